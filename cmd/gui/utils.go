@@ -16,9 +16,71 @@
 package main
 
 import (
+	"fmt"
+	"ivs-calculator/pkg/format"
+	"ivs-calculator/pkg/interpreter"
+	"ivs-calculator/pkg/mathfunc"
+	"math/big"
+	"os"
+	"strings"
+	"unicode"
+
 	"github.com/gotk3/gotk3/gtk"
 )
 
+/**
+ * Calculation modes which can be selected in the header bar
+ */
+const (
+	MODE_STANDARD = "standard"
+	MODE_SIGFIG   = "sigfig"
+	MODE_INTERVAL = "interval"
+	MODE_MODULAR  = "modular"
+	MODE_DECIMAL  = "decimal"
+)
+
+/**
+ * Notations of results which can be selected in the format settings, in the order of format.Notation
+ */
+var NOTATIONS = []struct {
+	id       string
+	label    string
+	notation format.Notation
+}{
+	{"auto", "Auto", format.Auto},
+	{"fixed", "Fixed", format.Fixed},
+	{"scientific", "Scientific", format.Scientific},
+	{"engineering", "Engineering", format.Engineering},
+	{"si", "SI prefix", format.SIPrefix},
+}
+
+/**
+ * Rounding modes which can be selected in the format settings, in the order of format.Rounding
+ */
+var ROUNDINGS = []struct {
+	id       string
+	label    string
+	rounding format.Rounding
+}{
+	{"half-up", "Half up", format.HalfUp},
+	{"half-even", "Half even", format.HalfEven},
+	{"down", "Toward zero", format.Down},
+	{"ceiling", "Ceiling", format.Ceiling},
+	{"floor", "Floor", format.Floor},
+}
+
+/**
+ * Rounding modes of inexact results of the decimal mode, which can be selected in the header bar
+ */
+var DECIMAL_ROUNDINGS = []struct {
+	id       string
+	label    string
+	rounding mathfunc.DecimalRounding
+}{
+	{"half-even", "Half even", mathfunc.RoundHalfEven},
+	{"half-up", "Half up", mathfunc.RoundHalfUp},
+}
+
 /**
  * Utility function to get the text content of a Gtk TextView
  * @param textView A Gtk TextView widget
@@ -41,3 +103,297 @@ func TextView_SetText(textView *gtk.TextView, text string) {
 	buffer.SetText(text)
 	textView.SetBuffer(buffer)
 }
+
+/**
+ * Utility function to replace the alternate syntax of operators ("p" for "^" and "r" for "√")
+ * Only letters standing between two operands are replaced, e.g. "6p2" or "3 r 125", so that letters which are a part
+ * of a longer name, e.g. "prod", and variables named p or r, e.g. in "map(p -> p*2, {1})", are kept
+ * @param input Inputted expression
+ * @return Expression with replaced operators
+ */
+func ReplaceAlternateSyntax(input string) string {
+	runes := []rune(input)
+	isNameRune := func(i int) bool {
+		return i >= 0 && i < len(runes) && (unicode.IsLetter(runes[i]) || runes[i] == '_')
+	}
+	// neighbouring runes, which aren't spaces, 0 at the ends of the input
+	previous := func(i int) rune {
+		for i--; i >= 0 && unicode.IsSpace(runes[i]); i-- {
+		}
+		if i < 0 {
+			return 0
+		}
+		return runes[i]
+	}
+	next := func(i int) rune {
+		for i++; i < len(runes) && unicode.IsSpace(runes[i]); i++ {
+		}
+		if i >= len(runes) {
+			return 0
+		}
+		return runes[i]
+	}
+	endsOperand := func(r rune) bool {
+		return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("_.)}!", r)
+	}
+	startsOperand := func(r rune) bool {
+		return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("_.({√", r)
+	}
+	for i, r := range runes {
+		if (r != 'r' && r != 'p') || isNameRune(i-1) || isNameRune(i+1) {
+			continue
+		}
+		if !endsOperand(previous(i)) || !startsOperand(next(i)) {
+			continue
+		}
+		if r == 'r' {
+			runes[i] = '√'
+		} else {
+			runes[i] = '^'
+		}
+	}
+	return string(runes)
+}
+
+/**
+ * Utility function to parse the modulus of the modular mode
+ * @param text Text of the modulus entry
+ * @return The modulus
+ * @return Error if the text isn't a positive integer
+ */
+func ParseModulus(text string) (*big.Int, error) {
+	modulus, ok := new(big.Int).SetString(strings.TrimSpace(text), 10)
+	if !ok || modulus.Sign() <= 0 {
+		return nil, fmt.Errorf("modulus has to be a positive integer")
+	}
+	return modulus, nil
+}
+
+/**
+ * Utility function to evaluate a parsed expression in the selected calculation mode
+ * @param node Root of the parsed expression
+ * @param mode One of the MODE_ constants
+ * @param modulus Modulus of the modular mode, it is used in other modes too if the expression set it using mod n { }
+ * @param session Session generating random numbers in the standard mode, it evaluates the decimal mode too
+ * @return Result of the expression
+ * @return Error of the evaluation
+ */
+func EvaluateInMode(node *interpreter.TreeNode, mode string, modulus *big.Int, session *interpreter.Session) (interpreter.Value, error) {
+	if modulus != nil {
+		return interpreter.InterpretModular(node, modulus)
+	}
+	switch mode {
+	case MODE_SIGFIG:
+		return interpreter.InterpretSigFigs(node)
+	case MODE_INTERVAL:
+		return interpreter.InterpretInterval(node)
+	default:
+		return session.Evaluate(node)
+	}
+}
+
+/**
+ * Utility function to format a result for the calculation history
+ * Results using random numbers show their seed, so that the calculation can be replayed using seed(n)
+ * @param value Result of the expression
+ * @param opts Settings of formatting numbers
+ * @return Formatted result
+ */
+func FormatResult(value interpreter.Value, opts format.Options) string {
+	if value.Seed != nil {
+		return fmt.Sprintf("%s    (seed %d)", value.Format(opts), *value.Seed)
+	}
+	return value.Format(opts)
+}
+
+/**
+ * Utility function to format a result as a fraction, a multiple of π or a surd, e.g. 2 1/3, 3π/4 or √2/2
+ * Elements of lists which aren't close to a fraction are kept in the decimal form
+ * @param value Result of the expression
+ * @param opts Settings of formatting numbers
+ * @return Formatted result
+ * @return False if the result has no fraction form different from the decimal one
+ */
+func FormatFraction(value interpreter.Value, opts format.Options) (string, bool) {
+	if !value.IsNumeric() {
+		return "", false
+	}
+	fraction := func(x float64) string {
+		if res, ok := format.Fraction(x, format.DefaultFractionTolerance); ok {
+			return res
+		}
+		return format.Number(x, opts)
+	}
+	var res string
+	if value.IsList {
+		elements := make([]string, len(value.List))
+		for i, x := range value.List {
+			elements[i] = fraction(x)
+		}
+		separator := ", "
+		if opts.Locale.ArgumentSeparator != 0 {
+			separator = string(opts.Locale.ArgumentSeparator) + " "
+		}
+		res = "{" + strings.Join(elements, separator) + "}"
+	} else {
+		res = fraction(value.Number)
+	}
+	if res == value.Format(opts) {
+		return "", false
+	}
+	if value.Seed != nil {
+		res = fmt.Sprintf("%s    (seed %d)", res, *value.Seed)
+	}
+	return res, true
+}
+
+/**
+ * Utility function to format a result in another base, e.g. 16#FF
+ * Fractional digits are limited by the digits of the format settings, 32 if they aren't set
+ * @param value Result of the expression
+ * @param base The base from 2 to 36
+ * @param opts Settings of formatting numbers
+ * @return Formatted result
+ * @return Error if the result isn't a number, a list or a residue, or can't be written in the base
+ */
+func FormatRadix(value interpreter.Value, base int, opts format.Options) (string, error) {
+	maxDigits := format.DefaultRadixDigits
+	if opts.Digits > 0 {
+		maxDigits = opts.Digits
+	}
+	if value.Residue != nil {
+		// residues are exact integers
+		return fmt.Sprintf("%d#%s (mod %v)", base, strings.ToUpper(value.Residue.Remainder.Text(base)),
+			value.Residue.Modulus), nil
+	}
+	if !value.IsNumeric() {
+		return "", fmt.Errorf("only numbers can be converted to another base")
+	}
+	if !value.IsList {
+		return format.Radix(value.Number, base, maxDigits, opts.Locale)
+	}
+	elements := make([]string, len(value.List))
+	for i, x := range value.List {
+		var err error
+		if elements[i], err = format.Radix(x, base, maxDigits, opts.Locale); err != nil {
+			return "", err
+		}
+	}
+	separator := ", "
+	if opts.Locale.ArgumentSeparator != 0 {
+		separator = string(opts.Locale.ArgumentSeparator) + " "
+	}
+	return "{" + strings.Join(elements, separator) + "}", nil
+}
+
+/**
+ * Utility function to inspect how a result is stored in the IEEE 754 formats
+ * @param value Result of the expression
+ * @return The bits of the result, nil if it isn't a single number
+ */
+func InspectResult(value interpreter.Value) *format.Inspection {
+	if value.IsList || !value.IsNumeric() {
+		return nil
+	}
+	inspection := format.Inspect(value.Number)
+	return &inspection
+}
+
+/**
+ * LaTeX of the symbols, which can be in formatted results
+ */
+var RESULT_LATEX = strings.NewReplacer("±", `\pm `, "µ", `\mu `, "π", `\pi `, "√", `\sqrt `, "…", `\ldots`,
+	"#", `\#`, "{", `\{`, "}", `\}`)
+
+/**
+ * Utility function to write a calculation in LaTeX, e.g. \frac{1}{2} + 1 = 1.5
+ * @param node The parsed expression
+ * @param result The formatted result
+ * @return LaTeX of the calculation
+ */
+func CalculationLaTeX(node *interpreter.TreeNode, result string) string {
+	return node.LaTeX() + " = " + RESULT_LATEX.Replace(result)
+}
+
+/**
+ * Utility function to explain a calculation, it draws the parsed expression as a tree with the value of every subtree
+ * The expression is evaluated again in a separate session, so that it doesn't change the seed of random numbers
+ * of the window, random numbers are generated from the seed of the result and drawn once, so they add up to the result
+ * Subtrees are evaluated on their own in the other modes, their numbers aren't random
+ * @param node The parsed expression
+ * @param mode One of the MODE_ constants
+ * @param modulus Modulus of the modular mode, nil in other modes
+ * @param decimal Settings of the decimal mode the result was calculated in, nil if it used floats
+ * @param seed Seed of the result, nil if it didn't use random numbers
+ * @param opts Settings of formatting numbers
+ * @return The tree, one node on a line
+ */
+func ExplainCalculation(node *interpreter.TreeNode, mode string, modulus *big.Int, decimal *mathfunc.DecimalContext,
+	seed *int64, opts format.Options) string {
+	explanation := interpreter.NewSession()
+	explanation.SetDecimal(decimal)
+	if seed != nil {
+		explanation.SetSeed(*seed)
+	}
+	var values map[*interpreter.TreeNode]interpreter.Value
+	if modulus == nil && mode != MODE_SIGFIG && mode != MODE_INTERVAL {
+		_, values, _ = explanation.Explain(node)
+	} else {
+		values = interpreter.IntermediateValues(node, func(n *interpreter.TreeNode) (interpreter.Value, error) {
+			return EvaluateInMode(n, mode, modulus, explanation)
+		})
+	}
+	return node.ASCIITree(func(n *interpreter.TreeNode) string {
+		value, ok := values[n]
+		// numbers are their own values and tables don't fit on a line
+		if !ok || n.Kind() == interpreter.NumberNode || value.Table != nil {
+			return ""
+		}
+		return value.Format(opts)
+	})
+}
+
+/**
+ * Utility function to find the locale of the user in the environment variables
+ * @return The locale set by LC_ALL, LC_NUMERIC or LANG if it's supported, en_US otherwise
+ */
+func DefaultLocale() format.Locale {
+	for _, variable := range []string{"LC_ALL", "LC_NUMERIC", "LANG"} {
+		value := os.Getenv(variable)
+		if value == "" {
+			continue
+		}
+		// e.g. cs_CZ.UTF-8
+		name := strings.SplitN(value, ".", 2)[0]
+		if locale, ok := format.LookupLocale(name); ok {
+			return locale
+		}
+		return format.EnUS
+	}
+	return format.EnUS
+}
+
+/**
+ * Utility function to describe a physical constant in the constants picker
+ * @param constant The described constant
+ * @return Symbol, name, value with its uncertainty and unit of the constant
+ */
+func ConstantDescription(constant interpreter.Constant) string {
+	value := interpreter.Value{Number: constant.Value, Uncertainty: constant.Uncertainty}
+	description := fmt.Sprintf("%s — %s = %v", constant.Symbol, constant.Name, value)
+	if constant.Unit != "" {
+		description += " " + constant.Unit
+	}
+	return description
+}
+
+/**
+ * Utility function to filter physical constants in the constants picker
+ * @param constant The filtered constant
+ * @param query Searched text
+ * @return True if the symbol or the name of the constant contains the query, ignoring case
+ */
+func ConstantMatches(constant interpreter.Constant, query string) bool {
+	query = strings.ToLower(query)
+	return strings.Contains(strings.ToLower(constant.Symbol), query) || strings.Contains(strings.ToLower(constant.Name), query)
+}
@@ -17,7 +17,9 @@ package main
 
 import (
 	"fmt"
+	"ivs-calculator/pkg/format"
 	"ivs-calculator/pkg/interpreter"
+	"ivs-calculator/pkg/mathfunc"
 	"log"
 	"strings"
 	"time"
@@ -37,6 +39,210 @@ type WindowState struct {
 	textInput        *gtk.TextView
 	shouldScrollDown int
 	buttonPressTime  time.Time
+	mode             string
+	modulusInput     *gtk.Entry
+	decimal          mathfunc.DecimalContext
+	session          *interpreter.Session
+	sheetRows        int
+	format           format.Options
+	decimalButton    *gtk.Button
+}
+
+/**
+ * Create the header bar with selection of the calculation mode
+ */
+func (state *WindowState) createHeaderBar() *gtk.HeaderBar {
+	headerBar, _ := gtk.HeaderBarNew()
+	headerBar.SetTitle("IVS Calculator")
+	headerBar.SetShowCloseButton(true)
+	modeSelect, _ := gtk.ComboBoxTextNew()
+	modeSelect.Append(MODE_STANDARD, "Standard")
+	modeSelect.Append(MODE_SIGFIG, "Significant figures")
+	modeSelect.Append(MODE_INTERVAL, "Interval")
+	modeSelect.Append(MODE_MODULAR, "Modular")
+	modeSelect.Append(MODE_DECIMAL, "Decimal")
+	modeSelect.SetActiveID(MODE_STANDARD)
+	state.mode = MODE_STANDARD
+	// modulus of the modular mode, it can be edited only in that mode
+	state.modulusInput, _ = gtk.EntryNew()
+	state.modulusInput.SetPlaceholderText("modulus")
+	state.modulusInput.SetWidthChars(8)
+	state.modulusInput.SetSensitive(false)
+	// scale and rounding of the decimal mode, they can be edited only in that mode
+	state.decimal = mathfunc.DecimalContext{Scale: mathfunc.DefaultDecimalScale, Rounding: mathfunc.RoundHalfEven}
+	scaleInput, _ := gtk.SpinButtonNewWithRange(0, mathfunc.MaxDecimalScale, 1)
+	scaleInput.SetValue(float64(state.decimal.Scale))
+	scaleInput.SetTooltipText("Decimal places of division and roots")
+	scaleInput.SetSensitive(false)
+	roundingSelect, _ := gtk.ComboBoxTextNew()
+	for _, rounding := range DECIMAL_ROUNDINGS {
+		roundingSelect.Append(rounding.id, rounding.label)
+	}
+	roundingSelect.SetActive(0)
+	roundingSelect.SetSensitive(false)
+	scaleInput.Connect("value-changed", func() {
+		state.decimal.Scale = scaleInput.GetValueAsInt()
+		state.updateSessionMode()
+	})
+	roundingSelect.Connect("changed", func() {
+		state.decimal.Rounding = DECIMAL_ROUNDINGS[roundingSelect.GetActive()].rounding
+		state.updateSessionMode()
+	})
+	modeSelect.Connect("changed", func() {
+		state.mode = modeSelect.GetActiveID()
+		state.modulusInput.SetSensitive(state.mode == MODE_MODULAR)
+		scaleInput.SetSensitive(state.mode == MODE_DECIMAL)
+		roundingSelect.SetSensitive(state.mode == MODE_DECIMAL)
+		state.updateSessionMode()
+	})
+	headerBar.PackEnd(roundingSelect)
+	headerBar.PackEnd(scaleInput)
+	headerBar.PackEnd(state.modulusInput)
+	headerBar.PackEnd(modeSelect)
+	headerBar.PackStart(state.createConstantsPicker())
+	headerBar.PackStart(state.createFormatSettings())
+	return headerBar
+}
+
+/**
+ * Select the numeric mode of the session, decimals in the decimal mode and floats otherwise
+ */
+func (state *WindowState) updateSessionMode() {
+	if state.mode == MODE_DECIMAL {
+		state.session.SetDecimal(&state.decimal)
+	} else {
+		state.session.SetDecimal(nil)
+	}
+}
+
+/**
+ * Create the button opening a searchable list of physical constants, the chosen one is inserted to the text input
+ */
+func (state *WindowState) createConstantsPicker() *gtk.MenuButton {
+	button, _ := gtk.MenuButtonNew()
+	button.SetLabel("Constants")
+	popover, _ := gtk.PopoverNew(button)
+	box, _ := gtk.BoxNew(gtk.ORIENTATION_VERTICAL, 6)
+	box.SetMarginStart(6)
+	box.SetMarginEnd(6)
+	box.SetMarginTop(6)
+	box.SetMarginBottom(6)
+	search, _ := gtk.SearchEntryNew()
+	box.Add(search)
+	sw, _ := gtk.ScrolledWindowNew(nil, nil)
+	sw.SetPolicy(gtk.POLICY_NEVER, gtk.POLICY_AUTOMATIC)
+	sw.SetMinContentHeight(300)
+	list, _ := gtk.ListBoxNew()
+	for _, constant := range interpreter.Constants {
+		row, _ := gtk.ListBoxRowNew()
+		label, _ := gtk.LabelNew(ConstantDescription(constant))
+		label.SetXAlign(0)
+		row.Add(label)
+		list.Add(row)
+	}
+	list.SetFilterFunc(func(row *gtk.ListBoxRow) bool {
+		query, _ := search.GetText()
+		return ConstantMatches(interpreter.Constants[row.GetIndex()], query)
+	})
+	search.Connect("search-changed", func() {
+		list.InvalidateFilter()
+	})
+	list.Connect("row-activated", func(list *gtk.ListBox, row *gtk.ListBoxRow) {
+		buffer, _ := state.textInput.GetBuffer()
+		buffer.InsertAtCursor(interpreter.Constants[row.GetIndex()].Symbol)
+		popover.Popdown()
+		state.textInput.GrabFocus()
+	})
+	sw.Add(list)
+	box.Add(sw)
+	box.ShowAll()
+	popover.Add(box)
+	button.SetPopover(popover)
+	return button
+}
+
+/**
+ * Create the button opening settings of formatting results, they apply to the following calculations
+ */
+func (state *WindowState) createFormatSettings() *gtk.MenuButton {
+	button, _ := gtk.MenuButtonNew()
+	button.SetLabel("Format")
+	popover, _ := gtk.PopoverNew(button)
+	grid, _ := gtk.GridNew()
+	grid.SetRowSpacing(6)
+	grid.SetColumnSpacing(6)
+	grid.SetMarginStart(6)
+	grid.SetMarginEnd(6)
+	grid.SetMarginTop(6)
+	grid.SetMarginBottom(6)
+
+	notationSelect, _ := gtk.ComboBoxTextNew()
+	for _, notation := range NOTATIONS {
+		notationSelect.Append(notation.id, notation.label)
+	}
+	notationSelect.SetActiveID(NOTATIONS[state.format.Notation].id)
+	notationSelect.Connect("changed", func() {
+		for _, notation := range NOTATIONS {
+			if notation.id == notationSelect.GetActiveID() {
+				state.format.Notation = notation.notation
+			}
+		}
+	})
+	digitsInput, _ := gtk.SpinButtonNewWithRange(0, 17, 1)
+	digitsInput.SetValue(float64(state.format.Digits))
+	digitsInput.SetTooltipText("Decimals in the fixed notation, significant digits in the others, 0 for as many as needed")
+	digitsInput.Connect("value-changed", func() {
+		state.format.Digits = digitsInput.GetValueAsInt()
+	})
+	roundingSelect, _ := gtk.ComboBoxTextNew()
+	for _, rounding := range ROUNDINGS {
+		roundingSelect.Append(rounding.id, rounding.label)
+	}
+	roundingSelect.SetActiveID(ROUNDINGS[state.format.Rounding].id)
+	roundingSelect.Connect("changed", func() {
+		for _, rounding := range ROUNDINGS {
+			if rounding.id == roundingSelect.GetActiveID() {
+				state.format.Rounding = rounding.rounding
+			}
+		}
+	})
+	groupingCheck, _ := gtk.CheckButtonNewWithLabel("Group digits")
+	groupingCheck.SetActive(state.format.Grouping)
+	groupingCheck.Connect("toggled", func() {
+		state.format.Grouping = groupingCheck.GetActive()
+	})
+	localeSelect, _ := gtk.ComboBoxTextNew()
+	for _, locale := range format.Locales {
+		localeSelect.Append(locale.Name, locale.Name)
+	}
+	localeSelect.SetActiveID(state.format.Locale.Name)
+	localeSelect.Connect("changed", func() {
+		if locale, ok := format.LookupLocale(localeSelect.GetActiveID()); ok {
+			state.format.Locale = locale
+			state.decimalButton.SetLabel(string(locale.DecimalMark))
+		}
+	})
+	noiseCheck, _ := gtk.CheckButtonNewWithLabel("Trim float noise")
+	noiseCheck.SetActive(state.format.TrimNoise)
+	noiseCheck.Connect("toggled", func() {
+		state.format.TrimNoise = noiseCheck.GetActive()
+	})
+
+	for i, text := range []string{"Notation", "Digits", "Rounding", "Locale"} {
+		label, _ := gtk.LabelNew(text)
+		label.SetXAlign(0)
+		grid.Attach(label, 0, i, 1, 1)
+	}
+	grid.Attach(notationSelect, 1, 0, 1, 1)
+	grid.Attach(digitsInput, 1, 1, 1, 1)
+	grid.Attach(roundingSelect, 1, 2, 1, 1)
+	grid.Attach(localeSelect, 1, 3, 1, 1)
+	grid.Attach(groupingCheck, 0, 4, 2, 1)
+	grid.Attach(noiseCheck, 0, 5, 2, 1)
+	grid.ShowAll()
+	popover.Add(grid)
+	button.SetPopover(popover)
+	return button
 }
 
 /**
@@ -78,7 +284,129 @@ func (state *WindowState) createTextInput() {
 		state.oldTextInputs = append(state.oldTextInputs, state.textInput)
 	}
 	state.textInput = textView
-	state.sheet.Attach(textView, 0, len(state.oldTextInputs), 1, 1)
+	state.sheet.Attach(textView, 0, state.sheetRows, 1, 1)
+	state.sheetRows++
+}
+
+/**
+ * Create a grid showing a table in the history sheet
+ * @param table The shown table
+ */
+func (state *WindowState) createTable(table *interpreter.Table) {
+	grid, _ := gtk.GridNew()
+	styleContext, _ := grid.GetStyleContext()
+	styleContext.AddClass("calculator-table")
+	grid.SetColumnSpacing(16)
+	grid.SetHAlign(gtk.ALIGN_END)
+	for i, column := range table.Columns {
+		label, _ := gtk.LabelNew(column)
+		labelStyle, _ := label.GetStyleContext()
+		labelStyle.AddClass("calculator-table-header")
+		label.SetXAlign(1)
+		grid.Attach(label, i, 0, 1, 1)
+	}
+	for i, row := range table.Rows {
+		for j, x := range row {
+			label, _ := gtk.LabelNew(format.Number(x, state.format))
+			label.SetXAlign(1)
+			label.SetSelectable(true)
+			grid.Attach(label, j, i+1, 1, 1)
+		}
+	}
+	state.sheet.Attach(grid, 0, state.sheetRows, 1, 1)
+	state.sheetRows++
+}
+
+/**
+ * Create a button switching a result row between its decimal and fraction form
+ * @param resultView The text view showing the result
+ * @param result The result in decimal form
+ * @param fraction The result in the form of a fraction
+ */
+func createFractionToggle(resultView *gtk.TextView, result string, fraction string) *gtk.ToggleButton {
+	toggle, _ := gtk.ToggleButtonNewWithLabel("a/b")
+	toggle.SetTooltipText("Show as a fraction")
+	toggle.Connect("toggled", func() {
+		if toggle.GetActive() {
+			TextView_SetText(resultView, fraction)
+		} else {
+			TextView_SetText(resultView, result)
+		}
+	})
+	return toggle
+}
+
+/**
+ * Create a button opening a popover, which shows how a result is stored in the IEEE 754 formats
+ * @param inspection The bits of the result
+ */
+func createInspectButton(inspection *format.Inspection) *gtk.MenuButton {
+	button, _ := gtk.MenuButtonNew()
+	button.SetLabel("IEEE")
+	button.SetTooltipText("Show how the result is stored")
+	popover, _ := gtk.PopoverNew(button)
+	label, _ := gtk.LabelNew(inspection.String())
+	styleContext, _ := label.GetStyleContext()
+	styleContext.AddClass("calculator-inspection")
+	label.SetSelectable(true)
+	label.SetXAlign(0)
+	label.Show()
+	popover.Add(label)
+	button.SetPopover(popover)
+	return button
+}
+
+/**
+ * Create a button opening a popover, which shows how the input was grouped and the values of its parts
+ * The explanation evaluates every part of the input, so it's computed only when the popover is opened for the first time
+ * @param explain Function drawing the parsed expression as a tree
+ */
+func createExplainButton(explain func() string) *gtk.MenuButton {
+	button, _ := gtk.MenuButtonNew()
+	button.SetLabel("Explain")
+	button.SetTooltipText("Show how the input was grouped")
+	popover, _ := gtk.PopoverNew(button)
+	label, _ := gtk.LabelNew("Evaluating…")
+	explained := false
+	button.Connect("toggled", func() {
+		if explained || !button.GetActive() {
+			return
+		}
+		explained = true
+		// Async
+		go func() {
+			explanation := explain()
+			glib.IdleAdd(func() {
+				label.SetText(explanation)
+			})
+		}()
+	})
+	styleContext, _ := label.GetStyleContext()
+	styleContext.AddClass("calculator-inspection")
+	label.SetSelectable(true)
+	label.SetXAlign(0)
+	label.Show()
+	popover.Add(label)
+	button.SetPopover(popover)
+	return button
+}
+
+/**
+ * Create a button copying a calculation to the clipboard in LaTeX
+ * @param latex The calculation in LaTeX
+ */
+func createCopyLaTeXButton(latex string) *gtk.Button {
+	button, _ := gtk.ButtonNewWithLabel("TeX")
+	button.SetTooltipText("Copy as LaTeX")
+	button.Connect("clicked", func() {
+		clipboard, err := gtk.ClipboardGet(gdk.SELECTION_CLIPBOARD)
+		if err != nil {
+			log.Printf("Clipboard: %v", err)
+			return
+		}
+		clipboard.SetText(latex)
+	})
+	return button
 }
 
 /**
@@ -148,6 +476,8 @@ func (state *WindowState) buttonCallback(label string) {
 		}
 	case "|  |":
 		buffer.InsertAtCursor("|")
+	case ".":
+		buffer.InsertAtCursor(string(state.format.Locale.DecimalMark))
 	case "?":
 		glib.IdleAdd(func() {
 			showHelp()
@@ -178,29 +508,67 @@ func (state *WindowState) finishCalculation() {
 	if input == "" {
 		return
 	}
+	mode := state.mode
+	modulusText, _ := state.modulusInput.GetText()
+	formatOptions := state.format
 	// Async
 	go func() {
-		input = strings.ReplaceAll(input, "r", "√")
-		input = strings.ReplaceAll(input, "p", "^")
-		node, err := interpreter.Parse(input)
+		input = ReplaceAlternateSyntax(input)
+		expression, base, err := interpreter.SplitConversion(input)
 		if err != nil {
 			state.showCalculationError(fmt.Sprintf("syntax error at position %d", err[0]))
 			return
 		}
-		floatResult, err2 := interpreter.Interpret(node)
+		node, modulus, err := interpreter.ParseModular(expression, formatOptions.Locale)
+		if err != nil {
+			state.showCalculationError(fmt.Sprintf("syntax error at position %d", err[0]))
+			return
+		}
+		if modulus == nil && mode == MODE_MODULAR {
+			var err2 error
+			if modulus, err2 = ParseModulus(modulusText); err2 != nil {
+				state.showCalculationError(err2.Error())
+				return
+			}
+		}
+		value, err2 := EvaluateInMode(node, mode, modulus, state.session)
 		if err2 != nil {
 			state.showCalculationError(err2.Error())
 			return
 		}
-		result := fmt.Sprintf("%g", floatResult)
-		state.showCalculationResult(result)
+		if value.Table != nil {
+			state.showCalculationTable(value.Table)
+			return
+		}
+		decimal := state.session.Decimal()
+		explain := func() string {
+			return ExplainCalculation(node, mode, modulus, decimal, value.Seed, formatOptions)
+		}
+		if base != 0 {
+			result, err2 := FormatRadix(value, base, formatOptions)
+			if err2 != nil {
+				state.showCalculationError(err2.Error())
+				return
+			}
+			state.showCalculationResult(result, "", nil, CalculationLaTeX(node, result), explain)
+			return
+		}
+		fraction, _ := FormatFraction(value, formatOptions)
+		state.showCalculationResult(FormatResult(value, formatOptions), fraction, InspectResult(value),
+			CalculationLaTeX(node, value.Format(formatOptions)), explain)
 	}()
 }
 
 /**
  * Show calculation result
+ * @param result The result in decimal form
+ * @param fraction The result in the form of a fraction, empty if it has none
+ * @param inspection How the result is stored, nil if it isn't a number
+ * @param latex The calculation in LaTeX
+ * @param explain Function drawing the parsed expression as a tree, called when the explanation is opened
  */
-func (state *WindowState) showCalculationResult(result string) {
+func (state *WindowState) showCalculationResult(result string, fraction string, inspection *format.Inspection, latex string,
+	explain func() string) {
 	glib.IdleAdd(func() {
 		state.textInput.SetEditable(false)
 		styleContext, _ := state.textInput.GetStyleContext()
@@ -211,6 +579,33 @@ func (state *WindowState) showCalculationResult(result string) {
 		state.textInput.SetJustification(gtk.JUSTIFY_RIGHT)
 		styleContext, _ = state.textInput.GetStyleContext()
 		styleContext.AddClass("calculator-textinput-result")
+		// buttons next to the result
+		actions, _ := gtk.BoxNew(gtk.ORIENTATION_HORIZONTAL, 4)
+		actions.SetVAlign(gtk.ALIGN_CENTER)
+		if fraction != "" {
+			actions.Add(createFractionToggle(state.textInput, result, fraction))
+		}
+		if inspection != nil {
+			actions.Add(createInspectButton(inspection))
+		}
+		actions.Add(createExplainButton(explain))
+		actions.Add(createCopyLaTeXButton(latex))
+		state.sheet.Attach(actions, 1, state.sheetRows-1, 1, 1)
+		state.createTextInput()
+		state.scrollWindow.ShowAll()
+		state.shouldScrollDown = 3
+	})
+}
+
+/**
+ * Show calculation result, which is a table
+ */
+func (state *WindowState) showCalculationTable(table *interpreter.Table) {
+	glib.IdleAdd(func() {
+		state.textInput.SetEditable(false)
+		styleContext, _ := state.textInput.GetStyleContext()
+		styleContext.AddClass("calculator-textinput-finished")
+		state.createTable(table)
 		state.createTextInput()
 		state.scrollWindow.ShowAll()
 		state.shouldScrollDown = 3
@@ -239,9 +634,12 @@ func (state *WindowState) showCalculationError(err string) {
 
 /**
  * Create the app layout and initialize WindowState
+ * @param win The Gtk Window, its title bar is replaced by the header bar
  */
-func createLayout() *gtk.Grid {
-	state := WindowState{}
+func createLayout(win *gtk.Window) *gtk.Grid {
+	state := WindowState{session: interpreter.NewSession(), format: format.DefaultOptions()}
+	state.format.Locale = DefaultLocale()
+	win.SetTitlebar(state.createHeaderBar())
 	state.createSheet()
 	state.createTextInput()
 
@@ -253,11 +651,17 @@ func createLayout() *gtk.Grid {
 		{"^", "7", "8", "9", "*"},
 		{"!", "4", "5", "6", "-"},
 		{"%", "1", "2", "3", "+"},
-		{"|  |", "0", ",", "?", "="},
+		{"|  |", "0", ".", "?", "="},
 	}
 	for i := 0; i < 25; i++ {
 		label := buttonLabels[i/5][i%5]
-		grid.Attach(state.createButton(label), i%5, 1+i/5, 1, 1)
+		button := state.createButton(label)
+		// the decimal key shows the decimal mark of the locale
+		if label == "." {
+			button.SetLabel(string(state.format.Locale.DecimalMark))
+			state.decimalButton = button
+		}
+		grid.Attach(button, i%5, 1+i/5, 1, 1)
 	}
 
 	grid.SetHExpand(true)
@@ -289,7 +693,7 @@ func createWindow() {
 	styleContext.AddProvider(cssProvider, gtk.STYLE_PROVIDER_PRIORITY_APPLICATION)
 
 	// Create layout
-	win.Add(createLayout())
+	win.Add(createLayout(win))
 	win.ShowAll()
 }
 
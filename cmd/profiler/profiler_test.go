@@ -16,7 +16,7 @@ func TestSD(t *testing.T) {
 	expectedRes := 1200.121239245806
 
 	total := StandardDeviation(numbers)
-	if total != expectedRes {
+	if math.Abs(total-expectedRes) > math.Pow(10, -10) {
 		t.Errorf("Result is: %.10f, should be: %.10f", total, expectedRes)
 	}
 
@@ -43,6 +43,15 @@ func TestSD(t *testing.T) {
 	if math.Abs(total-expectedRes) > math.Pow(10, -10) {
 		t.Errorf("Result is: %.10f, should be: %.10f", total, expectedRes)
 	}
+
+	// large numbers close to each other
+	numbers = []float64{1e9, 1e9 + 1, 1e9 + 2}
+	expectedRes = 1
+
+	total = StandardDeviation(numbers)
+	if math.Abs(total-expectedRes) > math.Pow(10, -10) {
+		t.Errorf("Result is: %.10f, should be: %.10f", total, expectedRes)
+	}
 }
 
 func benchmarkSD(file *os.File, b *testing.B) {
@@ -0,0 +1,305 @@
+package format
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+/**
+ * Notation: the way numbers are written
+ */
+type Notation int
+
+const (
+	Auto        Notation = iota // plain decimal numbers, scientific notation for very big and very small ones
+	Fixed                       // fixed number of decimals, e.g. 1234.50
+	Scientific                  // one digit before the decimal mark, e.g. 1.2345e+3
+	Engineering                 // exponent is a multiple of 3, e.g. 1.2345e+3, 12.345e+3
+	SIPrefix                    // engineering notation with an SI prefix instead of the exponent, e.g. 1.2345 k
+)
+
+/**
+ * Rounding: the way digits which don't fit into the requested number of digits are removed
+ */
+type Rounding int
+
+const (
+	HalfUp   Rounding = iota // to the nearest, halves away from zero
+	HalfEven                 // to the nearest, halves to the even digit
+	Down                     // toward zero
+	Ceiling                  // toward positive infinity
+	Floor                    // toward negative infinity
+)
+
+/**
+ * Options: settings of formatting numbers
+ */
+type Options struct {
+	Notation Notation
+	// decimals in the fixed notation, which are filled with zeros, the most significant digits in the others,
+	// which drop trailing zeros, 0 means as many as needed
+	Digits    int
+	Grouping  bool // group digits of the integer part by three
+	Rounding  Rounding
+	TrimNoise bool   // round to 15 significant digits first, so that 0.1+0.2 gives 0.3
+	Locale    Locale // decimal and grouping marks, en_US if not set
+}
+
+// significant digits a float64 value keeps reliably
+const reliableDigits = 15
+
+// numbers from 1e-5 up to 1e15 are written without an exponent in the auto notation
+const (
+	minPlainExponent = -5
+	maxPlainExponent = 15
+)
+
+// SI prefixes from 1e-24 to 1e24 by powers of 1000
+var siPrefixes = []string{"y", "z", "a", "f", "p", "n", "µ", "m", "", "k", "M", "G", "T", "P", "E", "Z", "Y"}
+
+/**
+ * DefaultOptions: returns the options used unless a frontend lets the user change them
+ *
+ * @return Options the options
+ */
+func DefaultOptions() Options {
+	return Options{Notation: Auto, Rounding: HalfUp, TrimNoise: true, Locale: EnUS}
+}
+
+/**
+ * decimal: a finite number in the form of decimal digits, its value is 0.digits × 10^exp
+ */
+type decimal struct {
+	negative bool
+	digits   string // without leading and trailing zeros, empty for zero
+	exp      int
+}
+
+/**
+ * decompose: converts a finite float to decimal digits
+ *
+ * @param x the number
+ * @param trimNoise true to keep only 15 significant digits
+ * @return decimal the shortest decimal number, which converts back to x, or x rounded to 15 significant digits
+ */
+func decompose(x float64, trimNoise bool) decimal {
+	precision := -1
+	if trimNoise {
+		precision = reliableDigits - 1
+	}
+	// scientific form d.ddde±x
+	s := strconv.FormatFloat(math.Abs(x), 'e', precision, 64)
+	mantissa, exponent := s, "0"
+	if i := strings.IndexByte(s, 'e'); i >= 0 {
+		mantissa, exponent = s[:i], s[i+1:]
+	}
+	exp, _ := strconv.Atoi(exponent)
+	digits := strings.TrimRight(strings.Replace(mantissa, ".", "", 1), "0")
+	if digits == "" {
+		return decimal{}
+	}
+	return decimal{negative: x < 0, digits: digits, exp: exp + 1}
+}
+
+/**
+ * round: rounds the number to the given count of digits
+ *
+ * @param n count of kept digits, can be zero or negative if the number is too small to keep any digits
+ * @param mode the rounding mode
+ * @return decimal the rounded number
+ */
+func (d decimal) round(n int, mode Rounding) decimal {
+	if n >= len(d.digits) {
+		return d
+	}
+	kept, dropped := "", d.digits
+	if n > 0 {
+		kept, dropped = d.digits[:n], d.digits[n:]
+	} else {
+		// dropped digits start with -n zeros
+		dropped = strings.Repeat("0", -n) + dropped
+	}
+	// compare dropped digits with a half of the last kept digit, dropped digits are never all zeros
+	half := strings.Compare(dropped, "5")
+	up := false
+	switch mode {
+	case HalfUp:
+		up = half >= 0
+	case HalfEven:
+		last := byte('0')
+		if kept != "" {
+			last = kept[len(kept)-1]
+		}
+		up = half > 0 || half == 0 && (last-'0')%2 == 1
+	case Down:
+		up = false
+	case Ceiling:
+		up = !d.negative
+	case Floor:
+		up = d.negative
+	}
+	res := decimal{negative: d.negative, exp: d.exp}
+	if !up {
+		res.digits = strings.TrimRight(kept, "0")
+		if res.digits == "" {
+			return decimal{}
+		}
+		return res
+	}
+	if n <= 0 {
+		// the rounded number is the unit of the last kept digit
+		res.digits = "1"
+		res.exp = d.exp - n + 1
+		return res
+	}
+	digits := []byte(kept)
+	i := len(digits) - 1
+	for ; i >= 0 && digits[i] == '9'; i-- {
+		digits[i] = '0'
+	}
+	if i < 0 {
+		// 99.9 rounded up is 100
+		res.digits = "1"
+		res.exp++
+		return res
+	}
+	digits[i]++
+	res.digits = strings.TrimRight(string(digits), "0")
+	return res
+}
+
+/**
+ * plain: writes the number without an exponent
+ *
+ * @param decimals minimal count of decimals, missing ones are filled with zeros
+ * @param grouping true to group digits of the integer part by three
+ * @param locale decimal and grouping marks
+ * @return string the number
+ */
+func (d decimal) plain(decimals int, grouping bool, locale Locale) string {
+	integer, fraction := "0", ""
+	switch {
+	case d.exp <= 0:
+		fraction = strings.Repeat("0", -d.exp) + d.digits
+	case d.exp >= len(d.digits):
+		integer = d.digits + strings.Repeat("0", d.exp-len(d.digits))
+	default:
+		integer, fraction = d.digits[:d.exp], d.digits[d.exp:]
+	}
+	if len(fraction) < decimals {
+		fraction += strings.Repeat("0", decimals-len(fraction))
+	}
+	if grouping {
+		integer = group(integer, locale.GroupingMark)
+	}
+	res := integer
+	if fraction != "" {
+		res += string(locale.DecimalMark) + fraction
+	}
+	if d.negative {
+		res = "-" + res
+	}
+	return res
+}
+
+/**
+ * withExponent: writes the number as a mantissa and an exponent
+ *
+ * @param exponent the exponent, the mantissa is the number divided by 10^exponent
+ * @param locale decimal mark
+ * @return string the mantissa
+ * @return string the exponent, e.g. e+3, it's written even if it's 0
+ */
+func (d decimal) withExponent(exponent int, locale Locale) (string, string) {
+	mantissa := d
+	mantissa.exp -= exponent
+	return mantissa.plain(0, false, locale), fmt.Sprintf("e%+d", exponent)
+}
+
+/**
+ * group: separates digits of an integer by three
+ *
+ * @param integer the digits
+ * @param mark the grouping mark
+ * @return string grouped digits, e.g. 1,234,567
+ */
+func group(integer string, mark rune) string {
+	var b strings.Builder
+	for i, r := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			b.WriteRune(mark)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+/**
+ * engineeringExponent: returns the exponent of the engineering notation of the number
+ *
+ * @param d the number
+ * @return int the exponent, a multiple of 3 such that the mantissa is at least 1 and less than 1000
+ */
+func engineeringExponent(d decimal) int {
+	exponent := d.exp - 1
+	if exponent >= 0 {
+		return exponent / 3 * 3
+	}
+	return -((-exponent + 2) / 3 * 3)
+}
+
+/**
+ * Number: formats a number
+ *
+ * Only the fixed notation fills missing decimals with zeros, the others round to the significant digits
+ * and drop trailing zeros, e.g. 2.50 is 2.5 in the auto notation and 2.5e+0 in the scientific one.
+ *
+ * @param x the number
+ * @param opts settings of the formatting
+ * @return string the formatted number
+ */
+func Number(x float64, opts Options) string {
+	if math.IsNaN(x) || math.IsInf(x, 0) {
+		return strconv.FormatFloat(x, 'g', -1, 64)
+	}
+	locale := opts.Locale.orDefault()
+	d := decompose(x, opts.TrimNoise)
+	if opts.Notation == Fixed {
+		d = d.round(d.exp+opts.Digits, opts.Rounding)
+		return d.plain(opts.Digits, opts.Grouping, locale)
+	}
+	if opts.Digits > 0 {
+		d = d.round(opts.Digits, opts.Rounding)
+	}
+	if d.digits == "" {
+		if opts.Notation == Scientific || opts.Notation == Engineering {
+			return "0e+0"
+		}
+		return "0"
+	}
+	var mantissa, exponent string
+	switch opts.Notation {
+	case Scientific:
+		mantissa, exponent = d.withExponent(d.exp-1, locale)
+	case Engineering:
+		mantissa, exponent = d.withExponent(engineeringExponent(d), locale)
+	case SIPrefix:
+		mantissa, exponent = d.withExponent(engineeringExponent(d), locale)
+		// prefixes replace exponents from e-24 to e+24
+		if i := engineeringExponent(d)/3 + len(siPrefixes)/2; i >= 0 && i < len(siPrefixes) {
+			exponent = ""
+			if siPrefixes[i] != "" {
+				exponent = " " + siPrefixes[i]
+			}
+		}
+	default:
+		if d.exp-1 < minPlainExponent || d.exp-1 >= maxPlainExponent {
+			mantissa, exponent = d.withExponent(d.exp-1, locale)
+			break
+		}
+		return d.plain(0, opts.Grouping, locale)
+	}
+	return mantissa + exponent
+}
@@ -0,0 +1,281 @@
+package format
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestAuto(t *testing.T) {
+	a, b := 0.1, 0.2
+	opts := DefaultOptions()
+	NumberTestCase(t, 0, opts, "0")
+	NumberTestCase(t, math.Copysign(0, -1), opts, "0")
+	NumberTestCase(t, 1e6, opts, "1000000")
+	NumberTestCase(t, a+b, opts, "0.3")
+	NumberTestCase(t, -2.5, opts, "-2.5")
+	NumberTestCase(t, 1.0/3, opts, "0.333333333333333")
+	NumberTestCase(t, 0.0001, opts, "0.0001")
+	NumberTestCase(t, 0.000001234, opts, "1.234e-6")
+	NumberTestCase(t, 1e15, opts, "1e+15")
+	NumberTestCase(t, 123456789012345, opts, "123456789012345")
+	NumberTestCase(t, math.Inf(-1), opts, "-Inf")
+	NumberTestCase(t, math.NaN(), opts, "NaN")
+
+	opts.TrimNoise = false
+	NumberTestCase(t, a+b, opts, "0.30000000000000004")
+
+	opts = DefaultOptions()
+	opts.Digits = 3
+	NumberTestCase(t, math.Pi, opts, "3.14")
+	NumberTestCase(t, 2, opts, "2")
+	NumberTestCase(t, 99.96, opts, "100")
+	NumberTestCase(t, 123456, opts, "123000")
+}
+
+func TestDigits(t *testing.T) {
+	// trailing zeros are kept only by the fixed notation, exponents are always written by scientific and engineering
+	for _, c := range []struct {
+		x        float64
+		notation Notation
+		digits   int
+		expected string
+	}{
+		{0.5, Auto, 2, "0.5"},
+		{2.5, Auto, 2, "2.5"},
+		{2.04, Auto, 2, "2"},
+		{0.125, Auto, 2, "0.13"},
+		{1.5e-7, Auto, 3, "1.5e-7"},
+		{1e20, Auto, 2, "1e+20"},
+		{0.5, Fixed, 2, "0.50"},
+		{2.5, Fixed, 2, "2.50"},
+		{0.5, Scientific, 2, "5e-1"},
+		{2.5, Scientific, 2, "2.5e+0"},
+		{2.5, Scientific, 0, "2.5e+0"},
+		{2.04, Scientific, 2, "2e+0"},
+		{0, Scientific, 2, "0e+0"},
+		{0.5, Engineering, 2, "500e-3"},
+		{2.5, Engineering, 2, "2.5e+0"},
+		{2500, Engineering, 2, "2.5e+3"},
+		{0, Engineering, 0, "0e+0"},
+		{2.5, SIPrefix, 2, "2.5"},
+		{2500, SIPrefix, 2, "2.5 k"},
+		{2000, SIPrefix, 3, "2 k"},
+	} {
+		NumberTestCase(t, c.x, Options{Notation: c.notation, Digits: c.digits, TrimNoise: true}, c.expected)
+	}
+}
+
+func TestFixed(t *testing.T) {
+	opts := Options{Notation: Fixed, Digits: 2, TrimNoise: true}
+	NumberTestCase(t, 1234.5, opts, "1234.50")
+	NumberTestCase(t, 0.005, opts, "0.01")
+	NumberTestCase(t, 0.0049, opts, "0.00")
+	NumberTestCase(t, -0.001, opts, "0.00")
+	NumberTestCase(t, 9.999, opts, "10.00")
+	NumberTestCase(t, 2.675, opts, "2.68")
+	NumberTestCase(t, 0, opts, "0.00")
+
+	opts.Digits = 0
+	NumberTestCase(t, 2.5, opts, "3")
+	NumberTestCase(t, 1e20, opts, "100000000000000000000")
+
+	opts.Grouping = true
+	NumberTestCase(t, 1234567.8, opts, "1,234,568")
+	NumberTestCase(t, -123456, opts, "-123,456")
+	NumberTestCase(t, 999, opts, "999")
+}
+
+func TestRounding(t *testing.T) {
+	opts := Options{Notation: Fixed, Digits: 0}
+	RoundingTestCase(t, opts, HalfUp, []float64{2.5, 3.5, -2.5, 2.4, -2.6}, []string{"3", "4", "-3", "2", "-3"})
+	RoundingTestCase(t, opts, HalfEven, []float64{2.5, 3.5, -2.5, 2.51, 0.5}, []string{"2", "4", "-2", "3", "0"})
+	RoundingTestCase(t, opts, Down, []float64{2.9, -2.9, 0.9}, []string{"2", "-2", "0"})
+	RoundingTestCase(t, opts, Ceiling, []float64{2.1, -2.9, 0.001}, []string{"3", "-2", "1"})
+	RoundingTestCase(t, opts, Floor, []float64{2.9, -2.1, -0.001}, []string{"2", "-3", "-1"})
+
+	opts = Options{Notation: Scientific, Digits: 2}
+	RoundingTestCase(t, opts, HalfEven, []float64{1250, 1350}, []string{"1.2e+3", "1.4e+3"})
+	RoundingTestCase(t, opts, Ceiling, []float64{1201}, []string{"1.3e+3"})
+}
+
+func TestScientific(t *testing.T) {
+	opts := Options{Notation: Scientific, TrimNoise: true}
+	NumberTestCase(t, 1e6, opts, "1e+6")
+	NumberTestCase(t, 12345, opts, "1.2345e+4")
+	NumberTestCase(t, -0.00012, opts, "-1.2e-4")
+	NumberTestCase(t, 5, opts, "5e+0")
+	NumberTestCase(t, 0, opts, "0e+0")
+
+	opts.Digits = 3
+	NumberTestCase(t, 1e6, opts, "1e+6")
+	NumberTestCase(t, 1234, opts, "1.23e+3")
+	NumberTestCase(t, 9.996, opts, "1e+1")
+}
+
+func TestEngineering(t *testing.T) {
+	opts := Options{Notation: Engineering, TrimNoise: true}
+	NumberTestCase(t, 12345, opts, "12.345e+3")
+	NumberTestCase(t, 1e6, opts, "1e+6")
+	NumberTestCase(t, 0.00012, opts, "120e-6")
+	NumberTestCase(t, 0.001, opts, "1e-3")
+	NumberTestCase(t, 0.01, opts, "10e-3")
+	NumberTestCase(t, 999, opts, "999e+0")
+
+	opts.Digits = 2
+	NumberTestCase(t, 999, opts, "1e+3")
+	NumberTestCase(t, 123456, opts, "120e+3")
+}
+
+func TestSIPrefix(t *testing.T) {
+	opts := Options{Notation: SIPrefix, TrimNoise: true}
+	NumberTestCase(t, 1500, opts, "1.5 k")
+	NumberTestCase(t, 4.7e-6, opts, "4.7 µ")
+	NumberTestCase(t, 2.2e9, opts, "2.2 G")
+	NumberTestCase(t, 0.5, opts, "500 m")
+	NumberTestCase(t, 12, opts, "12")
+	NumberTestCase(t, 1e27, opts, "1e+27")
+	NumberTestCase(t, 1e-27, opts, "1e-27")
+}
+
+func NumberTestCase(t *testing.T, x float64, opts Options, expectedOutput string) {
+	output := Number(x, opts)
+	if output != expectedOutput {
+		t.Errorf("Number(%v, %+v) = %s; should be %s", x, opts, output, expectedOutput)
+	}
+}
+
+func RoundingTestCase(t *testing.T, opts Options, mode Rounding, inputs []float64, expectedOutputs []string) {
+	opts.Rounding = mode
+	for i, x := range inputs {
+		NumberTestCase(t, x, opts, expectedOutputs[i])
+	}
+}
+
+func TestLocale(t *testing.T) {
+	opts := Options{Notation: Fixed, Digits: 2, Grouping: true, Locale: CsCZ}
+	NumberTestCase(t, 1234567.891, opts, "1\u00a0234\u00a0567,89")
+	opts.Locale = DeDE
+	NumberTestCase(t, -1234.5, opts, "-1.234,50")
+	opts = Options{Notation: Scientific, Locale: CsCZ}
+	NumberTestCase(t, 12345, opts, "1,2345e+4")
+	NumberTestCase(t, 0.5, Options{}, "0.5")
+
+	if locale, ok := LookupLocale("cs_CZ"); !ok || locale != CsCZ {
+		t.Errorf("LookupLocale(cs_CZ) = %v, %v should be %v", locale, ok, CsCZ)
+	}
+	if _, ok := LookupLocale("xx_XX"); ok {
+		t.Errorf("LookupLocale(xx_XX) found an unsupported locale")
+	}
+}
+
+func TestFraction(t *testing.T) {
+	FractionTestCase(t, 0.75, "3/4", true)
+	FractionTestCase(t, 2.3333333, "2 1/3", true)
+	FractionTestCase(t, -1.5, "-1 1/2", true)
+	FractionTestCase(t, 0.1, "1/10", true)
+	FractionTestCase(t, 5, "5", true)
+	FractionTestCase(t, 0, "0", true)
+	FractionTestCase(t, math.Pi, "π", true)
+	FractionTestCase(t, 3*math.Pi/4, "3π/4", true)
+	FractionTestCase(t, -2*math.Pi, "-2π", true)
+	FractionTestCase(t, math.Sqrt(2)/2, "√2/2", true)
+	FractionTestCase(t, math.Sin(math.Pi/3), "√3/2", true)
+	FractionTestCase(t, 3*math.Sqrt(5), "3√5", true)
+	FractionTestCase(t, math.E, "", false)
+	FractionTestCase(t, 1e-20, "", false)
+	FractionTestCase(t, 1e20, "", false)
+	FractionTestCase(t, math.NaN(), "", false)
+
+	// integers aren't written as big multiples of π or surds
+	FractionTestCase(t, 95534, "95534", true)
+	FractionTestCase(t, 103361, "103361", true)
+	FractionTestCase(t, 1e10, "10000000000", true)
+	FractionTestCase(t, 1e15-1, "999999999999999", true)
+	FractionTestCase(t, -7, "-7", true)
+	FractionTestCase(t, 1000*math.Pi, "1000π", true)
+	FractionTestCase(t, 1001*math.Pi, "", false)
+	// the fractional part of big numbers has to match too
+	FractionTestCase(t, 1e12+0.5, "1000000000000 1/2", true)
+	FractionTestCase(t, 1e9+0.1234, "", false)
+	FractionTestCase(t, 1e14+0.3, "100000000000000 3/10", true)
+	FractionTestCase(t, 1000.0001, "", false)
+}
+
+func FractionTestCase(t *testing.T, x float64, expectedOutput string, expectedOk bool) {
+	output, ok := Fraction(x, DefaultFractionTolerance)
+	if output != expectedOutput || ok != expectedOk {
+		t.Errorf("Fraction(%v) = %s, %v; should be %s, %v", x, output, ok, expectedOutput, expectedOk)
+	}
+}
+
+func TestRadix(t *testing.T) {
+	RadixTestCase(t, 255, 16, 32, "16#FF")
+	RadixTestCase(t, -10, 2, 32, "-2#1010")
+	RadixTestCase(t, 0, 7, 32, "7#0")
+	RadixTestCase(t, 0.5, 2, 32, "2#0.1")
+	RadixTestCase(t, 10.25, 8, 32, "8#12.2")
+	RadixTestCase(t, 0.1, 2, 10, "2#0.0001100110…")
+	RadixTestCase(t, 1.0/3, 3, 5, "3#0.02222…")
+	RadixTestCase(t, 1e20, 36, 32, "36#L3R41IFS0Q5TS")
+	RadixTestCase(t, 35, 36, 32, "36#Z")
+	RadixTestCase(t, 5.5, 10, 32, "10#5.5")
+
+	if _, err := Radix(1, 37, 32, EnUS); err == nil {
+		t.Errorf("Radix(1, 37) gave no error")
+	}
+	if _, err := Radix(math.Inf(1), 2, 32, EnUS); err == nil {
+		t.Errorf("Radix(+Inf, 2) gave no error")
+	}
+	if output, _ := Radix(0.5, 2, 32, CsCZ); output != "2#0,1" {
+		t.Errorf("Radix(0.5, 2, cs_CZ) = %s; should be 2#0,1", output)
+	}
+}
+
+func RadixTestCase(t *testing.T, x float64, base int, maxDigits int, expectedOutput string) {
+	output, err := Radix(x, base, maxDigits, EnUS)
+	if err != nil || output != expectedOutput {
+		t.Errorf("Radix(%v, %d, %d) = %s, %v; should be %s", x, base, maxDigits, output, err, expectedOutput)
+	}
+}
+
+func TestInspect(t *testing.T) {
+	one := Inspect(1)
+	InspectTestCase(t, one.Float64, FloatBits{64, 1, "0", "01111111111", strings.Repeat("0", 52), "0x3FF0000000000000",
+		0, math.Pow(2, -52), 1 - math.Pow(2, -53), 1 + math.Pow(2, -52), false})
+	InspectTestCase(t, one.Float32, FloatBits{32, 1, "0", "01111111", strings.Repeat("0", 23), "0x3F800000",
+		0, math.Pow(2, -23), 1 - math.Pow(2, -24), 1 + math.Pow(2, -23), false})
+
+	tenth := Inspect(-0.1)
+	if tenth.Float64.Hex != "0xBFB999999999999A" || tenth.Float64.Sign != "1" || tenth.Float64.Power != -4 {
+		t.Errorf("Inspect(-0.1) = %+v", tenth.Float64)
+	}
+	if tenth.Float32.Hex != "0xBDCCCCCD" || tenth.Float32.Value != float64(float32(-0.1)) {
+		t.Errorf("Inspect(-0.1) = %+v", tenth.Float32)
+	}
+
+	tiny := Inspect(5e-324)
+	if !tiny.Float64.Subnormal || tiny.Float64.Power != -1022 || tiny.Float64.Ulp != 5e-324 || tiny.Float64.Previous != 0 {
+		t.Errorf("Inspect(5e-324) = %+v", tiny.Float64)
+	}
+	if tiny.Float32.Subnormal || tiny.Float32.Value != 0 || tiny.Float32.Hex != "0x00000000" {
+		t.Errorf("Inspect(5e-324) = %+v", tiny.Float32)
+	}
+
+	infinity := Inspect(math.Inf(1))
+	if !math.IsNaN(infinity.Float64.Ulp) || infinity.Float64.Hex != "0x7FF0000000000000" {
+		t.Errorf("Inspect(+Inf) = %+v", infinity.Float64)
+	}
+	if !strings.Contains(infinity.String(), "infinity or NaN") {
+		t.Errorf("Inspect(+Inf) = %s should mention infinity", infinity)
+	}
+	if lines := strings.Split(Inspect(2).String(), "\n"); len(lines) != 16 || lines[0] != "float64 2" || lines[8] != "float32 2" {
+		t.Errorf("Inspect(2) = %s", strings.Join(lines, "\n"))
+	}
+}
+
+func InspectTestCase(t *testing.T, output FloatBits, expectedOutput FloatBits) {
+	if output != expectedOutput {
+		t.Errorf("Inspect(%v) = %+v; should be %+v", expectedOutput.Value, output, expectedOutput)
+	}
+}
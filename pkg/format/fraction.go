@@ -0,0 +1,152 @@
+package format
+
+import (
+	"math"
+	"strconv"
+)
+
+// tolerance of fractions used unless a frontend sets another one, 2.3333333 is still 2 1/3
+// it's relative for numbers below 1 and absolute for bigger ones, so that it bounds the error of their fractional part
+const DefaultFractionTolerance = 1e-7
+
+// biggest denominator of a fraction
+const maxDenominator = 1000
+
+// multiples of π and surds are recognised only with small numerators and denominators and nearly exactly,
+// otherwise almost any number would be one of them
+const (
+	maxSymbolicNumerator   = 1000
+	maxSymbolicDenominator = 12
+	symbolicTolerance      = 1e-12
+)
+
+// square roots recognised in surds, e.g. √2/2
+var surdRadicands = []int64{2, 3, 5, 6, 7}
+
+/**
+ * approximate: finds the best rational approximation of a positive number by its continued fraction
+ *
+ * @param x the number
+ * @param tolerance biggest error of the approximation, relative to numbers below 1, half an ulp of x is always tolerated
+ * @param maxDen biggest denominator of the approximation
+ * @return int64 numerator
+ * @return int64 denominator
+ * @return bool false if there's no approximation with a denominator up to maxDen
+ */
+func approximate(x, tolerance float64, maxDen int64) (int64, int64, bool) {
+	if x >= 1e15 {
+		return 0, 0, false
+	}
+	// the fractional part has to match, big numbers can't be closer than their rounding
+	bound := math.Max(tolerance*math.Min(x, 1), (math.Nextafter(x, math.Inf(1))-x)/2)
+	// convergents h/k of the continued fraction
+	h, prevH := int64(1), int64(0)
+	k, prevK := int64(0), int64(1)
+	r := x
+	for i := 0; i < 64; i++ {
+		a := math.Floor(r)
+		if a*float64(k)+float64(prevK) > float64(maxDen) {
+			return 0, 0, false
+		}
+		h, prevH = int64(a)*h+prevH, h
+		k, prevK = int64(a)*k+prevK, k
+		if math.Abs(x-float64(h)/float64(k)) <= bound {
+			return h, k, true
+		}
+		if r == a {
+			break
+		}
+		r = 1 / (r - a)
+	}
+	return 0, 0, false
+}
+
+/**
+ * mixed: writes a fraction as a mixed number, e.g. 2 1/3
+ *
+ * @param num numerator, not negative
+ * @param den denominator
+ * @return string the fraction
+ */
+func mixed(num, den int64) string {
+	if den == 1 {
+		return strconv.FormatInt(num, 10)
+	}
+	if num < den {
+		return strconv.FormatInt(num, 10) + "/" + strconv.FormatInt(den, 10)
+	}
+	return strconv.FormatInt(num/den, 10) + " " + strconv.FormatInt(num%den, 10) + "/" + strconv.FormatInt(den, 10)
+}
+
+/**
+ * symbolic: writes a fraction multiplied by a symbol, e.g. 3π/4
+ *
+ * @param num numerator, not negative
+ * @param den denominator
+ * @param symbol the symbol, e.g. π or √2
+ * @return string the product
+ */
+func symbolic(num, den int64, symbol string) string {
+	res := symbol
+	if num != 1 {
+		res = strconv.FormatInt(num, 10) + symbol
+	}
+	if den != 1 {
+		res += "/" + strconv.FormatInt(den, 10)
+	}
+	return res
+}
+
+/**
+ * Fraction: writes a number as a fraction, a multiple of π or a surd, e.g. 2 1/3, 3π/4 or √2/2
+ *
+ * Integers and fractions with small denominators, which match the number almost exactly, are written as they are.
+ * Multiples of π and surds have to match the number almost exactly and better than a fraction within the tolerance,
+ * their numerators are at most 1000, so that big numbers aren't written as nonsense like 364913π/12.
+ *
+ * @param x the number
+ * @param tolerance biggest error of the fraction, relative to numbers below 1
+ * @return string the fraction
+ * @return bool false if the number isn't close to any fraction with a denominator up to 1000
+ */
+func Fraction(x, tolerance float64) (string, bool) {
+	if math.IsNaN(x) || math.IsInf(x, 0) {
+		return "", false
+	}
+	if x == 0 {
+		return "0", true
+	}
+	sign := ""
+	if x < 0 {
+		sign = "-"
+	}
+	x = math.Abs(x)
+	if num, den, ok := approximate(x, symbolicTolerance, maxSymbolicDenominator); ok {
+		return sign + mixed(num, den), true
+	}
+	num, den, ok := approximate(x, tolerance, maxDenominator)
+	bestError := math.Inf(1)
+	if ok {
+		bestError = math.Abs(x-float64(num)/float64(den)) / x
+	}
+	// symbolic multiples are tried in this order, the first one better than the fraction is used
+	symbols := []string{"π"}
+	factors := []float64{math.Pi}
+	for _, n := range surdRadicands {
+		symbols = append(symbols, "√"+strconv.FormatInt(n, 10))
+		factors = append(factors, math.Sqrt(float64(n)))
+	}
+	for i, factor := range factors {
+		symNum, symDen, symOk := approximate(x/factor, symbolicTolerance, maxSymbolicDenominator)
+		if !symOk || symNum > maxSymbolicNumerator {
+			continue
+		}
+		if math.Abs(x-float64(symNum)*factor/float64(symDen))/x < bestError {
+			return sign + symbolic(symNum, symDen, symbols[i]), true
+		}
+	}
+	if ok {
+		return sign + mixed(num, den), true
+	}
+	return "", false
+}
@@ -0,0 +1,126 @@
+package format
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+/**
+ * FloatBits: how a number is stored in an IEEE 754 binary floating point format
+ */
+type FloatBits struct {
+	Width     int     // 32 or 64 bits
+	Value     float64 // the number rounded to the format
+	Sign      string  // sign bit
+	Exponent  string  // bits of the biased exponent
+	Mantissa  string  // bits of the mantissa without the implicit leading bit
+	Hex       string  // all bits in hexadecimal, e.g. 0x3FF0000000000000
+	Power     int     // power of two of the leading bit, -1022 or -126 for subnormal numbers
+	Ulp       float64 // distance to the next number with a bigger magnitude, NaN for infinities and NaN
+	Previous  float64 // nearest smaller number
+	Next      float64 // nearest bigger number
+	Subnormal bool
+}
+
+/**
+ * Inspection: how a number is stored in the single and double precision formats
+ */
+type Inspection struct {
+	Float32 FloatBits
+	Float64 FloatBits
+}
+
+/**
+ * Inspect: shows how a number is stored in the single and double precision formats
+ *
+ * @param x the number
+ * @return Inspection the bits of both formats
+ */
+func Inspect(x float64) Inspection {
+	single := float32(x)
+	return Inspection{
+		Float32: floatBits(uint64(math.Float32bits(single)), 32, 8, float64(single),
+			float64(math.Nextafter32(single, float32(math.Inf(-1)))), float64(math.Nextafter32(single, float32(math.Inf(1))))),
+		Float64: floatBits(math.Float64bits(x), 64, 11, x, math.Nextafter(x, math.Inf(-1)), math.Nextafter(x, math.Inf(1))),
+	}
+}
+
+/**
+ * floatBits: splits bits of a floating point number into its fields
+ *
+ * @param bits the bits
+ * @param width count of the bits, 32 or 64
+ * @param exponentWidth count of the bits of the exponent
+ * @param value the number
+ * @param previous nearest smaller number
+ * @param next nearest bigger number
+ * @return FloatBits the fields
+ */
+func floatBits(bits uint64, width, exponentWidth int, value, previous, next float64) FloatBits {
+	mantissaWidth := width - 1 - exponentWidth
+	binary := fmt.Sprintf("%0*b", width, bits)
+	bias := 1<<(exponentWidth-1) - 1
+	exponent := int(bits>>mantissaWidth) & (1<<exponentWidth - 1)
+	res := FloatBits{
+		Width:     width,
+		Value:     value,
+		Sign:      binary[:1],
+		Exponent:  binary[1 : 1+exponentWidth],
+		Mantissa:  binary[1+exponentWidth:],
+		Hex:       fmt.Sprintf("0x%0*X", width/4, bits),
+		Power:     exponent - bias,
+		Previous:  previous,
+		Next:      next,
+		Subnormal: exponent == 0 && bits&(1<<mantissaWidth-1) != 0,
+	}
+	switch exponent {
+	case 0:
+		// subnormal numbers and zero have the exponent of the smallest normal numbers
+		res.Power = 1 - bias
+		res.Ulp = math.Ldexp(1, res.Power-mantissaWidth)
+	case 1<<exponentWidth - 1:
+		res.Ulp = math.NaN()
+	default:
+		res.Ulp = math.Ldexp(1, res.Power-mantissaWidth)
+	}
+	return res
+}
+
+/**
+ * String: describes the fields of the number, each on its own line
+ *
+ * @return string the description
+ */
+func (f FloatBits) String() string {
+	number := func(x float64) string {
+		return strconv.FormatFloat(x, 'g', -1, f.Width)
+	}
+	lines := []string{
+		fmt.Sprintf("float%d %s", f.Width, number(f.Value)),
+		fmt.Sprintf("  sign      %s", f.Sign),
+		fmt.Sprintf("  exponent  %s (2^%d)", f.Exponent, f.Power),
+		fmt.Sprintf("  mantissa  %s", f.Mantissa),
+		fmt.Sprintf("  hex       %s", f.Hex),
+		fmt.Sprintf("  ulp       %s", number(f.Ulp)),
+		fmt.Sprintf("  previous  %s", number(f.Previous)),
+		fmt.Sprintf("  next      %s", number(f.Next)),
+	}
+	if f.Exponent == strings.Repeat("1", len(f.Exponent)) {
+		lines[2] = fmt.Sprintf("  exponent  %s (infinity or NaN)", f.Exponent)
+	}
+	if f.Subnormal {
+		lines[0] += " (subnormal)"
+	}
+	return strings.Join(lines, "\n")
+}
+
+/**
+ * String: describes the number in the double precision format followed by the single precision one
+ *
+ * @return string the description
+ */
+func (i Inspection) String() string {
+	return i.Float64.String() + "\n" + i.Float32.String()
+}
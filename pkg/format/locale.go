@@ -0,0 +1,51 @@
+package format
+
+/**
+ * Locale: marks used in numbers and between arguments of functions, both in expressions and results
+ *
+ * Grouping marks in expressions are optional, whitespace inside numbers is ignored in every locale.
+ */
+type Locale struct {
+	Name              string
+	DecimalMark       rune
+	GroupingMark      rune
+	ArgumentSeparator rune
+}
+
+// supported locales
+var (
+	EnUS = Locale{"en_US", '.', ',', ','}
+	CsCZ = Locale{"cs_CZ", ',', '\u00a0', ';'} // grouped by no-break spaces
+	DeDE = Locale{"de_DE", ',', '.', ';'}
+)
+
+// supported locales, which can be selected by their names
+var Locales = []Locale{EnUS, CsCZ, DeDE}
+
+/**
+ * LookupLocale: finds a supported locale by its name, e.g. cs_CZ
+ *
+ * @param name name of the locale
+ * @return Locale the locale
+ * @return bool false if the locale isn't supported
+ */
+func LookupLocale(name string) (Locale, bool) {
+	for _, locale := range Locales {
+		if locale.Name == name {
+			return locale, true
+		}
+	}
+	return Locale{}, false
+}
+
+/**
+ * orDefault: returns the locale, or en_US if it's the zero value
+ *
+ * @return Locale the locale
+ */
+func (l Locale) orDefault() Locale {
+	if l.DecimalMark == 0 {
+		return EnUS
+	}
+	return l
+}
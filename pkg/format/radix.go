@@ -0,0 +1,58 @@
+package format
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// fractional digits written in another base unless a frontend sets another limit
+const DefaultRadixDigits = 32
+
+/**
+ * Radix: writes a number in a base from 2 to 36 as a literal, which can be used in expressions, e.g. 16#FF
+ *
+ * The integer part is exact, the fractional part is cut after maxDigits digits, which is marked by an ellipsis.
+ *
+ * @param x the number
+ * @param base the base
+ * @param maxDigits biggest count of fractional digits
+ * @param locale decimal mark
+ * @return string the number, e.g. 2#0.0001100110011…
+ * @return error if the base isn't from 2 to 36 or the number isn't finite
+ */
+func Radix(x float64, base int, maxDigits int, locale Locale) (string, error) {
+	if base < 2 || base > 36 {
+		return "", errors.New("base has to be from 2 to 36")
+	}
+	if math.IsNaN(x) || math.IsInf(x, 0) {
+		return "", errors.New("only finite numbers can be written in another base")
+	}
+	integer, fraction := math.Modf(math.Abs(x))
+	bigInteger, _ := big.NewFloat(integer).Int(nil)
+	res := strconv.Itoa(base) + "#"
+	if x < 0 {
+		res = "-" + res
+	}
+	res += strings.ToUpper(bigInteger.Text(base))
+	if fraction == 0 {
+		return res, nil
+	}
+	// every digit adds at most 6 bits to the 53 bits of the fraction, so the digits are exact
+	digits := new(big.Float).SetPrec(uint(64 + 6*maxDigits)).SetFloat64(fraction)
+	bigBase := new(big.Float).SetInt64(int64(base))
+	var b strings.Builder
+	b.WriteRune(locale.orDefault().DecimalMark)
+	for i := 0; i < maxDigits && digits.Sign() != 0; i++ {
+		digits.Mul(digits, bigBase)
+		digit, _ := digits.Int64()
+		digits.Sub(digits, new(big.Float).SetInt64(digit))
+		b.WriteString(strings.ToUpper(strconv.FormatInt(digit, base)))
+	}
+	if digits.Sign() != 0 {
+		b.WriteString("…")
+	}
+	return res + b.String(), nil
+}
@@ -0,0 +1,288 @@
+package interpreter
+
+import "fmt"
+
+/**
+ * Span: position of a node in the parsed expression, byte offsets from Start up to End, End excluded
+ *
+ * Spans of operators cover their operands too and expressions in brackets cover the brackets,
+ * e.g. the span of 1+2 in 3*(1+2) is from 2 to 7.
+ * Nodes, which the parser adds on its own, get the span of the text they were created from,
+ * e.g. the number -1 of a unary minus has the span of the minus.
+ */
+type Span struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+/**
+ * IsZero: checks whether the span is unknown, which is the case of nodes created outside of the parser
+ *
+ * @return bool true for the zero span
+ */
+func (s Span) IsZero() bool {
+	return s == Span{}
+}
+
+/**
+ * join: returns the smallest span covering both spans, unknown spans are ignored
+ *
+ * @param t the other span
+ * @return Span the covering span
+ */
+func (s Span) join(t Span) Span {
+	if s.IsZero() {
+		return t
+	}
+	if t.IsZero() {
+		return s
+	}
+	if t.Start < s.Start {
+		s.Start = t.Start
+	}
+	if t.End > s.End {
+		s.End = t.End
+	}
+	return s
+}
+
+/**
+ * NodeKind: kind of a node of the expression tree
+ */
+type NodeKind int
+
+const (
+	NumberNode     NodeKind = iota // number literal
+	IdentifierNode                 // variable, constant or function called without brackets, e.g. pi or rand
+	UnaryNode                      // abs, fac, dfac or a unary sign neg or pos with a single operand
+	BinaryNode                     // operator with two operands, e.g. +, pow, root written as √, ± or ->
+	CallNode                       // function call with any number of arguments, lists are calls of list
+)
+
+/**
+ * String: returns the name of the node kind
+ *
+ * @return string the name, e.g. binary
+ */
+func (k NodeKind) String() string {
+	switch k {
+	case NumberNode:
+		return "number"
+	case IdentifierNode:
+		return "identifier"
+	case UnaryNode:
+		return "unary"
+	case BinaryNode:
+		return "binary"
+	case CallNode:
+		return "call"
+	}
+	return fmt.Sprintf("NodeKind(%d)", int(k))
+}
+
+// names of the unary signs, which the parser stores as a multiplication by -1 or +1
+var signNames = map[string]string{"-": "neg", "+": "pos"}
+
+/**
+ * unarySign: recognizes a unary sign, which the parser stores as a multiplication by -1 or +1
+ *
+ * @param n the node
+ * @return string - or +, empty if the node isn't a unary sign
+ */
+func unarySign(n *TreeNode) string {
+	if n.token.stringValue != "*" || n.rightNode == nil || n.rightNode.token.tokenType != NUMBER {
+		return ""
+	}
+	switch n.rightNode.token.stringValue {
+	case "-1":
+		return "-"
+	case "+1":
+		return "+"
+	}
+	return ""
+}
+
+/**
+ * Kind: returns the kind of the node, unary signs are unary nodes
+ *
+ * @return NodeKind the kind
+ */
+func (n *TreeNode) Kind() NodeKind {
+	op := n.token.stringValue
+	switch {
+	case n.token.tokenType == NUMBER:
+		return NumberNode
+	case unaryOperators[op] || unarySign(n) != "":
+		return UnaryNode
+	case n.leftNode == nil && n.rightNode == nil:
+		return IdentifierNode
+	case n.rightNode == nil && isIdentifier(op):
+		return CallNode
+	}
+	return BinaryNode
+}
+
+/**
+ * Token: returns the token of the node
+ *
+ * @return Token the token
+ */
+func (n *TreeNode) Token() Token {
+	return n.token
+}
+
+/**
+ * Op: returns the name of the operator, identifier or called function, e.g. pow, neg, pi or sin,
+ * or the literal of a number
+ *
+ * @return string the name
+ */
+func (n *TreeNode) Op() string {
+	if sign := unarySign(n); sign != "" {
+		return signNames[sign]
+	}
+	return n.token.stringValue
+}
+
+/**
+ * Value: returns the float value of a number node
+ *
+ * @return float64 the number, 0 for other nodes
+ */
+func (n *TreeNode) Value() float64 {
+	return n.token.floatValue
+}
+
+/**
+ * Left: returns the left child of the node, which is the only operand of unary operators,
+ * the radicand of roots and the chain of arguments of function calls
+ *
+ * @return *TreeNode the left child, nil if there's none
+ */
+func (n *TreeNode) Left() *TreeNode {
+	return n.leftNode
+}
+
+/**
+ * Right: returns the right child of the node, which is the degree of roots written as √
+ *
+ * @return *TreeNode the right child, nil if there's none and for unary nodes
+ */
+func (n *TreeNode) Right() *TreeNode {
+	if n.Kind() == UnaryNode {
+		return nil
+	}
+	return n.rightNode
+}
+
+/**
+ * Span: returns the position of the node in the parsed expression
+ *
+ * @return Span the position, zero for nodes created outside of the parser
+ */
+func (n *TreeNode) Span() Span {
+	return n.span
+}
+
+/**
+ * Args: returns the arguments of a function call in their order, e.g. 1, 2 and 3 of max(1, 2, 3)
+ *
+ * @return []*TreeNode the arguments, nil for other nodes than calls
+ */
+func (n *TreeNode) Args() []*TreeNode {
+	if n.Kind() != CallNode {
+		return nil
+	}
+	return callArgs(n)
+}
+
+/**
+ * Children: returns the operands of the node in their order, arguments of function calls
+ * are returned directly, not as the chain of commas they're stored in
+ *
+ * @return []*TreeNode the operands, nil for numbers and identifiers
+ */
+func (n *TreeNode) Children() []*TreeNode {
+	switch n.Kind() {
+	case NumberNode, IdentifierNode:
+		return nil
+	case UnaryNode:
+		return []*TreeNode{n.leftNode}
+	case CallNode:
+		return callArgs(n)
+	}
+	return []*TreeNode{n.leftNode, n.rightNode}
+}
+
+/**
+ * Visitor: its Visit method is called for each node by Walk
+ *
+ * If Visit returns a non-nil visitor w, the children of the node are walked with w,
+ * after them w.Visit(nil) is called.
+ */
+type Visitor interface {
+	Visit(node *TreeNode) (w Visitor)
+}
+
+/**
+ * Walk: traverses the tree in depth-first order, the same as ast.Walk of the Go standard library
+ *
+ * @param v the visitor, whose Visit is called with the node
+ * @param node root of the walked tree
+ */
+func Walk(v Visitor, node *TreeNode) {
+	if v = v.Visit(node); v == nil {
+		return
+	}
+	for _, child := range node.Children() {
+		if child != nil {
+			Walk(v, child)
+		}
+	}
+	v.Visit(nil)
+}
+
+// inspector: visitor calling a function, used by Inspect
+type inspector func(*TreeNode) bool
+
+/**
+ * Visit: calls the function with the node
+ *
+ * @param node the visited node
+ * @return Visitor the inspector if the function returned true, nil otherwise
+ */
+func (f inspector) Visit(node *TreeNode) Visitor {
+	if f(node) {
+		return f
+	}
+	return nil
+}
+
+/**
+ * Inspect: traverses the tree in depth-first order and calls f for each node,
+ * children of a node are skipped when f returns false, after the children f(nil) is called
+ *
+ * @param node root of the inspected tree
+ * @param f the function
+ */
+func Inspect(node *TreeNode, f func(*TreeNode) bool) {
+	Walk(inspector(f), node)
+}
+
+/**
+ * shiftSpans: moves spans of all nodes of the tree by an offset, when a part of the input was parsed
+ *
+ * @param node root of the tree
+ * @param offset position of the parsed part in the input
+ */
+func shiftSpans(node *TreeNode, offset int) {
+	if node == nil {
+		return
+	}
+	if !node.span.IsZero() {
+		node.span.Start += offset
+		node.span.End += offset
+	}
+	shiftSpans(node.leftNode, offset)
+	shiftSpans(node.rightNode, offset)
+}
@@ -0,0 +1,209 @@
+package interpreter
+
+import (
+	"fmt"
+)
+
+/**
+ * Backend: numeric representation, in which arithmetic expressions can be evaluated, e.g. floats, exact fractions
+ * or decimals
+ *
+ * InterpretBackend walks the expression tree and leaves numbers and operators to the backend,
+ * so every backend evaluates the same expressions without a tree walk of its own.
+ */
+type Backend[N any] interface {
+	// Name: name of the mode used in error messages, e.g. decimal
+	Name() string
+	// Supports: whether the backend implements the operator, e.g. + or fac
+	Supports(op string) bool
+	// Literal: converts a number literal, x is the literal parsed as a float, the literal is empty
+	// for numbers created outside of the parser
+	Literal(literal string, x float64) (N, error)
+	// Identifier: returns the number named by an identifier, e.g. i, false if the backend doesn't know the name
+	Identifier(name string) (N, bool)
+	// Unary: applies abs, fac or dfac
+	Unary(op string, x N) (N, error)
+	// Binary: applies an operator with two operands, e.g. + or pow
+	Binary(op string, a, b N) (N, error)
+}
+
+/**
+ * NodeEvaluator: backend, which evaluates some operator nodes on its own instead of leaving them to InterpretBackend,
+ * e.g. function calls of the standard mode or exact exponents of the modular mode
+ */
+type NodeEvaluator[N any] interface {
+	// EvaluateNode: evaluates the node, false leaves it to InterpretBackend
+	EvaluateNode(node *TreeNode) (N, bool, error)
+}
+
+/**
+ * nodeRecorder: backend, which is told the value of every subtree InterpretBackend evaluates,
+ * e.g. to explain the calculation
+ */
+type nodeRecorder[N any] interface {
+	// recordNode: records the value of the subtree
+	recordNode(node *TreeNode, x N)
+}
+
+// operators with one operand, the others have two
+var unaryOperators = map[string]bool{"abs": true, "fac": true, "dfac": true}
+
+/**
+ * InterpretBackend: calculates the result of the expression in the numeric representation of the backend
+ *
+ * Only numbers, identifiers known to the backend and operators it supports can be used,
+ * unless the backend is a NodeEvaluator, which evaluates the other nodes on its own.
+ *
+ * @param root Pointer to the AST node being evaluated
+ * @param backend the numeric representation
+ * @return N result of the whole expression
+ * @return error if the expression uses anything the backend doesn't support or a calculation fails
+ */
+func InterpretBackend[N any](root *TreeNode, backend Backend[N]) (N, error) {
+	x, err := interpretNode(root, backend)
+	if recorder, ok := backend.(nodeRecorder[N]); ok && err == nil {
+		recorder.recordNode(root, x)
+	}
+	return x, err
+}
+
+/**
+ * interpretNode: calculates the result of a subtree in the numeric representation of the backend,
+ * its operands are calculated by InterpretBackend
+ *
+ * @param root Pointer to the AST node being evaluated
+ * @param backend the numeric representation
+ * @return N result of the subtree
+ * @return error if the subtree uses anything the backend doesn't support or a calculation fails
+ */
+func interpretNode[N any](root *TreeNode, backend Backend[N]) (N, error) {
+	var zero N
+	if root == nil {
+		return zero, fmt.Errorf("cannot interpret an empty node")
+	}
+	if root.token.tokenType == NUMBER {
+		return backend.Literal(root.token.stringValue, evalNumber(root))
+	}
+	if root.token.tokenType != OPERATOR {
+		return zero, fmt.Errorf("invalid token type: %d", root.token.tokenType)
+	}
+
+	if evaluator, ok := backend.(NodeEvaluator[N]); ok {
+		if x, handled, err := evaluator.EvaluateNode(root); handled {
+			return x, err
+		}
+	}
+
+	op := root.token.stringValue
+	if root.leftNode == nil && root.rightNode == nil && isIdentifier(op) {
+		if x, ok := backend.Identifier(op); ok {
+			return x, nil
+		}
+	}
+	if !backend.Supports(op) {
+		return zero, fmt.Errorf("'%v' can't be used in %s mode", op, backend.Name())
+	}
+	if unaryOperators[op] {
+		x, err := InterpretBackend(root.leftNode, backend)
+		if err != nil {
+			return zero, err
+		}
+		return backend.Unary(op, x)
+	}
+
+	leftNode, rightNode := root.leftNode, root.rightNode
+	if op == "root" {
+		var err error
+		if leftNode, rightNode, err = rootOperands(root); err != nil {
+			return zero, err
+		}
+	}
+	a, err := InterpretBackend(leftNode, backend)
+	if err != nil {
+		return zero, err
+	}
+	b, err := InterpretBackend(rightNode, backend)
+	if err != nil {
+		return zero, err
+	}
+	return backend.Binary(op, a, b)
+}
+
+// operators of the arithmetic backends
+var arithmeticOperators = map[string]bool{
+	"+": true, "-": true, "*": true, "/": true, "mod": true, "pow": true, "root": true, "<": true, ">": true,
+	"abs": true, "fac": true, "dfac": true,
+}
+
+/**
+ * FloatBackend: 64-bit floats calculated by the mathfunc package, the same as the standard mode
+ */
+type FloatBackend struct{}
+
+/**
+ * Name: name of the mode used in error messages
+ *
+ * @return string float
+ */
+func (FloatBackend) Name() string {
+	return "float"
+}
+
+/**
+ * Supports: whether the backend implements the operator
+ *
+ * @param op name of the operator
+ * @return bool true for arithmetic operators and comparisons
+ */
+func (FloatBackend) Supports(op string) bool {
+	return arithmeticOperators[op]
+}
+
+/**
+ * Literal: returns the number literal parsed as a float
+ *
+ * @param literal string value of the number token
+ * @param x the literal parsed as a float
+ * @return float64 the number
+ * @return error never
+ */
+func (FloatBackend) Literal(literal string, x float64) (float64, error) {
+	return x, nil
+}
+
+/**
+ * Identifier: returns the value of a physical constant
+ *
+ * @param name symbol of the constant
+ * @return float64 value of the constant
+ * @return bool false if there's no such constant
+ */
+func (FloatBackend) Identifier(name string) (float64, bool) {
+	constant, ok := lookupConstant(name)
+	return constant.Value, ok
+}
+
+/**
+ * Unary: applies abs, fac or dfac
+ *
+ * @param op name of the operator
+ * @param x operand
+ * @return float64 result of the operator
+ * @return error if the operator fails
+ */
+func (FloatBackend) Unary(op string, x float64) (float64, error) {
+	return applyOperator(op, x, 0)
+}
+
+/**
+ * Binary: applies an operator with two operands
+ *
+ * @param op name of the operator
+ * @param a left operand
+ * @param b right operand
+ * @return float64 result of the operator
+ * @return error if the operator fails
+ */
+func (FloatBackend) Binary(op string, a, b float64) (float64, error) {
+	return applyOperator(op, a, b)
+}
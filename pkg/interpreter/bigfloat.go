@@ -0,0 +1,246 @@
+package interpreter
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// biggest absolute value of an integer exponent of a big float
+const maxBigFloatExponent = 1 << 20
+
+// maximal number of iterations of Newton's method for roots of big floats
+const maxBigFloatRootIterations = 10000
+
+/**
+ * BigFloatBackend: binary floats with a mantissa of the given number of bits, e.g. 256 bits for about 77 digits
+ *
+ * Powers need integer exponents and roots integer degrees.
+ */
+type BigFloatBackend struct {
+	Precision uint // bits of the mantissa
+}
+
+/**
+ * newFloat: creates a zero with the precision of the backend
+ *
+ * @return *big.Float the zero
+ */
+func (b BigFloatBackend) newFloat() *big.Float {
+	return new(big.Float).SetPrec(b.Precision)
+}
+
+/**
+ * Name: name of the mode used in error messages
+ *
+ * @return string big float
+ */
+func (BigFloatBackend) Name() string {
+	return "big float"
+}
+
+/**
+ * Supports: whether the backend implements the operator
+ *
+ * @param op name of the operator
+ * @return bool true for arithmetic operators and comparisons
+ */
+func (BigFloatBackend) Supports(op string) bool {
+	return arithmeticOperators[op]
+}
+
+/**
+ * Literal: returns the number literal rounded to the precision of the backend
+ *
+ * @param literal string value of the number token
+ * @param x the literal parsed as a float, used for numbers without a literal
+ * @return *big.Float the number
+ * @return error if the number isn't finite
+ */
+func (b BigFloatBackend) Literal(literal string, x float64) (*big.Float, error) {
+	if res, _, err := b.newFloat().Parse(literal, 10); err == nil {
+		return res, nil
+	}
+	if math.IsInf(x, 0) || math.IsNaN(x) {
+		return nil, fmt.Errorf("%v isn't a finite number", x)
+	}
+	return b.newFloat().SetFloat64(x), nil
+}
+
+/**
+ * Identifier: returns the value of a physical constant
+ *
+ * @param name symbol of the constant
+ * @return *big.Float value of the constant, it has only the precision of a float64 value
+ * @return bool false if there's no such constant
+ */
+func (b BigFloatBackend) Identifier(name string) (*big.Float, bool) {
+	constant, ok := lookupConstant(name)
+	if !ok {
+		return nil, false
+	}
+	return b.newFloat().SetFloat64(constant.Value), true
+}
+
+/**
+ * Unary: applies abs, fac or dfac
+ *
+ * @param op name of the operator
+ * @param x operand
+ * @return *big.Float result of the operator
+ * @return error if a factorial isn't of a natural number
+ */
+func (b BigFloatBackend) Unary(op string, x *big.Float) (*big.Float, error) {
+	if op == "abs" {
+		return b.newFloat().Abs(x), nil
+	}
+	if !x.IsInt() {
+		return nil, fmt.Errorf("factorial in big float mode works only with natural numbers")
+	}
+	n, _ := x.Int(nil)
+	res, err := exactFactorial(op, n)
+	if err != nil {
+		return nil, err
+	}
+	return b.newFloat().SetInt(res), nil
+}
+
+/**
+ * Binary: applies an operator with two operands
+ *
+ * @param op name of the operator
+ * @param x left operand
+ * @param y right operand
+ * @return *big.Float result of the operator
+ * @return error if the operation fails
+ */
+func (b BigFloatBackend) Binary(op string, x, y *big.Float) (*big.Float, error) {
+	switch op {
+	case "+":
+		return b.newFloat().Add(x, y), nil
+	case "-":
+		return b.newFloat().Sub(x, y), nil
+	case "*":
+		return b.newFloat().Mul(x, y), nil
+	case "/":
+		if y.Sign() == 0 {
+			return nil, fmt.Errorf("cannot divide by zero")
+		}
+		return b.newFloat().Quo(x, y), nil
+	case "mod":
+		if y.Sign() == 0 {
+			return nil, fmt.Errorf("cannot divide by zero")
+		}
+		// x - y*floor(x/y), the remainder has the sign of the divisor
+		q := b.newFloat().Quo(x, y)
+		floor, _ := q.Int(nil)
+		if q.Sign() < 0 && !q.IsInt() {
+			floor.Sub(floor, big.NewInt(1))
+		}
+		return b.newFloat().Sub(x, b.newFloat().Mul(y, b.newFloat().SetInt(floor))), nil
+	case "pow":
+		return b.power(x, y)
+	case "root":
+		return b.root(x, y)
+	case "<":
+		return b.newFloat().SetInt64(int64(boolToFloat(x.Cmp(y) < 0))), nil
+	default:
+		return b.newFloat().SetInt64(int64(boolToFloat(x.Cmp(y) > 0))), nil
+	}
+}
+
+/**
+ * power: raises a number to an integer power using exponentiation by squaring
+ *
+ * @param base the base
+ * @param exponent the exponent, it has to be an integer
+ * @return *big.Float the power
+ * @return error if the power is undefined or the exponent isn't an integer
+ */
+func (b BigFloatBackend) power(base, exponent *big.Float) (*big.Float, error) {
+	if !exponent.IsInt() {
+		return nil, fmt.Errorf("exponents in big float mode have to be integers")
+	}
+	if base.Sign() == 0 {
+		switch exponent.Sign() {
+		case 0:
+			return nil, fmt.Errorf("0^0 is undefined")
+		case -1:
+			return nil, fmt.Errorf("cannot raise 0 to a negative power")
+		}
+		return b.newFloat(), nil
+	}
+	e, _ := exponent.Int64()
+	if e > maxBigFloatExponent || e < -maxBigFloatExponent {
+		return nil, fmt.Errorf("exponent %v is too big", exponent)
+	}
+	n := e
+	if n < 0 {
+		n = -n
+	}
+	res, x := b.newFloat().SetInt64(1), b.newFloat().Set(base)
+	for ; n > 0; n >>= 1 {
+		if n&1 == 1 {
+			res.Mul(res, x)
+		}
+		if n > 1 {
+			x.Mul(x, x)
+		}
+	}
+	if e < 0 {
+		res.Quo(b.newFloat().SetInt64(1), res)
+	}
+	return res, nil
+}
+
+/**
+ * root: returns the nth root of x using Newton's method
+ *
+ * @param x the radicand
+ * @param degree degree of the root, it has to be a positive integer
+ * @return *big.Float the root
+ * @return error if the degree isn't a positive integer or an even root of a negative number is calculated
+ */
+func (b BigFloatBackend) root(x, degree *big.Float) (*big.Float, error) {
+	if !degree.IsInt() || degree.Sign() <= 0 {
+		return nil, fmt.Errorf("degrees of roots in big float mode have to be positive integers")
+	}
+	d, _ := degree.Int64()
+	if d > maxRationalRootDegree {
+		return nil, fmt.Errorf("degree %v is too big", degree)
+	}
+	n := int(d)
+	if x.Sign() < 0 {
+		if n%2 == 0 {
+			return nil, fmt.Errorf("can't calculate root %d of a negative number: %v", n, x)
+		}
+		res, err := b.root(b.newFloat().Neg(x), degree)
+		if err != nil {
+			return nil, err
+		}
+		return res.Neg(res), nil
+	}
+	if x.Sign() == 0 || n == 1 {
+		return b.newFloat().Set(x), nil
+	}
+	if n == 2 {
+		return b.newFloat().Sqrt(x), nil
+	}
+
+	// x < 2^exp, so 2^ceil(exp/n) is above the root and the approximations decrease towards it
+	exp := x.MantExp(nil)
+	work := BigFloatBackend{b.Precision + 32}
+	res := work.newFloat().SetMantExp(work.newFloat().SetInt64(1), (exp+n-1)/n+1)
+	bigN, bigN1 := work.newFloat().SetInt64(int64(n)), work.newFloat().SetInt64(int64(n-1))
+	for i := 0; i < maxBigFloatRootIterations; i++ {
+		power, _ := work.power(res, bigN1)
+		next := work.newFloat().Quo(x, power)
+		next.Add(next, work.newFloat().Mul(bigN1, res))
+		next.Quo(next, bigN)
+		if next.Cmp(res) >= 0 {
+			break
+		}
+		res = next
+	}
+	return b.newFloat().Set(res), nil
+}
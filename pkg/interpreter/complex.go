@@ -0,0 +1,172 @@
+package interpreter
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+	"strconv"
+)
+
+/**
+ * ComplexBackend: complex numbers, the imaginary unit is written as i, e.g. (1 + 2*i)^2 or √(-4)
+ *
+ * Powers and roots give their principal values, so the cube root of -8 is 1 + 1.732i.
+ * Modulo, factorials and comparisons work only with real numbers.
+ */
+type ComplexBackend struct{}
+
+/**
+ * Name: name of the mode used in error messages
+ *
+ * @return string complex
+ */
+func (ComplexBackend) Name() string {
+	return "complex"
+}
+
+/**
+ * Supports: whether the backend implements the operator
+ *
+ * @param op name of the operator
+ * @return bool true for arithmetic operators and comparisons
+ */
+func (ComplexBackend) Supports(op string) bool {
+	return arithmeticOperators[op]
+}
+
+/**
+ * Literal: returns the number literal as a real complex number
+ *
+ * @param literal string value of the number token
+ * @param x the literal parsed as a float
+ * @return complex128 the number
+ * @return error never
+ */
+func (ComplexBackend) Literal(literal string, x float64) (complex128, error) {
+	return complex(x, 0), nil
+}
+
+/**
+ * Identifier: returns the imaginary unit i or the value of a physical constant
+ *
+ * @param name the identifier
+ * @return complex128 the number
+ * @return bool false if it's neither i nor a constant
+ */
+func (ComplexBackend) Identifier(name string) (complex128, bool) {
+	if name == "i" {
+		return 1i, true
+	}
+	constant, ok := lookupConstant(name)
+	return complex(constant.Value, 0), ok
+}
+
+/**
+ * Unary: applies abs, fac or dfac, abs returns the modulus of the number
+ *
+ * @param op name of the operator
+ * @param x operand
+ * @return complex128 result of the operator
+ * @return error if a factorial isn't of a real number or fails
+ */
+func (ComplexBackend) Unary(op string, x complex128) (complex128, error) {
+	if op == "abs" {
+		return complex(cmplx.Abs(x), 0), nil
+	}
+	if imag(x) != 0 {
+		return 0, fmt.Errorf("'%v' works only with real numbers", op)
+	}
+	res, err := applyOperator(op, real(x), 0)
+	return complex(res, 0), err
+}
+
+/**
+ * Binary: applies an operator with two operands
+ *
+ * @param op name of the operator
+ * @param a left operand
+ * @param b right operand
+ * @return complex128 result of the operator
+ * @return error if the operation fails, is undefined or its result is too big
+ */
+func (ComplexBackend) Binary(op string, a, b complex128) (complex128, error) {
+	var res complex128
+	switch op {
+	case "+":
+		res = a + b
+	case "-":
+		res = a - b
+	case "*":
+		res = a * b
+	case "/":
+		if b == 0 {
+			return 0, fmt.Errorf("cannot divide by zero")
+		}
+		res = a / b
+	case "pow":
+		if a == 0 && b == 0 {
+			return 0, fmt.Errorf("0^0 is undefined")
+		}
+		if a == 0 && real(b) < 0 {
+			return 0, fmt.Errorf("cannot raise 0 to a negative power")
+		}
+		res = complexPower(a, b)
+	case "root":
+		if b == 0 {
+			return 0, fmt.Errorf("can't calculate 0th root")
+		}
+		if b == 2 {
+			res = cmplx.Sqrt(a)
+		} else {
+			res = complexPower(a, 1/b)
+		}
+	default:
+		// mod, < and >
+		if imag(a) != 0 || imag(b) != 0 {
+			return 0, fmt.Errorf("'%v' works only with real numbers", op)
+		}
+		x, err := applyOperator(op, real(a), real(b))
+		return complex(x, 0), err
+	}
+	if cmplx.IsInf(res) || cmplx.IsNaN(res) {
+		return 0, fmt.Errorf("result is too big")
+	}
+	return res, nil
+}
+
+/**
+ * complexPower: returns the principal value of a power, integer powers of real numbers stay real
+ *
+ * @param a the base
+ * @param b the exponent
+ * @return complex128 the power
+ */
+func complexPower(a, b complex128) complex128 {
+	if imag(a) == 0 && imag(b) == 0 && (real(a) >= 0 || real(b) == math.Trunc(real(b))) {
+		return complex(math.Pow(real(a), real(b)), 0)
+	}
+	return cmplx.Pow(a, b)
+}
+
+/**
+ * FormatComplex: formats a complex number, e.g. 3, 2i or 1 - 0.5i
+ *
+ * @param x the number
+ * @return string formatted number
+ */
+func FormatComplex(x complex128) string {
+	number := func(f float64) string {
+		return strconv.FormatFloat(f, 'g', 10, 64)
+	}
+	re, im := real(x), imag(x)
+	switch {
+	case im == 0:
+		return number(re)
+	case re == 0:
+		return number(im) + "i"
+	case im < 0:
+		return number(re) + " - " + number(-im) + "i"
+	default:
+		return number(re) + " + " + number(im) + "i"
+	}
+}
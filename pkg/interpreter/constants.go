@@ -0,0 +1,76 @@
+package interpreter
+
+import (
+	"fmt"
+	"math"
+)
+
+/**
+ * Constant: physical constant, which can be used in expressions by its symbol
+ */
+type Constant struct {
+	Symbol      string
+	Name        string
+	Value       float64
+	Uncertainty float64 // standard uncertainty, 0 for exact constants
+	Unit        string
+}
+
+// CODATA 2018 recommended values of physical constants
+var Constants = []Constant{
+	{"alpha", "fine-structure constant", 7.2973525693e-3, 0.0000000011e-3, ""},
+	{"u", "atomic mass constant", 1.66053906660e-27, 0.00000000050e-27, "kg"},
+	{"N_A", "Avogadro constant", 6.02214076e23, 0, "mol⁻¹"},
+	{"a_B", "Bohr radius", 5.29177210903e-11, 0.00000000080e-11, "m"},
+	{"k_B", "Boltzmann constant", 1.380649e-23, 0, "J K⁻¹"},
+	{"e_charge", "elementary charge", 1.602176634e-19, 0, "C"},
+	{"m_e", "electron mass", 9.1093837015e-31, 0.0000000028e-31, "kg"},
+	{"F", "Faraday constant", 96485.33212, 0, "C mol⁻¹"},
+	{"R", "molar gas constant", 8.314462618, 0, "J mol⁻¹ K⁻¹"},
+	{"G", "Newtonian constant of gravitation", 6.67430e-11, 0.00015e-11, "m³ kg⁻¹ s⁻²"},
+	{"m_n", "neutron mass", 1.67492749804e-27, 0.00000000095e-27, "kg"},
+	{"h", "Planck constant", 6.62607015e-34, 0, "J s"},
+	{"hbar", "reduced Planck constant", 6.62607015e-34 / (2 * math.Pi), 0, "J s"},
+	{"m_p", "proton mass", 1.67262192369e-27, 0.00000000051e-27, "kg"},
+	{"R_inf", "Rydberg constant", 10973731.568160, 0.000021, "m⁻¹"},
+	{"c", "speed of light in vacuum", 299792458, 0, "m s⁻¹"},
+	{"g_n", "standard acceleration of gravity", 9.80665, 0, "m s⁻²"},
+	{"sigma_SB", "Stefan-Boltzmann constant", 5.670374419e-8, 0, "W m⁻² K⁻⁴"},
+	{"epsilon_vac", "vacuum electric permittivity", 8.8541878128e-12, 0.0000000013e-12, "F m⁻¹"},
+	{"mu_vac", "vacuum magnetic permeability", 1.25663706212e-6, 0.00000000019e-6, "N A⁻²"},
+}
+
+/**
+ * lookupConstant: finds a physical constant by its symbol
+ *
+ * @param symbol symbol of the constant
+ * @return Constant the constant
+ * @return bool false if there's no such constant
+ */
+func lookupConstant(symbol string) (Constant, bool) {
+	for _, constant := range Constants {
+		if constant.Symbol == symbol {
+			return constant, true
+		}
+	}
+	return Constant{}, false
+}
+
+/**
+ * evalUncertainConstant: evaluates uncertain(symbol), the physical constant with its standard uncertainty
+ *
+ * Constants are exact numbers unless they are written this way, each call is an independent measured quantity.
+ *
+ * @param node Pointer to the uncertain node
+ * @return Value value of the constant with its uncertainty
+ * @return error if the argument isn't a symbol of a constant
+ */
+func evalUncertainConstant(node *TreeNode) (Value, error) {
+	args := callArgs(node)
+	if len(args) == 1 && args[0].Kind() == IdentifierNode {
+		if constant, ok := lookupConstant(args[0].token.stringValue); ok {
+			return Value{Number: constant.Value, Uncertainty: constant.Uncertainty}, nil
+		}
+	}
+	return Value{}, fmt.Errorf("argument of uncertain has to be a symbol of a physical constant")
+}
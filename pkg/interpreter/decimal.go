@@ -0,0 +1,148 @@
+package interpreter
+
+import (
+	"fmt"
+	"ivs-calculator/pkg/mathfunc"
+	"math/big"
+	"strconv"
+)
+
+/**
+ * InterpretDecimal: calculates the exact decimal result of the expression, e.g. 0.1 + 0.2 is exactly 0.3
+ *
+ * Number literals are read digit by digit, so they aren't rounded to binary floats.
+ * Addition, subtraction, multiplication, modulo, natural powers and factorials are exact,
+ * division, roots and other powers are rounded to the scale of the context.
+ *
+ * @param root Pointer to the AST node being evaluated
+ * @param ctx scale and rounding of inexact results
+ * @return Value the result, its Decimal holds the exact digits and Number the nearest float
+ * @return error if the expression uses anything else than numbers and arithmetic operators,
+ * a calculation fails or the scale is out of range
+ */
+func InterpretDecimal(root *TreeNode, ctx mathfunc.DecimalContext) (Value, error) {
+	if ctx.Scale < 0 || ctx.Scale > mathfunc.MaxDecimalScale {
+		return Value{}, fmt.Errorf("scale has to be from 0 to %d", mathfunc.MaxDecimalScale)
+	}
+	res, err := InterpretBackend[mathfunc.Decimal](root, DecimalBackend{ctx})
+	if err != nil {
+		return Value{}, err
+	}
+	return Value{Number: res.Float64(), Decimal: &res}, nil
+}
+
+/**
+ * DecimalBackend: exact decimals of the decimal mode
+ */
+type DecimalBackend struct {
+	Context mathfunc.DecimalContext // scale and rounding of inexact results
+}
+
+/**
+ * Name: name of the mode used in error messages
+ *
+ * @return string decimal
+ */
+func (DecimalBackend) Name() string {
+	return "decimal"
+}
+
+/**
+ * Supports: whether the backend implements the operator
+ *
+ * @param op name of the operator
+ * @return bool true for arithmetic operators and comparisons
+ */
+func (DecimalBackend) Supports(op string) bool {
+	return arithmeticOperators[op]
+}
+
+/**
+ * Literal: returns the exact decimal written in a number literal
+ *
+ * Numbers without a literal, which are created outside of the parser, are converted from their float value.
+ *
+ * @param literal string value of the number token
+ * @param x the literal parsed as a float
+ * @return mathfunc.Decimal the decimal
+ * @return error if the number isn't finite
+ */
+func (DecimalBackend) Literal(literal string, x float64) (mathfunc.Decimal, error) {
+	if literal == "" {
+		literal = strconv.FormatFloat(x, 'g', -1, 64)
+	}
+	return mathfunc.ParseDecimal(literal)
+}
+
+/**
+ * Identifier: decimals have no named numbers, constants aren't exact
+ *
+ * @param name the identifier
+ * @return mathfunc.Decimal zero value
+ * @return bool always false
+ */
+func (DecimalBackend) Identifier(name string) (mathfunc.Decimal, bool) {
+	return mathfunc.Decimal{}, false
+}
+
+/**
+ * Unary: applies abs, fac or dfac
+ *
+ * @param op name of the operator
+ * @param a operand
+ * @return mathfunc.Decimal result of the operator
+ * @return error if a factorial isn't of a natural number
+ */
+func (DecimalBackend) Unary(op string, a mathfunc.Decimal) (mathfunc.Decimal, error) {
+	switch op {
+	case "abs":
+		return mathfunc.DecimalAbsoluteValue(a), nil
+	case "fac":
+		return mathfunc.DecimalFactorial(a)
+	}
+	return mathfunc.DecimalDoubleFactorial(a)
+}
+
+/**
+ * Binary: applies an operator with two operands
+ *
+ * @param op name of the operator
+ * @param a left operand
+ * @param b right operand
+ * @return mathfunc.Decimal result of the operator
+ * @return error if the operation fails
+ */
+func (d DecimalBackend) Binary(op string, a, b mathfunc.Decimal) (mathfunc.Decimal, error) {
+	switch op {
+	case "+":
+		return mathfunc.DecimalAdd(a, b), nil
+	case "-":
+		return mathfunc.DecimalSubtract(a, b), nil
+	case "*":
+		return mathfunc.DecimalMultiply(a, b), nil
+	case "/":
+		return mathfunc.DecimalDivide(a, b, d.Context)
+	case "mod":
+		return mathfunc.DecimalModulo(a, b)
+	case "pow":
+		return mathfunc.DecimalPower(a, b, d.Context)
+	case "root":
+		return mathfunc.DecimalRoot(a, b, d.Context)
+	case "<":
+		return decimalBool(a.Cmp(b) < 0), nil
+	}
+	return decimalBool(a.Cmp(b) > 0), nil
+}
+
+/**
+ * decimalBool: converts result of a comparison to a decimal
+ *
+ * @param b result of the comparison
+ * @return mathfunc.Decimal 1 if b is true, 0 otherwise
+ */
+func decimalBool(b bool) mathfunc.Decimal {
+	if b {
+		return mathfunc.Decimal{Unscaled: big.NewInt(1)}
+	}
+	return mathfunc.Decimal{Unscaled: new(big.Int)}
+}
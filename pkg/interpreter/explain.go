@@ -0,0 +1,155 @@
+package interpreter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// escapes labels of nodes in Graphviz DOT
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+/**
+ * IntermediateValues: evaluates every subtree of the expression on its own, e.g. to show how the result was reached
+ *
+ * Subtrees, which can't be evaluated on their own, are left out, e.g. bodies of sums using their index variable.
+ * Arguments of calls are evaluated one by one, not as the chain of commas they're stored in.
+ *
+ * @param root root of the tree
+ * @param evaluate calculates the value of a subtree, e.g. Session.Evaluate
+ * @return map[*TreeNode]Value values of the subtrees by their roots
+ */
+func IntermediateValues(root *TreeNode, evaluate func(*TreeNode) (Value, error)) map[*TreeNode]Value {
+	values := make(map[*TreeNode]Value)
+	Inspect(root, func(n *TreeNode) bool {
+		if n == nil {
+			return false
+		}
+		if value, err := evaluate(n); err == nil {
+			values[n] = value
+		}
+		return true
+	})
+	return values
+}
+
+/**
+ * nodeLabel: describes the node by its number, name or operator symbol, e.g. 2.5, pi, ^, -x or max()
+ *
+ * @param n the node
+ * @return string the label
+ */
+func nodeLabel(n *TreeNode) string {
+	op := n.token.stringValue
+	switch n.Kind() {
+	case NumberNode:
+		text, _ := printNumber(n)
+		return text
+	case IdentifierNode:
+		return op
+	case CallNode:
+		return op + "()"
+	case UnaryNode:
+		if sign := unarySign(n); sign != "" {
+			return sign + "x"
+		}
+		if op == "abs" {
+			return "|x|"
+		}
+	}
+	return rootSymbol(n)
+}
+
+/**
+ * annotatedLabel: describes the node and appends its annotation, e.g. + = 7
+ *
+ * @param n the node
+ * @param annotate returns the annotation of a node, e.g. its value, can be nil
+ * @return string the label
+ */
+func annotatedLabel(n *TreeNode, annotate func(*TreeNode) string) string {
+	label := nodeLabel(n)
+	if annotate != nil {
+		if annotation := annotate(n); annotation != "" {
+			label += " = " + annotation
+		}
+	}
+	return label
+}
+
+/**
+ * ASCIITree: draws the tree indented by the depth of the nodes, children are listed in their order, e.g.
+ *
+ *	+ = 7
+ *	|-- 1
+ *	`-- * = 6
+ *	    |-- 2
+ *	    `-- 3
+ *
+ * @param annotate returns the annotation of a node, e.g. its value, empty if it has none, can be nil
+ * @return string the tree, one node on a line
+ */
+func (n *TreeNode) ASCIITree(annotate func(*TreeNode) string) string {
+	var sb strings.Builder
+	var draw func(node *TreeNode, prefix, childPrefix string)
+	draw = func(node *TreeNode, prefix, childPrefix string) {
+		sb.WriteString(prefix + annotatedLabel(node, annotate) + "\n")
+		children := node.Children()
+		for i, child := range children {
+			if child == nil {
+				continue
+			}
+			if i == len(children)-1 {
+				draw(child, childPrefix+"`-- ", childPrefix+"    ")
+			} else {
+				draw(child, childPrefix+"|-- ", childPrefix+"|   ")
+			}
+		}
+	}
+	if n != nil {
+		draw(n, "", "")
+	}
+	return sb.String()
+}
+
+/**
+ * DOT: writes the tree as a graph in the Graphviz DOT language, e.g. for dot -Tpng
+ *
+ * Nodes are labelled by their operators and annotations, edges lead from operators to their operands in their order.
+ *
+ * @param annotate returns the annotation of a node, e.g. its value, empty if it has none, can be nil
+ * @return string the graph
+ */
+func (n *TreeNode) DOT(annotate func(*TreeNode) string) string {
+	var sb strings.Builder
+	sb.WriteString("digraph expression {\n\tgraph [ordering=out];\n\tnode [shape=box];\n")
+	ids := make(map[*TreeNode]int)
+	Inspect(n, func(node *TreeNode) bool {
+		if node == nil {
+			return false
+		}
+		id := len(ids)
+		ids[node] = id
+		label := nodeLabel(node)
+		if annotate != nil {
+			if annotation := annotate(node); annotation != "" {
+				label += "\n= " + annotation
+			}
+		}
+		sb.WriteString(fmt.Sprintf("\tn%d [label=\"%s\"];\n", id, dotEscaper.Replace(label)))
+		return true
+	})
+	// edges are written after all nodes got their ids
+	Inspect(n, func(node *TreeNode) bool {
+		if node == nil {
+			return false
+		}
+		for _, child := range node.Children() {
+			if child != nil {
+				sb.WriteString(fmt.Sprintf("\tn%d -> n%d;\n", ids[node], ids[child]))
+			}
+		}
+		return true
+	})
+	sb.WriteString("}\n")
+	return sb.String()
+}
@@ -0,0 +1,147 @@
+package interpreter
+
+import (
+	"fmt"
+	"ivs-calculator/pkg/mathfunc"
+)
+
+/**
+ * annuityArgs: splits arguments of annuity functions, which take three numbers, optionally the future value
+ * and the type of payments, 0 for payments at the end of periods and 1 for the beginning
+ *
+ * @param numbers the arguments
+ * @return [3]float64 the three required arguments
+ * @return float64 the future value, 0 if not given
+ * @return bool true if payments are made at the beginning of periods
+ * @return error if there's a wrong number of arguments or the type isn't 0 or 1
+ */
+func annuityArgs(numbers []float64) ([3]float64, float64, bool, error) {
+	var required [3]float64
+	if len(numbers) < 3 || len(numbers) > 5 {
+		return required, 0, false, fmt.Errorf("expected 3 to 5 numbers, got %d", len(numbers))
+	}
+	copy(required[:], numbers)
+	fv := 0.0
+	if len(numbers) > 3 {
+		fv = numbers[3]
+	}
+	due, err := paymentType(numbers, 4)
+	return required, fv, due, err
+}
+
+/**
+ * paymentType: returns the type of payments, which is an optional argument
+ *
+ * @param numbers the arguments
+ * @param i index of the type
+ * @return bool true if payments are made at the beginning of periods
+ * @return error if the type isn't 0 or 1
+ */
+func paymentType(numbers []float64, i int) (bool, error) {
+	if len(numbers) <= i {
+		return false, nil
+	}
+	switch numbers[i] {
+	case 0:
+		return false, nil
+	case 1:
+		return true, nil
+	}
+	return false, fmt.Errorf("type of payments has to be 0 for the end or 1 for the beginning of periods")
+}
+
+/**
+ * annuity: converts an annuity function to a builtin function taking its arguments in the order of spreadsheets
+ *
+ * @param f the annuity function taking three numbers, future value and type of payments
+ * @return func(args []Value) (Value, error) the builtin function
+ */
+func annuity(f func(a, b, c, fv float64, due bool) (float64, error)) func(args []Value) (Value, error) {
+	return statistic(func(numbers []float64) (float64, error) {
+		required, fv, due, err := annuityArgs(numbers)
+		if err != nil {
+			return 0, err
+		}
+		return f(required[0], required[1], required[2], fv, due)
+	})
+}
+
+/**
+ * rateOf: calculates rate(nper, pmt, pv[, fv[, type[, guess]]]), the interest rate per period of an annuity
+ *
+ * @param numbers the arguments
+ * @return float64 the rate
+ * @return error if the arguments are wrong or the rate can't be found
+ */
+func rateOf(numbers []float64) (float64, error) {
+	guess := 0.1
+	if len(numbers) == 6 {
+		guess = numbers[5]
+		numbers = numbers[:5]
+	}
+	required, fv, due, err := annuityArgs(numbers)
+	if err != nil {
+		return 0, err
+	}
+	return mathfunc.Rate(required[0], required[1], required[2], fv, due, guess)
+}
+
+/**
+ * npvOf: calculates npv(rate, values), the net present value of the cash flows, which can be a list or numbers
+ *
+ * @param args the rate and the cash flows
+ * @return Value the net present value
+ * @return error if the arguments are wrong
+ */
+func npvOf(args []Value) (Value, error) {
+	rate, err := numbersOf(args[:1])
+	if err != nil {
+		return Value{}, err
+	}
+	values, err := numbersOf(args[1:])
+	if err != nil {
+		return Value{}, err
+	}
+	res, err := mathfunc.Npv(rate[0], values)
+	if err != nil {
+		return Value{}, err
+	}
+	return NumberValue(res), nil
+}
+
+/**
+ * irrOf: calculates irr(values), the internal rate of return of the cash flows, which can be a list or numbers
+ *
+ * @param numbers the cash flows
+ * @return float64 the internal rate of return
+ * @return error if the rate can't be found
+ */
+func irrOf(numbers []float64) (float64, error) {
+	return mathfunc.Irr(numbers, 0.1)
+}
+
+/**
+ * amortizationTable: calculates amortize(principal, rate, n), the schedule of paying off a loan
+ *
+ * @param args the principal, rate per period and number of periods
+ * @return Value the schedule as a table
+ * @return error if the arguments are wrong
+ */
+func amortizationTable(args []Value) (Value, error) {
+	numbers, err := numbersOf(args)
+	if err != nil {
+		return Value{}, err
+	}
+	if len(numbers) != 3 {
+		return Value{}, fmt.Errorf("expected 3 numbers, got %d", len(numbers))
+	}
+	schedule, err := mathfunc.Amortize(numbers[0], numbers[1], numbers[2])
+	if err != nil {
+		return Value{}, err
+	}
+	table := &Table{Columns: []string{"period", "payment", "interest", "principal", "balance"}}
+	for _, row := range schedule {
+		table.Rows = append(table.Rows, []float64{row.Period, row.Payment, row.Interest, row.Principal, row.Balance})
+	}
+	return Value{Table: table}, nil
+}
@@ -0,0 +1,457 @@
+package interpreter
+
+import (
+	"fmt"
+	"ivs-calculator/pkg/format"
+	"ivs-calculator/pkg/mathfunc"
+)
+
+/**
+ * builtin: function which can be called from an expression by its name
+ */
+type builtin struct {
+	minArgs int
+	maxArgs int // -1 for unlimited number of arguments
+	call    func(args []Value) (Value, error)
+}
+
+// functions callable from expressions, lists in braces are calls of the list function
+var builtins = map[string]builtin{
+	"list":       {0, -1, makeList},
+	"len":        {1, 1, listLength},
+	"sort":       {1, -1, sortList},
+	"mean":       {1, -1, statistic(mathfunc.Mean)},
+	"median":     {1, -1, statistic(mathfunc.Median)},
+	"mode":       {1, -1, statistic(mathfunc.Mode)},
+	"stdev":      {1, -1, statistic(mathfunc.StandardDeviation)},
+	"var":        {1, -1, statistic(mathfunc.Variance)},
+	"min":        {1, -1, statistic(mathfunc.Min)},
+	"max":        {1, -1, statistic(mathfunc.Max)},
+	"root":       {1, 2, rootOf},
+	"gamma":      {1, 1, elementwise(mathfunc.Gamma)},
+	"lgamma":     {1, 1, elementwise(mathfunc.Lgamma)},
+	"beta":       {2, 2, binary(mathfunc.Beta)},
+	"nCr":        {2, 2, binary(mathfunc.Combinations)},
+	"nPr":        {2, 2, binary(mathfunc.Permutations)},
+	"gcd":        {1, -1, statistic(mathfunc.GCD)},
+	"lcm":        {1, -1, statistic(mathfunc.LCM)},
+	"isprime":    {1, 1, elementwise(isPrimeOf)},
+	"factor":     {1, 1, factorList},
+	"nextprime":  {1, 1, elementwise(mathfunc.NextPrime)},
+	"totient":    {1, 1, elementwise(mathfunc.Totient)},
+	"powmod":     {3, 3, ternary(mathfunc.PowMod)},
+	"modinv":     {2, 2, binary(mathfunc.ModInverse)},
+	"normpdf":    {1, 3, normal(mathfunc.NormPdf)},
+	"normcdf":    {1, 3, normal(mathfunc.NormCdf)},
+	"norminv":    {1, 3, normal(mathfunc.NormInv)},
+	"binompdf":   {3, 3, ternary(mathfunc.BinomPdf)},
+	"binomcdf":   {3, 3, ternary(mathfunc.BinomCdf)},
+	"poissonpdf": {2, 2, binary(mathfunc.PoissonPdf)},
+	"tcdf":       {2, 2, binary(mathfunc.StudentTCdf)},
+	"tinv":       {2, 2, binary(mathfunc.StudentTInv)},
+	"chisqcdf":   {2, 2, binary(mathfunc.ChiSquareCdf)},
+	"pmt":        {3, 5, annuity(mathfunc.Pmt)},
+	"fv":         {3, 5, annuity(mathfunc.Fv)},
+	"pv":         {3, 5, annuity(mathfunc.Pv)},
+	"nper":       {3, 5, annuity(mathfunc.Nper)},
+	"rate":       {3, 6, statistic(rateOf)},
+	"npv":        {2, -1, npvOf},
+	"irr":        {1, -1, statistic(irrOf)},
+	"compound":   {3, 3, ternary(mathfunc.CompoundInterest)},
+	"effect":     {2, 2, binary(mathfunc.EffectiveRate)},
+	"nominal":    {2, 2, binary(mathfunc.NominalRate)},
+	"amortize":   {3, 3, amortizationTable},
+	"inspect":    {1, 1, inspectNumber},
+}
+
+/**
+ * evalCall: evaluates arguments of a function call and calls the function
+ *
+ * @param node Pointer to the function call node
+ * @param f called function
+ * @param sc Pointer to the innermost scope of variables, can be nil
+ * @return Value result of the function
+ * @return error if the number of arguments is wrong, an argument can't be evaluated or the function fails
+ */
+func evalCall(node *TreeNode, f builtin, sc *scope) (Value, error) {
+	name := node.token.stringValue
+	argNodes := callArgs(node)
+	if len(argNodes) < f.minArgs || f.maxArgs >= 0 && len(argNodes) > f.maxArgs {
+		if f.minArgs == f.maxArgs {
+			return Value{}, fmt.Errorf("%s takes %d arguments, got %d", name, f.minArgs, len(argNodes))
+		}
+		return Value{}, fmt.Errorf("%s takes at least %d arguments, got %d", name, f.minArgs, len(argNodes))
+	}
+
+	args := make([]Value, len(argNodes))
+	for i, argNode := range argNodes {
+		var err error
+		args[i], err = interpret(argNode, sc)
+		if err != nil {
+			return Value{}, err
+		}
+		if err = checkOperand(args[i]); err != nil {
+			return Value{}, err
+		}
+	}
+	return f.call(args)
+}
+
+/**
+ * interpretNumber: calculates the result of an expression, which has to be a number
+ *
+ * @param root Pointer to the AST node being evaluated
+ * @param sc Pointer to the innermost scope of variables, can be nil
+ * @return float64 result of the expression
+ * @return error if the expression can't be evaluated or its result is a list
+ */
+func interpretNumber(root *TreeNode, sc *scope) (float64, error) {
+	res, err := interpret(root, sc)
+	if err != nil {
+		return 0, err
+	}
+	if res.IsList {
+		return 0, fmt.Errorf("expected a number, got a list")
+	}
+	if err = checkOperand(res); err != nil {
+		return 0, err
+	}
+	if res.Uncertainty != 0 {
+		return 0, fmt.Errorf("expected an exact number, got an uncertain one")
+	}
+	return res.Number, nil
+}
+
+/**
+ * numbersOf: collects numbers from arguments of a function
+ *
+ * Function can be called either with a single list or with the numbers as its arguments.
+ *
+ * @param args arguments of the function
+ * @return []float64 the numbers
+ * @return error if lists are mixed with other arguments
+ */
+func numbersOf(args []Value) ([]float64, error) {
+	if len(args) == 1 && args[0].IsList {
+		return args[0].List, nil
+	}
+	numbers := make([]float64, len(args))
+	for i, arg := range args {
+		if arg.IsList {
+			return nil, fmt.Errorf("expected a number, got a list")
+		}
+		if arg.Uncertainty != 0 {
+			return nil, fmt.Errorf("expected an exact number, got an uncertain one")
+		}
+		numbers[i] = arg.Number
+	}
+	return numbers, nil
+}
+
+/**
+ * statistic: converts function of a slice of numbers to a builtin function
+ *
+ * @param f function calculating the statistic
+ * @return func(args []Value) (Value, error) the builtin function
+ */
+func statistic(f func([]float64) (float64, error)) func(args []Value) (Value, error) {
+	return func(args []Value) (Value, error) {
+		numbers, err := numbersOf(args)
+		if err != nil {
+			return Value{}, err
+		}
+		res, err := f(numbers)
+		if err != nil {
+			return Value{}, err
+		}
+		return NumberValue(res), nil
+	}
+}
+
+/**
+ * elementwise: converts function of a number to a builtin function, which is applied on a number or on each element of a list
+ *
+ * @param f the function
+ * @return func(args []Value) (Value, error) the builtin function
+ */
+func elementwise(f func(float64) (float64, error)) func(args []Value) (Value, error) {
+	return func(args []Value) (Value, error) {
+		arg := args[0]
+		if arg.Uncertainty != 0 {
+			return Value{}, fmt.Errorf("expected an exact number, got an uncertain one")
+		}
+		if !arg.IsList {
+			res, err := f(arg.Number)
+			if err != nil {
+				return Value{}, err
+			}
+			return NumberValue(res), nil
+		}
+		res := make([]float64, len(arg.List))
+		for i, x := range arg.List {
+			var err error
+			if res[i], err = f(x); err != nil {
+				return Value{}, err
+			}
+		}
+		return ListValue(res), nil
+	}
+}
+
+/**
+ * binary: converts function of two numbers to a builtin function
+ *
+ * @param f the function
+ * @return func(args []Value) (Value, error) the builtin function
+ */
+func binary(f func(float64, float64) (float64, error)) func(args []Value) (Value, error) {
+	return func(args []Value) (Value, error) {
+		numbers, err := numbersOf(args)
+		if err != nil {
+			return Value{}, err
+		}
+		if len(numbers) != 2 {
+			return Value{}, fmt.Errorf("expected 2 numbers, got %d", len(numbers))
+		}
+		res, err := f(numbers[0], numbers[1])
+		if err != nil {
+			return Value{}, err
+		}
+		return NumberValue(res), nil
+	}
+}
+
+/**
+ * isPrimeOf: checks whether a number is a prime
+ *
+ * @param x the number
+ * @return float64 1 if the number is a prime, 0 otherwise
+ * @return error if the number isn't an integer or is too big
+ */
+func isPrimeOf(x float64) (float64, error) {
+	prime, err := mathfunc.IsPrime(x)
+	if err != nil || !prime {
+		return 0, err
+	}
+	return 1, nil
+}
+
+/**
+ * factorList: returns prime factors of a number as a list
+ *
+ * @param args the number
+ * @return Value list of the prime factors in ascending order
+ * @return error if the argument isn't a positive integer
+ */
+func factorList(args []Value) (Value, error) {
+	numbers, err := numbersOf(args)
+	if err != nil {
+		return Value{}, err
+	}
+	if len(numbers) != 1 {
+		return Value{}, fmt.Errorf("factor takes a number, got a list")
+	}
+	factors, err := mathfunc.Factor(numbers[0])
+	if err != nil {
+		return Value{}, err
+	}
+	return ListValue(factors), nil
+}
+
+/**
+ * ternary: converts function of three numbers to a builtin function
+ *
+ * @param f the function
+ * @return func(args []Value) (Value, error) the builtin function
+ */
+func ternary(f func(float64, float64, float64) (float64, error)) func(args []Value) (Value, error) {
+	return func(args []Value) (Value, error) {
+		numbers, err := numbersOf(args)
+		if err != nil {
+			return Value{}, err
+		}
+		if len(numbers) != 3 {
+			return Value{}, fmt.Errorf("expected 3 numbers, got %d", len(numbers))
+		}
+		res, err := f(numbers[0], numbers[1], numbers[2])
+		if err != nil {
+			return Value{}, err
+		}
+		return NumberValue(res), nil
+	}
+}
+
+/**
+ * normal: converts function of the normal distribution to a builtin function,
+ * which takes either only its first argument for the standard normal distribution or also the mean and standard deviation
+ *
+ * @param f function of the normal distribution
+ * @return func(args []Value) (Value, error) the builtin function
+ */
+func normal(f func(float64, float64, float64) (float64, error)) func(args []Value) (Value, error) {
+	return func(args []Value) (Value, error) {
+		switch len(args) {
+		case 1:
+			return ternary(f)([]Value{args[0], NumberValue(0), NumberValue(1)})
+		case 3:
+			return ternary(f)(args)
+		}
+		return Value{}, fmt.Errorf("expected 1 or 3 numbers, got %d", len(args))
+	}
+}
+
+/**
+ * rootOf: calculates root(x, n), which is the same as n√x, root(x) is the square root of x
+ *
+ * @param args radicand and optionally the degree of the root
+ * @return Value the root
+ * @return error if the root can't be calculated
+ */
+func rootOf(args []Value) (Value, error) {
+	degree := NumberValue(2)
+	if len(args) == 2 {
+		degree = args[1]
+	}
+	return applyBinary("root", args[0], degree)
+}
+
+/**
+ * rootOperands: returns radicand and degree of a root written either as n√x or as root(x, n)
+ *
+ * @param node Pointer to the root node
+ * @return *TreeNode radicand
+ * @return *TreeNode degree, implicitly 2 for root(x)
+ * @return error if the function has a wrong number of arguments
+ */
+func rootOperands(node *TreeNode) (*TreeNode, *TreeNode, error) {
+	if node.rightNode != nil {
+		return node.leftNode, node.rightNode, nil
+	}
+	args := callArgs(node)
+	switch len(args) {
+	case 1:
+		return args[0], NewNode(NewToken(NUMBER, "2", 2)), nil
+	case 2:
+		return args[0], args[1], nil
+	}
+	return nil, nil, fmt.Errorf("root takes at least 1 arguments, got %d", len(args))
+}
+
+/**
+ * makeList: creates a list of its arguments
+ *
+ * @param args elements of the list
+ * @return Value the list
+ * @return error if any of the elements is a list
+ */
+func makeList(args []Value) (Value, error) {
+	list := make([]float64, len(args))
+	for i, arg := range args {
+		if arg.IsList {
+			return Value{}, fmt.Errorf("lists can't be nested")
+		}
+		if arg.Uncertainty != 0 {
+			return Value{}, fmt.Errorf("lists can't have an uncertainty")
+		}
+		list[i] = arg.Number
+	}
+	return ListValue(list), nil
+}
+
+/**
+ * listLength: returns number of elements of a list
+ *
+ * @param args the list
+ * @return Value number of elements
+ * @return error if the argument isn't a list
+ */
+func listLength(args []Value) (Value, error) {
+	if !args[0].IsList {
+		return Value{}, fmt.Errorf("len takes a list")
+	}
+	return NumberValue(float64(len(args[0].List))), nil
+}
+
+/**
+ * sortList: sorts numbers in ascending order
+ *
+ * @param args a list or the numbers to be sorted
+ * @return Value sorted list
+ * @return error if lists are mixed with other arguments
+ */
+func sortList(args []Value) (Value, error) {
+	numbers, err := numbersOf(args)
+	if err != nil {
+		return Value{}, err
+	}
+	return ListValue(mathfunc.Sort(numbers)), nil
+}
+
+/**
+ * evalHigherOrder: evaluates map(x -> body, list) and filter(x -> condition, list)
+ *
+ * Map evaluates the body for each element of the list bound to the variable x.
+ * Filter keeps only the elements for which the condition isn't zero.
+ *
+ * @param node Pointer to the map or filter node
+ * @param sc Pointer to the innermost scope of variables, can be nil
+ * @return Value resulting list
+ * @return error if the arguments are invalid or the body can't be evaluated for any element
+ */
+func evalHigherOrder(node *TreeNode, sc *scope) (Value, error) {
+	name := node.token.stringValue
+	args := callArgs(node)
+	if len(args) != 2 {
+		return Value{}, fmt.Errorf("%s takes 2 arguments: lambda and list", name)
+	}
+
+	lambda := args[0]
+	if lambda.token.tokenType != OPERATOR || lambda.token.stringValue != "->" {
+		return Value{}, fmt.Errorf("first argument of %s has to be a lambda, e.g. x -> x^2", name)
+	}
+	param := lambda.leftNode
+	if param.token.tokenType != OPERATOR || param.leftNode != nil || param.rightNode != nil || !isIdentifier(param.token.stringValue) {
+		return Value{}, fmt.Errorf("parameter of a lambda has to be a variable name")
+	}
+
+	list, err := interpret(args[1], sc)
+	if err != nil {
+		return Value{}, err
+	}
+	if !list.IsList {
+		return Value{}, fmt.Errorf("second argument of %s has to be a list", name)
+	}
+
+	res := make([]float64, 0, len(list.List))
+	for _, x := range list.List {
+		y, err := interpretNumber(lambda.rightNode, &scope{name: param.token.stringValue, value: x, parent: sc})
+		if err != nil {
+			return Value{}, err
+		}
+		if name == "map" {
+			res = append(res, y)
+		} else if y != 0 {
+			res = append(res, x)
+		}
+	}
+	return ListValue(res), nil
+}
+
+/**
+ * inspectNumber: shows how a number is stored in the IEEE 754 single and double precision formats
+ *
+ * @param args the number
+ * @return Value the inspection
+ * @return error if the argument isn't an exact number
+ */
+func inspectNumber(args []Value) (Value, error) {
+	numbers, err := numbersOf(args)
+	if err != nil {
+		return Value{}, err
+	}
+	if len(numbers) != 1 {
+		return Value{}, fmt.Errorf("expected 1 number, got %d", len(numbers))
+	}
+	inspection := format.Inspect(numbers[0])
+	return Value{Number: numbers[0], Inspection: &inspection}, nil
+}
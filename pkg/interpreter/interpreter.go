@@ -2,9 +2,12 @@ package interpreter
 
 import (
 	"fmt"
+	"ivs-calculator/pkg/format"
 	"ivs-calculator/pkg/mathfunc"
 	"strconv"
+	"strings"
 	"unicode"
+	"unicode/utf8"
 )
 
 // order of operations by their precedence in expression
@@ -12,21 +15,137 @@ var operOrder = map[string]struct {
 	prec   int
 	rAssoc bool
 }{
-	"-": {2, false},
-	"+": {2, false},
-	"*": {3, false},
-	"/": {3, false},
-	"%": {3, false},
-	"√": {4, true},
-	"!": {4, true},
-	"^": {4, true},
-	"m": {5, true},
-	"p": {5, true},
+	",":  {1, false},
+	"->": {2, true},
+	"<":  {3, false},
+	">":  {3, false},
+	"-":  {4, false},
+	"+":  {4, false},
+	"*":  {5, false},
+	"/":  {5, false},
+	"%":  {5, false},
+	"±":  {6, false},
+	"√":  {7, true},
+	"!!": {7, true},
+	"!":  {7, true},
+	"^":  {7, true},
+	"m":  {8, true},
+	"p":  {8, true},
+}
+
+// names which can't be used as identifiers, since they are used as operators in postfix notation or in the tree,
+// root can be called only as the function sharing the implementation of √
+var reservedNames = map[string]bool{
+	"m":    true,
+	"p":    true,
+	"abs":  true,
+	"fac":  true,
+	"dfac": true,
+	"pow":  true,
+	"root": true,
+	"mod":  true,
+}
+
+/**
+ * scope: binding of a variable to its value, e.g. the index of a sum
+ *
+ * Scopes are chained, so inner bindings shadow the outer ones.
+ */
+type scope struct {
+	name   string
+	value  float64
+	parent *scope
+	random *randomState           // set only in the outermost scope of a calculation
+	record func(*TreeNode, Value) // set only in the outermost scope of an explained calculation
+}
+
+/**
+ * lookup: finds the value bound to the given name
+ *
+ * @param name name of the variable
+ * @return float64 value of the variable
+ * @return bool false if no such variable is bound
+ */
+func (sc *scope) lookup(name string) (float64, bool) {
+	for ; sc != nil; sc = sc.parent {
+		if sc.name == name {
+			return sc.value, true
+		}
+	}
+	return 0, false
+}
+
+/**
+ * isIdentRune: checks whether rune can be a part of an identifier
+ *
+ * @param r checked rune
+ * @return bool true for letters and underscore
+ */
+func isIdentRune(r rune) bool {
+	return unicode.IsLetter(r) || r == '_'
+}
+
+/**
+ * isIdentifier: checks whether token is a name of a variable or a function
+ *
+ * @param token checked token
+ * @return bool true if the token consists only of identifier runes
+ */
+func isIdentifier(token string) bool {
+	if token == "" {
+		return false
+	}
+	for _, r := range token {
+		if !isIdentRune(r) {
+			return false
+		}
+	}
+	return true
+}
+
+/**
+ * startsWithGroup: checks whether text starts with a group of digits following a grouping mark
+ *
+ * @param text the text after the grouping mark
+ * @return bool true if the text starts with exactly three digits
+ */
+func startsWithGroup(text string) bool {
+	digits := 0
+	for _, r := range text {
+		if !unicode.IsDigit(r) {
+			break
+		}
+		digits++
+	}
+	return digits == 3
+}
+
+/**
+ * isFuncOpen: checks whether token is a start of a function call, e.g. "sum("
+ *
+ * @param token checked token
+ * @return bool true if the token is an identifier followed by an opening bracket
+ */
+func isFuncOpen(token string) bool {
+	return strings.HasSuffix(token, "(") && isIdentifier(strings.TrimSuffix(token, "("))
 }
 
 /**
  * Parse: parses inputted math expression from infix notation into a binary expression tree
  *
+ * The expression is written in the en_US locale, see ParseLocale.
+ *
+ * @param input infix expression to get parsed
+ * @return *TreeNode root of a binary expression tree
+ * @return []int slice with positions of syntax errors, if such've been found
+ */
+func Parse(input string) (*TreeNode, []int) {
+	return ParseLocale(input, format.EnUS)
+}
+
+/**
+ * ParseLocale: parses inputted math expression written in the locale from infix notation into a binary expression tree
+ *
  * Calls toSlice() on string expression to produce a valid slice of expression
  * If expression contained wrong syntax then a slice with positions of those mistakes is returned with a nil root
  * After it calls intoPost() to convert infix slice into a postfix one, since it's easier to convert into a tree
@@ -34,53 +153,182 @@ var operOrder = map[string]struct {
  *
  *
  * @param input infix expression to get parsed
+ * @param locale decimal mark, grouping mark and argument separator used in the expression
  * @return *TreeNode root of a binary expression tree
  * @return []int slice with positions of syntax errors, if such've been found
  */
-func Parse(input string) (*TreeNode, []int) {
-	expSlice, wrongSynt := toSlice(input)
+func ParseLocale(input string, locale format.Locale) (*TreeNode, []int) {
+	expSlice, spans, wrongSynt := toSlice(input, locale)
 	if len(wrongSynt) != 0 {
 		return nil, wrongSynt
 	}
-	post := inToPost(expSlice)
-	root := postToTree(post)
-	return root, nil
+	post, postSpans := inToPost(expSlice, spans)
+	return postToTree(post, postSpans)
 }
 
 /**
- * evalOperator: evaluates operator node
+ * standardBackend: values of the standard mode, their numbers are 64-bit floats calculated the same as by FloatBackend
  *
- * Calls Interpret() on left and right children of the node, then based
- * on the node's token.stringValue calls the correct function.
+ * Besides arithmetic it evaluates variables, function calls, series, lambdas, lists and uncertain numbers.
+ */
+type standardBackend struct {
+	sc *scope // innermost scope of variables, can be nil
+}
+
+/**
+ * Name: name of the mode used in error messages
  *
- * @param node Pointer to the node being evaluated
- * @return float64 resulting from the called operator function
- * @return error if called on an unknown operator,
- * or when an error occurs when interpreting child nodes
- * or when calling the operator function
+ * @return string standard
+ */
+func (standardBackend) Name() string {
+	return "standard"
+}
+
+/**
+ * Supports: whether the backend implements the operator
+ *
+ * @param op name of the operator
+ * @return bool always true, unknown operators fail when they are applied
+ */
+func (standardBackend) Supports(op string) bool {
+	return true
+}
+
+/**
+ * Literal: returns the number literal parsed as a float
+ *
+ * @param literal string value of the number token
+ * @param x the literal parsed as a float
+ * @return Value the number
+ * @return error never
  */
-func evalOperator(node *TreeNode) (float64, error) {
-	left, err1 := Interpret(node.leftNode)
-	if err1 != nil {
-		return 0, err1
+func (standardBackend) Literal(literal string, x float64) (Value, error) {
+	return NumberValue(x), nil
+}
+
+/**
+ * Identifier: returns the value of a variable bound in the scope or the exact value of a physical constant
+ *
+ * @param name name of the variable or symbol of the constant
+ * @return Value value bound to the identifier
+ * @return bool false if there's no such variable or constant
+ */
+func (b standardBackend) Identifier(name string) (Value, bool) {
+	if value, ok := b.sc.lookup(name); ok {
+		return NumberValue(value), true
+	}
+	if constant, ok := lookupConstant(name); ok {
+		return NumberValue(constant.Value), true
 	}
+	return Value{}, false
+}
 
-	stringValue := node.token.stringValue
+/**
+ * Unary: applies abs, fac or dfac
+ *
+ * @param op name of the operator
+ * @param x operand
+ * @return Value result of the operator
+ * @return error if the operator fails
+ */
+func (standardBackend) Unary(op string, x Value) (Value, error) {
+	return applyUnary(op, x)
+}
 
-	// handle one operand operators
-	if stringValue == "abs" {
-		return mathfunc.AbsoluteValue(left), nil
-	} else if stringValue == "fac" {
-		return mathfunc.Factorial(left)
+/**
+ * Binary: applies an operator with two operands
+ *
+ * @param op name of the operator
+ * @param a left operand
+ * @param b right operand
+ * @return Value result of the operator
+ * @return error if called on an unknown operator or the operator fails
+ */
+func (standardBackend) Binary(op string, a, b Value) (Value, error) {
+	return applyBinary(op, a, b)
+}
+
+/**
+ * recordNode: records the value of a subtree evaluated in the outermost scope of an explained calculation,
+ * bodies of sums and lambdas are left out, they are evaluated for many values of their variable
+ *
+ * @param node Pointer to the root of the subtree
+ * @param x value of the subtree
+ */
+func (b standardBackend) recordNode(node *TreeNode, x Value) {
+	if b.sc != nil && b.sc.record != nil {
+		b.sc.record(node, x)
 	}
+}
+
+/**
+ * EvaluateNode: evaluates identifiers, which aren't variables or constants, and operators binding a variable
+ * to their body or calling a function, the other operators are left to InterpretBackend
+ *
+ * An identifier, which isn't a variable or a constant, is a call of a function without arguments.
+ *
+ * @param node Pointer to the operator node
+ * @return Value result of the node
+ * @return bool false if the node is left to InterpretBackend
+ * @return error if the identifier is unknown or the call fails
+ */
+func (b standardBackend) EvaluateNode(node *TreeNode) (Value, bool, error) {
+	stringValue := node.token.stringValue
 
-	right, err2 := Interpret(node.rightNode)
-	if err2 != nil {
-		return 0, err2
+	if node.leftNode == nil && node.rightNode == nil && isIdentifier(stringValue) {
+		if value, ok := b.Identifier(stringValue); ok {
+			return value, true, nil
+		}
+		if f, ok := lookupBuiltin(stringValue, b.sc); ok {
+			res, err := evalCall(node, f, b.sc)
+			return res, true, err
+		}
+		return Value{}, true, fmt.Errorf("unknown identifier: '%v'", stringValue)
 	}
 
-	// handle two operand operators
+	// handle operators binding a variable to their body
 	switch stringValue {
+	case "sum", "prod":
+		res, err := evalSeries(node, b.sc)
+		return res, true, err
+	case "map", "filter":
+		res, err := evalHigherOrder(node, b.sc)
+		return res, true, err
+	case "->":
+		return Value{}, true, fmt.Errorf("lambda can only be an argument of map or filter")
+	case "uncertain":
+		res, err := evalUncertainConstant(node)
+		return res, true, err
+	}
+
+	// handle function calls
+	if f, ok := lookupBuiltin(stringValue, b.sc); ok && node.rightNode == nil {
+		res, err := evalCall(node, f, b.sc)
+		return res, true, err
+	}
+	if node.Kind() == CallNode {
+		return Value{}, true, fmt.Errorf("unknown function: '%v'", stringValue)
+	}
+	return Value{}, false, nil
+}
+
+/**
+ * applyOperator: applies operator on numbers by calling the correct function
+ *
+ * @param op name of the operator
+ * @param left left operand, or the only operand of one operand operators
+ * @param right right operand, ignored by one operand operators
+ * @return float64 resulting from the called operator function
+ * @return error if called on an unknown operator or when calling the operator function
+ */
+func applyOperator(op string, left, right float64) (float64, error) {
+	switch op {
+	case "abs":
+		return mathfunc.AbsoluteValue(left), nil
+	case "fac":
+		return mathfunc.Factorial(left)
+	case "dfac":
+		return mathfunc.DoubleFactorial(left)
 	case "+":
 		return mathfunc.Add(left, right), nil
 	case "*":
@@ -95,11 +343,28 @@ func evalOperator(node *TreeNode) (float64, error) {
 		return mathfunc.Power(left, right)
 	case "root":
 		return mathfunc.Root(left, right)
+	case "<":
+		return boolToFloat(left < right), nil
+	case ">":
+		return boolToFloat(left > right), nil
 	default:
-		return 0, fmt.Errorf("invalid operator: '%v'", node.token.stringValue)
+		return 0, fmt.Errorf("invalid operator: '%v'", op)
 	}
 }
 
+/**
+ * boolToFloat: converts result of a comparison to a number
+ *
+ * @param b result of the comparison
+ * @return float64 1 if b is true, 0 otherwise
+ */
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
 /**
  * evalNumber: evaluates number node by returning it's stored float64 value
  *
@@ -113,103 +378,208 @@ func evalNumber(node *TreeNode) float64 {
 /**
  * Interpret: calculates the result as float64 of the expression represented by the parametr root
  *
+ * The expression is evaluated by InterpretBackend with the standard backend within the default session.
+ *
  * @param root Pointer to the AST node being evaluated
  * @return float64 result of the whole expression
- * @return error if there was an error when evaluating the AST - see evalOperator for details
+ * @return error if there was an error when evaluating the AST - see standardBackend for details,
+ * or if the result is a list
  */
 func Interpret(root *TreeNode) (float64, error) {
-	if root == nil { // interpreting an empty tree or node desn't make sense
-		return 0, fmt.Errorf("cannot interpret an empty node")
+	res, err := defaultSession.Evaluate(root)
+	if err != nil {
+		return 0, err
 	}
-
-	if root.token.tokenType == OPERATOR {
-		return evalOperator(root)
-	} else if root.token.tokenType == NUMBER {
-		return evalNumber(root), nil
-	} else {
-		return 0, fmt.Errorf("invalid token type: %d", root.token.tokenType)
+	if res.IsList {
+		return 0, fmt.Errorf("result is a list, not a number")
 	}
+	if err = checkOperand(res); err != nil {
+		return 0, err
+	}
+	return res.Number, nil
+}
+
+/**
+ * Evaluate: calculates the result of the expression represented by the parametr root
+ *
+ * Unlike Interpret the result can be also a list. Random numbers are generated within a default session.
+ *
+ * @param root Pointer to the AST node being evaluated
+ * @return Value result of the whole expression
+ * @return error if there was an error when evaluating the AST - see standardBackend for details
+ */
+func Evaluate(root *TreeNode) (Value, error) {
+	return defaultSession.Evaluate(root)
+}
+
+/**
+ * interpret: calculates the result of the expression represented by the parametr root within a scope
+ *
+ * @param root Pointer to the AST node being evaluated
+ * @param sc Pointer to the innermost scope of variables, can be nil
+ * @return Value result of the whole expression
+ * @return error if there was an error when evaluating the AST - see standardBackend for details
+ */
+func interpret(root *TreeNode, sc *scope) (Value, error) {
+	return InterpretBackend[Value](root, standardBackend{sc})
 }
 
 /**
  * toSlice: converts math expression from string to slice
  *
+ * Numbers are written with the decimal mark of the locale and optionally grouped by its grouping mark,
+ * arguments of functions and elements of lists are separated by its argument separator.
+ * Numbers in the slice use a decimal point and arguments are separated by commas in every locale.
+ *
  * @param in inputted math expression in form of a string
+ * @param locale marks used in the expression
  * @return []string slice consisted of inputted math expression
+ * @return []Span positions of the elements of the slice in the expression
  * @return []int slice of mistakes in mathematical notation. Consider as an error return, if length of it is > 0
  */
-func toSlice(in string) ([]string, []int) {
+func toSlice(in string, locale format.Locale) ([]string, []Span, []int) {
 	//
 	if in == "" {
-		return nil, []int{0}
+		return nil, nil, []int{0}
 	}
 	outSlice := make([]string, 0)
+	spans := make([]Span, 0)
+	push := func(token string, span Span) {
+		outSlice = append(outSlice, token)
+		spans = append(spans, span)
+	}
 	wrongSynt := make([]int, 0)
 	brackPos := make([]int, 0)
+	brackOpen := make([]string, 0) // tokens opening the brackets in brackPos: "(", "{" or a function call
 	absPos := make([]int, 0)
 	openedAbs := false
 	openedBr := false
 	consNum := false
+	consIdent := false
 	isFloat := false
+	grouped := false // number contains grouping marks
 	wantPow := false
 	closedBr := false
+	closedIdent := false
 	number := ""
+	numStart, numEnd := 0, 0
+	ident := ""
+	skipTo := 0
 	for i, tokenRune := range in {
+		if i < skipTo {
+			continue
+		}
 		token := string(tokenRune)
-		if token == "(" || token == ")" || token == "+" || token == "-" || token == "*" || token == "/" || token == "!" || token == "^" || token == "√" || token == "|" || token == "%" {
-			if i == 0 && (token == ")" || token == "*" || token == "/" || token == "!" || token == "^" || token == "%") {
+		end := i + len(token)
+		// alternate syntax of the plus-minus sign
+		if strings.HasPrefix(in[i:], "+/-") {
+			token = "±"
+			skipTo = i + len("+/-")
+			end = skipTo
+		}
+		// append an identifier to slice if it's construction is over
+		if consIdent && !isIdentRune(tokenRune) {
+			consIdent = false
+			if reservedNames[ident] && !(token == "(" && ident == "root") {
+				wrongSynt = append(wrongSynt, i-len(ident))
+			}
+			// identifier directly followed by a bracket is a function call
+			if token == "(" {
+				push(ident+"(", Span{i - len(ident), end})
+				brackPos = append(brackPos, i)
+				brackOpen = append(brackOpen, ident+"(")
+				ident = ""
+				continue
+			}
+			push(ident, Span{i - len(ident), i})
+			ident = ""
+			closedIdent = true
+		}
+		if token == "(" || token == ")" || token == "{" || token == "}" || token == "+" || token == "-" || token == "*" || token == "/" || token == "!" || token == "^" || token == "√" || token == "|" || token == "%" || token == "<" || token == ">" || token == "±" {
+			if i == 0 && (token == ")" || token == "}" || token == "*" || token == "/" || token == "!" || token == "^" || token == "%" || token == "<" || token == ">" || token == "±") {
 				wrongSynt = append(wrongSynt, i)
 			}
+			afterIdent := closedIdent
+			closedIdent = false
 			// append a number to slice if it's construction is over
 			if consNum {
 				consNum = false
-				outSlice = append(outSlice, number)
+				isFloat = false
+				grouped = false
+				push(number, Span{numStart, numEnd})
 				number = ""
 			}
-			//
+			// exponent has to start with an operand, a bracket or a sign
 			if wantPow {
 				wantPow = false
-				wrongSynt = append(wrongSynt, i)
-				continue
+				if token == ")" || token == "}" || token == "*" || token == "/" || token == "!" || token == "^" || token == "%" || token == "<" || token == ">" || token == "±" {
+					wrongSynt = append(wrongSynt, i)
+					continue
+				}
 			}
 			if token == "√" {
 				var prev string
 				if len(outSlice) > 0 {
 					prev = outSlice[len(outSlice)-1]
-					_, err := strconv.Atoi(prev)
-					if err != nil && prev != ")" {
-						outSlice = append(outSlice, "2")
+					_, err := strconv.ParseFloat(prev, 64)
+					if err != nil && prev != ")" && !isIdentifier(prev) {
+						push("2", Span{i, end})
 					}
 				} else {
-					outSlice = append(outSlice, "2")
+					push("2", Span{i, end})
 				}
 			}
 			if token == "^" {
+				if len(outSlice) == 0 {
+					wrongSynt = append(wrongSynt, i)
+					continue
+				}
+				// base can be a number, a variable, an expression in brackets or an absolute value
 				prev := outSlice[len(outSlice)-1]
-				_, err := strconv.Atoi(prev)
-				if err != nil {
+				_, err := strconv.ParseFloat(prev, 64)
+				if err != nil && !isIdentifier(prev) && prev != ")" && (prev != "|" || openedAbs) {
 					wrongSynt = append(wrongSynt, i)
 					continue
 				}
 				wantPow = true
 			}
-			if token == "-" && i > 0 {
+			if token == "-" && len(outSlice) > 0 {
 				if outSlice[len(outSlice)-1] == "-" {
 					outSlice[len(outSlice)-1] = "+"
+					spans[len(spans)-1].End = end
+					continue
 				} else if outSlice[len(outSlice)-1] == "+" {
 					outSlice[len(outSlice)-1] = "-"
+					spans[len(spans)-1].End = end
+					continue
 				}
 			}
-			if token == "+" && i > 0 {
+			if token == "+" && len(outSlice) > 0 {
 				if outSlice[len(outSlice)-1] == "+" {
 					outSlice[len(outSlice)-1] = "+"
+					spans[len(spans)-1].End = end
+					continue
 				} else if outSlice[len(outSlice)-1] == "-" {
 					outSlice[len(outSlice)-1] = "-"
+					spans[len(spans)-1].End = end
+					continue
 				}
 			}
-			if token == "*" || token == "/" || token == "!" || token == "%" {
+			// minus followed by greater than is an arrow of a lambda, e.g. x -> x^2
+			if token == ">" && len(outSlice) > 1 && outSlice[len(outSlice)-1] == "-" {
+				outSlice[len(outSlice)-1] = "->"
+				spans[len(spans)-1].End = end
+				continue
+			}
+			// two exclamation marks are a double factorial
+			if token == "!" && len(outSlice) > 0 && outSlice[len(outSlice)-1] == "!" {
+				outSlice[len(outSlice)-1] = "!!"
+				spans[len(spans)-1].End = end
+				continue
+			}
+			if (token == "*" || token == "/" || token == "!" || token == "%" || token == "<" || token == ">" || token == "±") && len(outSlice) > 0 {
 				prev := outSlice[len(outSlice)-1]
-				if prev == "*" || prev == "/" || prev == "!" || prev == "%" || prev == "+" || prev == "-" {
+				if prev == "*" || prev == "/" || prev == "%" || prev == "+" || prev == "-" || prev == "<" || prev == ">" || prev == "±" || prev == "->" || prev == "," || isFuncOpen(prev) {
 					wrongSynt = append(wrongSynt, i)
 					continue
 				}
@@ -224,32 +594,85 @@ func toSlice(in string) ([]string, []int) {
 				}
 			}
 
-			if token == "(" {
-				if closedBr {
+			if token == "(" || token == "{" {
+				if closedBr || afterIdent {
 					wrongSynt = append(wrongSynt, i)
 					continue
 				}
 				openedBr = true
 				brackPos = append(brackPos, i)
+				brackOpen = append(brackOpen, token)
+				// list in braces is a call of the list function
+				if token == "{" {
+					closedBr = false
+					openedBr = false
+					push("list(", Span{i, end})
+					continue
+				}
 			}
-			if token == ")" {
-				if len(brackPos) == 0 {
+			if token == ")" || token == "}" {
+				if len(brackPos) == 0 || (token == "}") != (brackOpen[len(brackOpen)-1] == "{") {
 					wrongSynt = append(wrongSynt, i)
 					continue
 				}
 				if openedBr {
 					brackPos = brackPos[:len(brackPos)-1]
+					brackOpen = brackOpen[:len(brackOpen)-1]
 				}
 				closedBr = true
 				brackPos = brackPos[:len(brackPos)-1]
-				outSlice = append(outSlice, token)
+				brackOpen = brackOpen[:len(brackOpen)-1]
+				// separators and operators have to be followed by an operand, e.g. max(1, ) or (x ->) miss it
+				if prev := outSlice[len(outSlice)-1]; prev == "," || prev == "->" || strings.Contains("+-*/%^√<>±", prev) {
+					wrongSynt = append(wrongSynt, spans[len(spans)-1].Start)
+					continue
+				}
+				// function without arguments is called only by its name
+				if prev := outSlice[len(outSlice)-1]; isFuncOpen(prev) {
+					outSlice[len(outSlice)-1] = strings.TrimSuffix(prev, "(")
+					spans[len(spans)-1].End = end
+					continue
+				}
+				push(")", Span{i, end})
 				continue
 			}
 			closedBr = false
 			openedBr = false
-			outSlice = append(outSlice, token)
-		} else if unicode.IsDigit(tokenRune) || (consNum && (token == "," || token == ".")) {
-			if closedBr {
+			push(token, Span{i, end})
+		} else if tokenRune == locale.ArgumentSeparator && len(brackOpen) > 0 && brackOpen[len(brackOpen)-1] != "(" {
+			// argument separator directly inside of a function call or a list separates its arguments
+			closedIdent = false
+			if consNum {
+				consNum = false
+				isFloat = false
+				grouped = false
+				push(number, Span{numStart, numEnd})
+				number = ""
+			}
+			prev := outSlice[len(outSlice)-1]
+			if wantPow || prev == "," || prev == "->" || isFuncOpen(prev) || strings.Contains("(+-*/%^√<>±", prev) {
+				wantPow = false
+				wrongSynt = append(wrongSynt, i)
+				continue
+			}
+			closedBr = false
+			push(",", Span{i, end})
+		} else if isIdentRune(tokenRune) {
+			if consIdent {
+				ident += token
+				continue
+			}
+			if consNum || closedBr || closedIdent {
+				wrongSynt = append(wrongSynt, i)
+				continue
+			}
+			if wantPow {
+				wantPow = false
+			}
+			ident = token
+			consIdent = true
+		} else if unicode.IsDigit(tokenRune) || (consNum && (tokenRune == locale.DecimalMark || tokenRune == locale.GroupingMark)) {
+			if closedBr || closedIdent {
 				closedBr = false
 				wrongSynt = append(wrongSynt, i)
 				continue
@@ -257,9 +680,19 @@ func toSlice(in string) ([]string, []int) {
 			if wantPow {
 				wantPow = false
 			}
-			if token == "," || token == "." {
+			if tokenRune == locale.GroupingMark {
+				// only the first group of digits can be shorter than three digits
+				if isFloat || (!grouped && len(number) > 3) || !startsWithGroup(in[i+len(token):]) {
+					wrongSynt = append(wrongSynt, i)
+					continue
+				}
+				grouped = true
+				continue
+			}
+			if tokenRune == locale.DecimalMark {
 				if !isFloat {
 					number += "."
+					numEnd = end
 					isFloat = true
 					continue
 				}
@@ -267,8 +700,33 @@ func toSlice(in string) ([]string, []int) {
 				continue
 			}
 
+			if !consNum {
+				numStart = i
+			}
 			number += token
+			numEnd = end
 			consNum = true
+		} else if token == "#" && consNum && !isFloat && !grouped {
+			// number in another base, the number before # is the base, e.g. 16#FF
+			end := i + len(token)
+			for end < len(in) {
+				r, size := utf8.DecodeRuneInString(in[end:])
+				if !isRadixDigit(r) && r != locale.DecimalMark {
+					break
+				}
+				end += size
+			}
+			value, err := parseRadix(number, in[i+len(token):end], locale.DecimalMark)
+			if err != nil {
+				wrongSynt = append(wrongSynt, i)
+				continue
+			}
+			push(strconv.FormatFloat(value, 'g', -1, 64), Span{numStart, end})
+			consNum = false
+			number = ""
+			// nothing but an operator can follow the number, the same as after an identifier
+			closedIdent = true
+			skipTo = end
 		} else if unicode.IsSpace(tokenRune) {
 			continue
 		} else {
@@ -290,58 +748,97 @@ func toSlice(in string) ([]string, []int) {
 	}
 
 	if consNum {
-		outSlice = append(outSlice, number)
+		push(number, Span{numStart, numEnd})
+	}
+	if consIdent {
+		if reservedNames[ident] {
+			wrongSynt = append(wrongSynt, len(in)-len(ident))
+		}
+		push(ident, Span{len(in) - len(ident), len(in)})
 	}
 
-	return outSlice, wrongSynt
+	return outSlice, spans, wrongSynt
 }
 
 /**
  * inToPost: converts infix expression into postfix
  *
+ * Spans of the postfix tokens are their positions in the expression, functions and absolute values
+ * span from their opening to their closing brackets, the same as expressions in brackets.
+ *
  * @param input expression in infix notation
+ * @param spans positions of the infix tokens, can be nil when they're unknown
  * @return []string slice of expression in postfix notation
+ * @return []Span positions of the postfix tokens
  */
-func inToPost(input []string) []string {
+func inToPost(input []string, spans []Span) ([]string, []Span) {
 	post := make([]string, 0)
+	postSpans := make([]Span, 0)
 	stack := make([]string, 0)
+	stackSpans := make([]Span, 0)
+	// pops the operator on top of the stack and returns it with its span
+	pop := func() (string, Span) {
+		operator, span := stack[len(stack)-1], stackSpans[len(stackSpans)-1]
+		stack = stack[:len(stack)-1]
+		stackSpans = stackSpans[:len(stackSpans)-1]
+		return operator, span
+	}
+	output := func(token string, span Span) {
+		post = append(post, token)
+		postSpans = append(postSpans, span)
+	}
 	openedAbs := false
 	afterOpPar := false
 	lastDig := false
 	for i, token := range input {
+		var span Span
+		if spans != nil {
+			span = spans[i]
+		}
 		switch token {
 		case "(":
 			lastDig = false
 			stack = append(stack, token)
+			stackSpans = append(stackSpans, span)
 		case ")":
-			lastDig = false
+			lastDig = true
 			for {
-				operator := stack[len(stack)-1]
-				stack = stack[:len(stack)-1]
+				operator, opSpan := pop()
+				// the last postfix token is the root of the bracketed expression, it spans the brackets
 				if operator == "(" {
+					if len(postSpans) > 0 {
+						postSpans[len(postSpans)-1] = postSpans[len(postSpans)-1].join(opSpan.join(span))
+					}
 					break
 				}
-				post = append(post, operator)
+				// function is applied on the contents of its brackets
+				if isFuncOpen(operator) {
+					output(operator, opSpan.join(span))
+					break
+				}
+				output(operator, opSpan)
 				afterOpPar = true
 			}
 		case "|":
-			lastDig = false
 			if openedAbs {
+				lastDig = true
+				openedAbs = false
 				for {
-					operator := stack[len(stack)-1]
-					stack = stack[:len(stack)-1]
+					operator, opSpan := pop()
 					if operator == "|" {
+						output("abs", opSpan.join(span))
 						break
 					}
-					post = append(post, operator)
+					output(operator, opSpan)
 					afterOpPar = true
-					post = append(post, "abs")
 				}
 			} else {
+				lastDig = false
 				openedAbs = true
 				stack = append(stack, token)
+				stackSpans = append(stackSpans, span)
 			}
-		case "+", "-", "/", "*", "%", "^", "!", "√":
+		case "+", "-", "/", "*", "%", "^", "!", "!!", "√", ",", "<", ">", "->", "±":
 			curOp := token
 			if i == 0 && curOp == "-" { // checking if current operator is unary minus in the beginning of an expression
 				curOp = "m"
@@ -363,9 +860,7 @@ func inToPost(input []string) []string {
 
 				for {
 					if !operOrder[curOp].rAssoc && operOrder[curOp].prec <= operOrder[lastOp].prec || operOrder[curOp].rAssoc && operOrder[curOp].prec < operOrder[lastOp].prec {
-						operator := stack[len(stack)-1]
-						stack = stack[:len(stack)-1]
-						post = append(post, operator)
+						output(pop())
 
 						if len(stack) == 0 {
 							break
@@ -379,21 +874,27 @@ func inToPost(input []string) []string {
 			}
 
 			stack = append(stack, curOp)
-			if token != "!" {
+			stackSpans = append(stackSpans, span)
+			if token != "!" && token != "!!" {
 				lastDig = false
 			}
 			afterOpPar = false
 		default:
+			if isFuncOpen(token) {
+				lastDig = false
+				stack = append(stack, token)
+				stackSpans = append(stackSpans, span)
+				continue
+			}
 			lastDig = true
-			post = append(post, token)
+			output(token, span)
 		}
 	}
 
 	for len(stack) > 0 {
-		post = append(post, stack[len(stack)-1])
-		stack = stack[:len(stack)-1]
+		output(pop())
 	}
-	return post
+	return post, postSpans
 }
 
 /**
@@ -401,24 +902,41 @@ func inToPost(input []string) []string {
  *
  * @param stack slice of nodes
  * @param token operator from postfix expression
+ * @param span position of the operator in the expression, the node spans its operands too
  * @return []*TreeNode returns updated stack of nodes with assigned operands to operator
+ * @return error if the stack doesn't contain all operands of the operator, e.g. of + in 1+
  */
-func toTreeOper(stack []*TreeNode, token string) []*TreeNode {
+func toTreeOper(stack []*TreeNode, token string, span Span) ([]*TreeNode, error) {
 	var (
 		t *Token
 		l *TreeNode
 		r *TreeNode
 	)
+	operands := 1
+	switch token {
+	case "+", "-", "/", "*", "^", "%", ",", "<", ">", "->", "±", "√":
+		operands = 2
+	}
+	if len(stack) < operands {
+		return stack, fmt.Errorf("missing operand of '%v'", token)
+	}
 	switch token {
-	case "+", "-", "/", "*", "^", "%":
+	case "+", "-", "/", "*", "^", "%", ",", "<", ">", "->", "±":
 		l = stack[len(stack)-2]
 		r = stack[len(stack)-1]
-	case "!", "abs":
+	case "!", "!!", "abs":
 		l = stack[len(stack)-1]
 		r = nil
 	case "√":
 		l = stack[len(stack)-1]
 		r = stack[len(stack)-2]
+	default:
+		// function call, arguments are stored in the left subtree
+		if isFuncOpen(token) {
+			l = stack[len(stack)-1]
+			r = nil
+			token = strings.TrimSuffix(token, "(")
+		}
 	}
 
 	if token == "%" {
@@ -429,63 +947,105 @@ func toTreeOper(stack []*TreeNode, token string) []*TreeNode {
 		t = NewToken(OPERATOR, "root", 0.0)
 	} else if token == "!" {
 		t = NewToken(OPERATOR, "fac", 0.0)
+	} else if token == "!!" {
+		t = NewToken(OPERATOR, "dfac", 0.0)
 	} else if token == "abs" {
 		t = NewToken(OPERATOR, token, 0.0)
 	} else if token == "m" {
 		unT := NewToken(NUMBER, "-1", -1.0)
 		unN := NewNode(unT)
+		unN.span = span
 		l := stack[len(stack)-1]
 		t := NewToken(OPERATOR, "*", 0.0)
 		n := NewParent(t, l, unN)
+		n.span = span.join(l.span)
 		stack[len(stack)-1] = n
 
-		return stack
+		return stack, nil
 	} else if token == "p" {
-		unT := NewToken(NUMBER, "1", 1.0)
+		unT := NewToken(NUMBER, "+1", 1.0)
 		unN := NewNode(unT)
+		unN.span = span
 		l := stack[len(stack)-1]
 		t := NewToken(OPERATOR, "*", 0.0)
 		n := NewParent(t, l, unN)
+		n.span = span.join(l.span)
 		stack[len(stack)-1] = n
 
-		return stack
+		return stack, nil
 	} else {
 		t = NewToken(OPERATOR, token, 0.0)
 	}
 	n := NewParent(t, l, r)
+	n.span = span
+	if l != nil {
+		n.span = n.span.join(l.span)
+	}
+	if r != nil {
+		n.span = n.span.join(r.span)
+	}
 	if r == nil {
 		stack[len(stack)-1] = n
 
-		return stack
+		return stack, nil
 	}
 
 	stack = stack[:len(stack)-1]
 	stack[len(stack)-1] = n
 
-	return stack
+	return stack, nil
 }
 
 /**
  * postToTree: converts postfix expression to a binary expression tree
  *
  * @param post slice of a postfix expression to be converted into a tree
+ * @param spans positions of the postfix tokens, can be nil when they're unknown
  * @return *TreeNode root of a tree
+ * @return []int position of the operator missing an operand, if there's one
  */
-func postToTree(post []string) *TreeNode {
+func postToTree(post []string, spans []Span) (*TreeNode, []int) {
 	stack := make([]*TreeNode, 0)
 
-	for _, token := range post {
+	for i, token := range post {
+		var span Span
+		if spans != nil {
+			span = spans[i]
+		}
 		switch token {
-		case "+", "-", "/", "*", "^", "!", "%", "√", "abs", "m", "p":
-			stack = toTreeOper(stack, token)
+		case "+", "-", "/", "*", "^", "!", "!!", "%", "√", "abs", "m", "p", ",", "<", ">", "->", "±":
+			var err error
+			if stack, err = toTreeOper(stack, token, span); err != nil {
+				return nil, []int{span.Start}
+			}
 		default:
+			if isFuncOpen(token) {
+				var err error
+				if stack, err = toTreeOper(stack, token, span); err != nil {
+					return nil, []int{span.Start}
+				}
+				continue
+			}
+			// identifiers are stored as operators without operands
+			if isIdentifier(token) {
+				t := NewToken(OPERATOR, token, 0.0)
+				n := NewNode(t)
+				n.span = span
+				stack = append(stack, n)
+				continue
+			}
 			fl, _ := strconv.ParseFloat(token, 64)
 
 			t := NewToken(NUMBER, token, fl)
 			n := NewNode(t)
+			n.span = span
 			stack = append(stack, n)
 		}
 	}
 
-	return stack[0]
+	// nothing to evaluate, e.g. in empty brackets
+	if len(stack) == 0 {
+		return nil, []int{0}
+	}
+	return stack[0], nil
 }
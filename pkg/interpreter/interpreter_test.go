@@ -1,10 +1,15 @@
 package interpreter
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"ivs-calculator/pkg/format"
 	"ivs-calculator/pkg/mathfunc"
+	"math"
+	"math/big"
 	"reflect"
+	"strconv"
 	"strings"
 	"testing"
 )
@@ -16,10 +21,10 @@ func TestInterpretEmpty(t *testing.T) {
 	var nilNode *TreeNode = nil
 	InterpretErrorTestCase(t, nilNode, errors.New("cannot interpret an empty node"))
 
-	var emptyNode = &TreeNode{Token{OPERATOR, "", 0}, nil, nil}
+	var emptyNode = &TreeNode{token: Token{OPERATOR, "", 0}}
 	InterpretErrorTestCase(t, emptyNode, errors.New("cannot interpret an empty node"))
 
-	var emptyChildren = &TreeNode{Token{OPERATOR, "+", 0}, nil, nil}
+	var emptyChildren = &TreeNode{token: Token{OPERATOR, "+", 0}}
 	InterpretErrorTestCase(t, emptyChildren, errors.New("cannot interpret an empty node"))
 }
 
@@ -40,60 +45,60 @@ func TestInterpretOperators(t *testing.T) {
 	res, _ = mathfunc.Root(5.0, 4.0)
 	InterpretResultTestCase(t, getTreeForOperator("root"), res)
 
-	var tree = &TreeNode{Token{OPERATOR, "+", 0},
-		&TreeNode{Token{OPERATOR, "*", 0},
-			&TreeNode{Token{NUMBER, "", 3}, nil, nil},
-			&TreeNode{Token{NUMBER, "", 2}, nil, nil}},
-		&TreeNode{Token{NUMBER, "", 4.0}, nil, nil}}
+	var tree = &TreeNode{token: Token{OPERATOR, "+", 0},
+		leftNode: &TreeNode{token: Token{OPERATOR, "*", 0},
+			leftNode:  &TreeNode{token: Token{NUMBER, "", 3}},
+			rightNode: &TreeNode{token: Token{NUMBER, "", 2}}},
+		rightNode: &TreeNode{token: Token{NUMBER, "", 4.0}}}
 	InterpretResultTestCase(t, tree, 10.0)
 
-	tree = &TreeNode{Token{OPERATOR, "fac", 0},
-		&TreeNode{Token{NUMBER, "", 5}, nil, nil}, nil}
+	tree = &TreeNode{token: Token{OPERATOR, "fac", 0},
+		leftNode: &TreeNode{token: Token{NUMBER, "", 5}}}
 	InterpretResultTestCase(t, tree, 120)
 
-	tree = &TreeNode{Token{OPERATOR, "fac", 0},
-		&TreeNode{Token{OPERATOR, "abs", 0},
-			&TreeNode{Token{NUMBER, "", -5}, nil, nil}, nil}, nil}
+	tree = &TreeNode{token: Token{OPERATOR, "fac", 0},
+		leftNode: &TreeNode{token: Token{OPERATOR, "abs", 0},
+			leftNode: &TreeNode{token: Token{NUMBER, "", -5}}}}
 	InterpretResultTestCase(t, tree, 120)
 }
 
 func getTreeForOperator(op string) *TreeNode {
-	var tree = &TreeNode{Token{OPERATOR, op, 0},
-		&TreeNode{Token{NUMBER, "", 5.0}, nil, nil},
-		&TreeNode{Token{NUMBER, "", 4.0}, nil, nil}}
+	var tree = &TreeNode{token: Token{OPERATOR, op, 0},
+		leftNode:  &TreeNode{token: Token{NUMBER, "", 5.0}},
+		rightNode: &TreeNode{token: Token{NUMBER, "", 4.0}}}
 	return tree
 }
 
 // Test constants and operator validity
 func TestInterpretInvalidData(t *testing.T) {
-	var tree = &TreeNode{Token{2, "+", 0},
-		&TreeNode{Token{NUMBER, "", 5.0}, nil, nil},
-		&TreeNode{Token{NUMBER, "", 4.0}, nil, nil}}
+	var tree = &TreeNode{token: Token{2, "+", 0},
+		leftNode:  &TreeNode{token: Token{NUMBER, "", 5.0}},
+		rightNode: &TreeNode{token: Token{NUMBER, "", 4.0}}}
 	InterpretErrorTestCase(t, tree, errors.New("invalid token type: 2"))
 
-	tree = &TreeNode{Token{OPERATOR, "(", 0},
-		&TreeNode{Token{NUMBER, "", 5.0}, nil, nil},
-		&TreeNode{Token{NUMBER, "", 4.0}, nil, nil}}
+	tree = &TreeNode{token: Token{OPERATOR, "(", 0},
+		leftNode:  &TreeNode{token: Token{NUMBER, "", 5.0}},
+		rightNode: &TreeNode{token: Token{NUMBER, "", 4.0}}}
 	InterpretErrorTestCase(t, tree, errors.New("invalid operator: '('"))
 }
 
 func TestInterpretNumber(t *testing.T) {
-	var tree = &TreeNode{Token{NUMBER, "", 8.123}, nil, nil}
+	var tree = &TreeNode{token: Token{NUMBER, "", 8.123}}
 	InterpretResultTestCase(t, tree, 8.123)
 }
 
 // Test propagation of errors that arise in mathfunc
 func TestInterpretOpError(t *testing.T) {
-	var tree = &TreeNode{Token{OPERATOR, "+", 0},
-		&TreeNode{Token{NUMBER, "", 4.0}, nil, nil},
-		&TreeNode{Token{OPERATOR, "/", 0},
-			&TreeNode{Token{NUMBER, "", 10.0}, nil, nil},
-			&TreeNode{Token{NUMBER, "", 0.0}, nil, nil}}}
+	var tree = &TreeNode{token: Token{OPERATOR, "+", 0},
+		leftNode: &TreeNode{token: Token{NUMBER, "", 4.0}},
+		rightNode: &TreeNode{token: Token{OPERATOR, "/", 0},
+			leftNode:  &TreeNode{token: Token{NUMBER, "", 10.0}},
+			rightNode: &TreeNode{token: Token{NUMBER, "", 0.0}}}}
 	InterpretErrorTestCase(t, tree, errors.New("cannot divide by zero"))
 
-	tree = &TreeNode{Token{OPERATOR, "fac", 0},
-		&TreeNode{Token{NUMBER, "", -1}, nil, nil}, nil}
-	InterpretErrorTestCase(t, tree, errors.New("cannot calculate factorial of negative numbers"))
+	tree = &TreeNode{token: Token{OPERATOR, "fac", 0},
+		leftNode: &TreeNode{token: Token{NUMBER, "", -1}}}
+	InterpretErrorTestCase(t, tree, errors.New("cannot calculate factorial of negative integers"))
 
 }
 
@@ -117,11 +122,1164 @@ func InterpretResultTestCase(t *testing.T, tree *TreeNode, expectedOutput float6
 	}
 }
 
+func TestInterpretSeries(t *testing.T) {
+	ExpressionTestCase(t, "sum(k, 1, 100, k^2)", 338350, nil)
+	ExpressionTestCase(t, "prod(k, 1, 5, k)", 120, nil)
+	ExpressionTestCase(t, "2*sum(k, 1, 3, k)-1", 11, nil)
+	ExpressionTestCase(t, "sum(k, 1, 3, sum(j, 1, k, j))", 10, nil)
+	ExpressionTestCase(t, "sum(k, -2, 2, k*k)", 10, nil)
+	ExpressionTestCase(t, "sum(k, 1, 3, |k-2|)", 2, nil)
+
+	// empty ranges
+	ExpressionTestCase(t, "sum(k, 1, 0, k)", 0, nil)
+	ExpressionTestCase(t, "prod(k, 1, 0, k)", 1, nil)
+
+	ExpressionTestCase(t, "sum(k, 0.5, 3, k)", 0, errors.New("bounds of sum have to be integers"))
+	ExpressionTestCase(t, "sum(k, 10^17, 10^17+10, 1)", 0, errors.New("bounds of sum have to be from -2^53 to 2^53"))
+	ExpressionTestCase(t, "sum(k, 2^53-2, 2^53, k-2^53)", -3, nil)
+	ExpressionTestCase(t, "prod(k, 1, 10000000, k)", 0, errors.New("prod can't have more than 1000000 terms"))
+	ExpressionTestCase(t, "sum(1, 1, 3, 5)", 0, errors.New("index of sum has to be a variable name"))
+	ExpressionTestCase(t, "sum(k, 1, 3)", 0, errors.New("sum takes 4 arguments: index, lower bound, upper bound and body"))
+	ExpressionTestCase(t, "prod(k, 1, 200, k)", 0, errors.New("result of prod is too big"))
+
+	// index is visible only inside of the body
+	ExpressionTestCase(t, "sum(k, 1, 3, k)+k", 0, errors.New("unknown identifier: 'k'"))
+
+	// separators have to be followed by an argument
+	for input, pos := range map[string]int{
+		"sum(k,1,3,)":     9,
+		"sum(k, 1, 3, )":  11,
+		"max(1, 2,)+1":    8,
+		"{1,}":            2,
+		"2*sum(k,1,2,k,)": 13,
+	} {
+		if _, wrongSynt := Parse(input); len(wrongSynt) == 0 || wrongSynt[0] != pos {
+			t.Errorf("Parse(%s) wrong syntax at %v should be at %d", input, wrongSynt, pos)
+		}
+	}
+
+	// calls of unknown functions and names of internal operators
+	ExpressionTestCase(t, "ln(2)", 0, errors.New("unknown function: 'ln'"))
+	ExpressionTestCase(t, "sqrt(4)", 0, errors.New("unknown function: 'sqrt'"))
+	ExpressionTestCase(t, "1 + floor(k)", 0, errors.New("unknown function: 'floor'"))
+	ExpressionTestCase(t, "root(8, 3)", 2, nil)
+	for input, pos := range map[string]int{
+		"fac(5)":              0,
+		"2*dfac(5)":           2,
+		"sum(mod, 1, 3, mod)": 4,
+		"map(root -> 1, {1})": 4,
+		"pow":                 0,
+	} {
+		if _, wrongSynt := Parse(input); len(wrongSynt) == 0 || wrongSynt[0] != pos {
+			t.Errorf("Parse(%s) wrong syntax at %v should be at %d", input, wrongSynt, pos)
+		}
+	}
+}
+
+func TestEvaluateLists(t *testing.T) {
+	ListTestCase(t, "{3, 1, 4, 1, 5}", []float64{3, 1, 4, 1, 5}, nil)
+	ListTestCase(t, "{}", []float64{}, nil)
+	ListTestCase(t, "sort({3, 1, 4, 1, 5})", []float64{1, 1, 3, 4, 5}, nil)
+	ListTestCase(t, "map(x -> x^2, {1, 2, 3})", []float64{1, 4, 9}, nil)
+	ListTestCase(t, "filter(x -> x > 2, {1, 2, 3, 4})", []float64{3, 4}, nil)
+	ListTestCase(t, "filter(x -> x % 2, {1, 2, 3, 4})", []float64{1, 3}, nil)
+
+	// element-wise arithmetic
+	ListTestCase(t, "{1, 2, 3}+{4, 5, 6}", []float64{5, 7, 9}, nil)
+	ListTestCase(t, "{1, 2, 3}*2", []float64{2, 4, 6}, nil)
+	ListTestCase(t, "-{1, 2}", []float64{-1, -2}, nil)
+	ListTestCase(t, "|{-1, 2}|", []float64{1, 2}, nil)
+	ListTestCase(t, "sum(k, 1, 3, {k, 1})", []float64{6, 3}, nil)
+
+	ListTestCase(t, "{1, 2}+{1}", nil, errors.New("lists have different lengths: 2 and 1"))
+	ListTestCase(t, "{1, {2}}", nil, errors.New("lists can't be nested"))
+	ListTestCase(t, "map(2, {1})", nil, errors.New("first argument of map has to be a lambda, e.g. x -> x^2"))
+	ListTestCase(t, "map(x -> x, 1)", nil, errors.New("second argument of map has to be a list"))
+	ListTestCase(t, "2*(x -> x)", nil, errors.New("lambda can only be an argument of map or filter"))
+
+	ExpressionTestCase(t, "mean({3, 1, 4, 1, 5})", 2.8, nil)
+	ExpressionTestCase(t, "median({3, 1, 4, 1, 5})", 3, nil)
+	ExpressionTestCase(t, "mode({3, 1, 4, 1, 5})", 1, nil)
+	ExpressionTestCase(t, "var({10, 20})", 50, nil)
+	ExpressionTestCase(t, "var({1000000000, 1000000001, 1000000002})", 1, nil)
+	ExpressionTestCase(t, "stdev({1000000000, 1000000001, 1000000002})", 1, nil)
+	ExpressionTestCase(t, "min(3, 1, 4)", 1, nil)
+	ExpressionTestCase(t, "max({3, 1, 4})", 4, nil)
+	ExpressionTestCase(t, "len({3, 1, 4})", 3, nil)
+	ExpressionTestCase(t, "len({})", 0, nil)
+	ExpressionTestCase(t, "{1, 2}", 0, errors.New("result is a list, not a number"))
+	ExpressionTestCase(t, "mean({})", 0, errors.New("cannot calculate mean of no numbers"))
+	ExpressionTestCase(t, "len(1, 2)", 0, errors.New("len takes 1 arguments, got 2"))
+	ExpressionTestCase(t, "stdev({1}, 2)", 0, errors.New("expected a number, got a list"))
+
+	// lambdas and comparisons need both of their operands
+	for input, pos := range map[string]int{
+		"x->":            1,
+		"x -> ":          2,
+		"1 < ":           2,
+		"1 >":            2,
+		"map({1}, x->)":  10,
+		"filter(x>, {})": 9,
+		"()":             0,
+	} {
+		if _, wrongSynt := Parse(input); len(wrongSynt) == 0 || wrongSynt[0] != pos {
+			t.Errorf("Parse(%s) wrong syntax at %v should be at %d", input, wrongSynt, pos)
+		}
+	}
+}
+
+func ListTestCase(t *testing.T, input string, expectedOutput []float64, expectedError error) {
+	tree, wrongSynt := Parse(input)
+	if len(wrongSynt) != 0 {
+		t.Errorf("Parse(%s) wrong syntax at %v", input, wrongSynt)
+		return
+	}
+	out, err := Evaluate(tree)
+	if expectedError == nil && (!out.IsList || !reflect.DeepEqual(out.List, expectedOutput)) {
+		t.Errorf("Evaluate(%s) out = %v should be %v", input, out, expectedOutput)
+	}
+	if (err == nil && expectedError != nil) || (err != nil && expectedError == nil) || (err != nil && expectedError != nil && err.Error() != expectedError.Error()) {
+		t.Errorf("Evaluate(%s) err = %s should be %s", input, err, expectedError)
+	}
+}
+
+func TestEvaluateUncertainty(t *testing.T) {
+	UncertainTestCase(t, "12.34 ± 0.42", "12.3 ± 0.4", nil)
+	UncertainTestCase(t, "12.3 +/- 0.4", "12.3 ± 0.4", nil)
+	UncertainTestCase(t, "5 ± 0.123", "5.00 ± 0.12", nil)
+	UncertainTestCase(t, "1000 ± 96", "1000 ± 100", nil)
+	UncertainTestCase(t, "-5 ± 0.5", "-5.0 ± 0.5", nil)
+	UncertainTestCase(t, "150000000000000000000 ± 3000000000000000000", "(1.500 ± 0.030)e+20", nil)
+
+	// propagation through operators
+	UncertainTestCase(t, "(2 ± 0.1)*(3 ± 0.2)", "6.0 ± 0.5", nil)
+	UncertainTestCase(t, "2 ± 0.1*3 ± 0.2", "6.0 ± 0.5", nil)
+	UncertainTestCase(t, "(10 ± 0.5)-(10 ± 0.5)", "0.0 ± 0.7", nil)
+	UncertainTestCase(t, "(1 ± 0.1)/(2 ± 0.1)", "0.50 ± 0.06", nil)
+	UncertainTestCase(t, "√(16 ± 0.4)", "4.00 ± 0.05", nil)
+	UncertainTestCase(t, "|-3 ± 0.5|", "3.0 ± 0.5", nil)
+	UncertainTestCase(t, "7%(3 ± 0.1)", "1.00 ± 0.20", nil)
+	UncertainTestCase(t, "(10 ± 1)^2", "100 ± 20", nil)
+	UncertainTestCase(t, "(4 ± 0.2)!", "24 ± 7", nil)
+
+	UncertainTestCase(t, "{1, 2} ± 1", "", errors.New("lists can't have an uncertainty"))
+	UncertainTestCase(t, "(5 ± 1) ± (1 ± 1)", "", errors.New("uncertainty can't be uncertain"))
+	UncertainTestCase(t, "mean(1 ± 1, 2)", "", errors.New("expected an exact number, got an uncertain one"))
+
+	// uncertainty has to follow the sign
+	for input, pos := range map[string]int{
+		"1 ±":        2,
+		"1 +/-":      2,
+		"1±":         1,
+		"(2 ± )*3":   3,
+		"max(1 +/-)": 6,
+	} {
+		if _, wrongSynt := Parse(input); len(wrongSynt) == 0 || wrongSynt[0] != pos {
+			t.Errorf("Parse(%s) wrong syntax at %v should be at %d", input, wrongSynt, pos)
+		}
+	}
+
+	// (10 ± 1)^2
+	var tree = &TreeNode{token: Token{OPERATOR, "pow", 0},
+		leftNode: &TreeNode{token: Token{OPERATOR, "±", 0},
+			leftNode:  &TreeNode{token: Token{NUMBER, "", 10}},
+			rightNode: &TreeNode{token: Token{NUMBER, "", 1}}},
+		rightNode: &TreeNode{token: Token{NUMBER, "", 2}}}
+	UncertainTreeTestCase(t, tree, 100, 20)
+
+	// 2^(3 ± 0.1)
+	tree = &TreeNode{token: Token{OPERATOR, "pow", 0},
+		leftNode: &TreeNode{token: Token{NUMBER, "", 2}},
+		rightNode: &TreeNode{token: Token{OPERATOR, "±", 0},
+			leftNode:  &TreeNode{token: Token{NUMBER, "", 3}},
+			rightNode: &TreeNode{token: Token{NUMBER, "", 0.1}}}}
+	UncertainTreeTestCase(t, tree, 8, 0.8*math.Ln2)
+
+	// (3 ± 0.1)√(8)
+	tree = &TreeNode{token: Token{OPERATOR, "root", 0},
+		leftNode: &TreeNode{token: Token{NUMBER, "", 8}},
+		rightNode: &TreeNode{token: Token{OPERATOR, "±", 0},
+			leftNode:  &TreeNode{token: Token{NUMBER, "", 3}},
+			rightNode: &TreeNode{token: Token{NUMBER, "", 0.1}}}}
+	UncertainTreeTestCase(t, tree, 2, 0.2*math.Log(8)/9)
+}
+
+func TestInterpretSigFigs(t *testing.T) {
+	// precision of literals
+	SigFigTestCase(t, "2.50", "2.50", nil)
+	SigFigTestCase(t, "0.0250", "0.0250", nil)
+	SigFigTestCase(t, "1500", "1.5e+03", nil)
+	SigFigTestCase(t, "1500.", "1.500e+03", nil)
+	SigFigTestCase(t, "-2.50", "-2.50", nil)
+
+	// addition and subtraction keep the least precise decimal place
+	SigFigTestCase(t, "12.11+18.0+1.013", "31.1", nil)
+	SigFigTestCase(t, "1.00-0.999", "0.00", nil)
+	SigFigTestCase(t, "9.96+0.1", "10.1", nil)
+	SigFigTestCase(t, "1500+23.4", "1.5e+03", nil)
+
+	// multiplication and division keep the fewest significant figures
+	SigFigTestCase(t, "2.50*2", "5", nil)
+	SigFigTestCase(t, "2.50*2.0", "5.0", nil)
+	SigFigTestCase(t, "+2.50*4.00", "10.0", nil)
+	SigFigTestCase(t, "4.0/3", "1", nil)
+	SigFigTestCase(t, "3.3*3.0", "9.9", nil)
+	SigFigTestCase(t, "9.96*1.0", "1.0e+01", nil)
+	SigFigTestCase(t, "2.00*(1.1+2.25)", "6.7", nil)
+
+	// powers and roots keep the significant figures of the base
+	SigFigTestCase(t, "12^2", "1.4e+02", nil)
+	SigFigTestCase(t, "√2.0", "1.4", nil)
+	SigFigTestCase(t, "|-3.0|", "3.0", nil)
+
+	SigFigTestCase(t, "2/0", "", fmt.Errorf("cannot divide by zero"))
+	SigFigTestCase(t, "mean({1, 2})", "", fmt.Errorf("'mean' can't be used in significant figures mode"))
+	SigFigTestCase(t, "2 ± 0.1", "", fmt.Errorf("'±' can't be used in significant figures mode"))
+}
+
+func SigFigTestCase(t *testing.T, input string, expectedOutput string, expectedError error) {
+	tree, wrongSynt := Parse(input)
+	if len(wrongSynt) != 0 {
+		t.Errorf("Parse(%s) wrong syntax at %v", input, wrongSynt)
+		return
+	}
+	out, err := InterpretSigFigs(tree)
+	if expectedError == nil && out.String() != expectedOutput {
+		t.Errorf("InterpretSigFigs(%s) out = %s should be %s", input, out, expectedOutput)
+	}
+	if (err == nil && expectedError != nil) || (err != nil && expectedError == nil) || (err != nil && expectedError != nil && err.Error() != expectedError.Error()) {
+		t.Errorf("InterpretSigFigs(%s) err = %s should be %s", input, err, expectedError)
+	}
+}
+
+func TestInterpretInterval(t *testing.T) {
+	IntervalTestCase(t, "2+3", "[5, 5]", nil)
+	IntervalTestCase(t, "0.1", "[0.09999999999999999, 0.10000000000000002]", nil)
+	IntervalTestCase(t, "0.5", "[0.5, 0.5]", nil)
+	IntervalTestCase(t, "10 ± 0.5", "[9.5, 10.5]", nil)
+	IntervalTestCase(t, "(10 ± 0.5)-(5 ± 0.25)", "[4.25, 5.75]", nil)
+	IntervalTestCase(t, "(2 ± 1)*(-3 ± 1)", "[-12, -2]", nil)
+	IntervalTestCase(t, "-(2 ± 1)", "[-3, -1]", nil)
+	IntervalTestCase(t, "1/3", "[0.3333333333333333, 0.3333333333333334]", nil)
+	IntervalTestCase(t, "|-2 ± 3|", "[0, 5]", nil)
+	IntervalTestCase(t, "√(16 ± 7)", "[3, 4.795831523312721]", nil)
+	IntervalTestCase(t, "5!", "[120, 120]", nil)
+	IntervalTestCase(t, "2^0.5", "[1.4142135623730947, 1.4142135623730954]", nil)
+	IntervalTestCase(t, "2.5√8", "[2.2973967099940684, 2.2973967099940715]", nil)
+	IntervalTestCase(t, "(-2)^0.5", "", fmt.Errorf("cannot raise an interval containing negative numbers to a power, which isn't an exact integer"))
+
+	IntervalTestCase(t, "1/(1 ± 2)", "", fmt.Errorf("cannot divide by an interval containing zero"))
+	IntervalTestCase(t, "1/0", "", fmt.Errorf("cannot divide by an interval containing zero"))
+	IntervalTestCase(t, "√(1 ± 2)", "", fmt.Errorf("can't calculate root 2 of an interval containing negative numbers"))
+	IntervalTestCase(t, "max(1, 2)", "", fmt.Errorf("'max' can't be used in interval mode"))
+}
+
+func IntervalTestCase(t *testing.T, input string, expectedOutput string, expectedError error) {
+	tree, wrongSynt := Parse(input)
+	if len(wrongSynt) != 0 {
+		t.Errorf("Parse(%s) wrong syntax at %v", input, wrongSynt)
+		return
+	}
+	out, err := InterpretInterval(tree)
+	if expectedError == nil && out.String() != expectedOutput {
+		t.Errorf("InterpretInterval(%s) out = %s should be %s", input, out, expectedOutput)
+	}
+	if (err == nil && expectedError != nil) || (err != nil && expectedError == nil) || (err != nil && expectedError != nil && err.Error() != expectedError.Error()) {
+		t.Errorf("InterpretInterval(%s) err = %s should be %s", input, err, expectedError)
+	}
+}
+
+func UncertainTestCase(t *testing.T, input string, expectedOutput string, expectedError error) {
+	tree, wrongSynt := Parse(input)
+	if len(wrongSynt) != 0 {
+		t.Errorf("Parse(%s) wrong syntax at %v", input, wrongSynt)
+		return
+	}
+	out, err := Evaluate(tree)
+	if expectedError == nil && out.String() != expectedOutput {
+		t.Errorf("Evaluate(%s) out = %s should be %s", input, out, expectedOutput)
+	}
+	if (err == nil && expectedError != nil) || (err != nil && expectedError == nil) || (err != nil && expectedError != nil && err.Error() != expectedError.Error()) {
+		t.Errorf("Evaluate(%s) err = %s should be %s", input, err, expectedError)
+	}
+}
+
+func UncertainTreeTestCase(t *testing.T, tree *TreeNode, expectedNumber float64, expectedUncertainty float64) {
+	out, err := Evaluate(tree)
+	if err != nil {
+		t.Errorf("Evaluate(%v) err = %s should be nil", tree, err)
+	}
+	if math.Abs(out.Number-expectedNumber) > math.Pow(10, -10) || math.Abs(out.Uncertainty-expectedUncertainty) > math.Pow(10, -10) {
+		t.Errorf("Evaluate(%v) out = %f ± %f should be %f ± %f", tree, out.Number, out.Uncertainty, expectedNumber, expectedUncertainty)
+	}
+}
+
+func TestInterpretPower(t *testing.T) {
+	ExpressionTestCase(t, "2^-1", 0.5, nil)
+	ExpressionTestCase(t, "2^(-2)", 0.25, nil)
+	ExpressionTestCase(t, "4^0.5", 2, nil)
+	ExpressionTestCase(t, "2.5^2", 6.25, nil)
+	ExpressionTestCase(t, "(1+1)^3", 8, nil)
+	ExpressionTestCase(t, "2^(1+2)", 8, nil)
+	ExpressionTestCase(t, "2^3^2", 512, nil)
+	ExpressionTestCase(t, "|-2|^2", 4, nil)
+	ExpressionTestCase(t, "(3!)^2", 36, nil)
+	ExpressionTestCase(t, "2^3!", 64, nil)
+	ExpressionTestCase(t, "2^√4", 4, nil)
+	ExpressionTestCase(t, "(-8)^(1/3)", 0, errors.New("cannot raise a negative number to a fractional power: -8.000^0.3333333333333333"))
+	ExpressionTestCase(t, "10^400", 0, errors.New("result of 10.000^400 is too big"))
+
+	for _, in := range []string{"2^*3", "2^)", "(2^)", "^2", "2+^3", "2^^3", "3!^2"} {
+		if _, _, err := toSlice(in, format.EnUS); len(err) == 0 {
+			t.Errorf("toSlice(%s) gave no error", in)
+		}
+	}
+}
+
+func TestInterpretRoot(t *testing.T) {
+	ExpressionTestCase(t, "3√27", 3, nil)
+	ExpressionTestCase(t, "3.2√64", 3.668016172818685, nil)
+	ExpressionTestCase(t, "3√-8", -2, nil)
+	ExpressionTestCase(t, "root(27, 3)", 3, nil)
+	ExpressionTestCase(t, "root(16)", 4, nil)
+	ExpressionTestCase(t, "root(-8, 3)", -2, nil)
+	ExpressionTestCase(t, "root(64, 3.2)", 3.668016172818685, nil)
+	ExpressionTestCase(t, "root(-64, 3.2)", 0, errors.New("can't calculate root 3.2 of a negative number: -64.000"))
+	ExpressionTestCase(t, "root(1, 2, 3)", 0, errors.New("root takes at least 1 arguments, got 3"))
+
+	ListTestCase(t, "root({4, 9})", []float64{2, 3}, nil)
+	UncertainTestCase(t, "root(16 ± 0.4)", "4.00 ± 0.05", nil)
+	SigFigTestCase(t, "root(2.0)", "1.4", nil)
+	SigFigTestCase(t, "root(8.00, 3)", "2.00", nil)
+	IntervalTestCase(t, "root(27, 3)", "[3, 3]", nil)
+	IntervalTestCase(t, "root(16 ± 7)", "[3, 4.795831523312721]", nil)
+}
+
+func TestInterpretFactorial(t *testing.T) {
+	ExpressionTestCase(t, "0.5!", math.Sqrt(math.Pi)/2, nil)
+	ExpressionTestCase(t, "7!!", 105, nil)
+	ExpressionTestCase(t, "8!!", 384, nil)
+	ExpressionTestCase(t, "5!*2", 240, nil)
+	ExpressionTestCase(t, "gamma(5)", 24, nil)
+	ExpressionTestCase(t, "beta(2, 3)", 1.0/12, nil)
+	ExpressionTestCase(t, "(-2)!", 0, errors.New("cannot calculate factorial of negative integers"))
+	ExpressionTestCase(t, "2.5!!", 0, errors.New("double factorial works only with integers"))
+	ExpressionTestCase(t, "gamma(0)", 0, errors.New("gamma is undefined for non-positive integers"))
+	ExpressionTestCase(t, "171!", 0, errors.New("1.24101807e+309 is too big to calculate with"))
+	ExpressionTestCase(t, "171!+1", 0, errors.New("1.24101807e+309 is too big to calculate with"))
+	ExpressionTestCase(t, "100000000000000000000!", 0, errors.New("1e+20! is too big: exponent of the result doesn't fit into 64 bits"))
+	ExpressionTestCase(t, "100000000000000000000!!", 0, errors.New("1e+20!! is too big: exponent of the result doesn't fit into 64 bits"))
+
+	ListTestCase(t, "gamma({1, 2, 3})", []float64{1, 1, 2}, nil)
+	UncertainTestCase(t, "171!", "1.24101807e+309", nil)
+	UncertainTestCase(t, "1000!!", "3.993984427e+1284", nil)
+	UncertainTestCase(t, "10000!", "2.846259681e+35659", nil)
+	UncertainTestCase(t, "1000000000000000!", "~1e+14565705518096754", nil)
+	UncertainTestCase(t, "(4 ± 0.2)!!", "", errors.New("cannot propagate uncertainty through 'dfac'"))
+	SigFigTestCase(t, "5.0!!", "15", nil)
+
+	expOut := []string{"5", "!!", "+", "1"}
+	out, _, err := toSlice("5!!+1", format.EnUS)
+	if len(err) > 0 || !reflect.DeepEqual(out, expOut) {
+		t.Errorf("toSlice(5!!+1) = %v should be %v", out, expOut)
+	}
+}
+
+func TestInterpretNumberTheory(t *testing.T) {
+	ExpressionTestCase(t, "nCr(5, 2)", 10, nil)
+	ExpressionTestCase(t, "nPr(5, 2)", 20, nil)
+	ExpressionTestCase(t, "gcd(12, 18, 8)", 2, nil)
+	ExpressionTestCase(t, "lcm({4, 6})", 12, nil)
+	ExpressionTestCase(t, "isprime(97)", 1, nil)
+	ExpressionTestCase(t, "isprime(91)", 0, nil)
+	ExpressionTestCase(t, "nextprime(13)", 17, nil)
+	ExpressionTestCase(t, "totient(36)", 12, nil)
+	ExpressionTestCase(t, "powmod(2, 10, 1000)", 24, nil)
+	ExpressionTestCase(t, "powmod(3, -1, 7)", 5, nil)
+	ExpressionTestCase(t, "modinv(3, 7)", 5, nil)
+	ExpressionTestCase(t, "nCr(5.5, 2)", 0, errors.New("nCr works only with integers"))
+	ExpressionTestCase(t, "nCr({5}, 2)", 0, errors.New("expected a number, got a list"))
+	ExpressionTestCase(t, "modinv(4, 6)", 0, errors.New("4 has no inverse modulo 6"))
+
+	ListTestCase(t, "factor(360)", []float64{2, 2, 2, 3, 3, 5}, nil)
+	ListTestCase(t, "isprime({2, 4, 5})", []float64{1, 0, 1}, nil)
+	ListTestCase(t, "factor(0)", nil, errors.New("cannot factor 0"))
+}
+
+func TestInterpretDistributions(t *testing.T) {
+	ExpressionTestCase(t, "normcdf(0)", 0.5, nil)
+	ExpressionTestCase(t, "normcdf(100, 100, 15)", 0.5, nil)
+	ExpressionTestCase(t, "norminv(0.5, 10, 2)", 10, nil)
+	ExpressionTestCase(t, "normpdf(0)", 1/math.Sqrt(2*math.Pi), nil)
+	ExpressionTestCase(t, "binompdf(11, 10, 0.5)", 0, nil)
+	ExpressionTestCase(t, "binomcdf(10, 10, 0.5)", 1, nil)
+	ExpressionTestCase(t, "poissonpdf(0, 2)", math.Exp(-2), nil)
+	ExpressionTestCase(t, "tcdf(0, 3)", 0.5, nil)
+	ExpressionTestCase(t, "tinv(0.5, 3)", 0, nil)
+	ExpressionTestCase(t, "chisqcdf(-1, 2)", 0, nil)
+	ExpressionTestCase(t, "normcdf(1, 2)", 0, errors.New("expected 1 or 3 numbers, got 2"))
+	ExpressionTestCase(t, "norminv(2)", 0, errors.New("probability has to be between 0 and 1"))
+}
+
+func TestSessionRandom(t *testing.T) {
+	session := NewSession()
+	evaluate := func(input string) (Value, error) {
+		tree, wrongSynt := Parse(input)
+		if len(wrongSynt) != 0 {
+			t.Fatalf("Parse(%s) wrong syntax at %v", input, wrongSynt)
+		}
+		return session.Evaluate(tree)
+	}
+
+	out, err := evaluate("seed(42)")
+	if err != nil || out.Number != 42 || out.Seed != nil {
+		t.Errorf("seed(42) = %v, %v should be 42 without a seed", out, err)
+	}
+	first, _ := evaluate("rand()+randint(1, 6)*10+randn(5, 2)*100")
+	second, _ := evaluate("rand()")
+	if first.Seed == nil || *first.Seed != 42 || second.Seed == nil || *second.Seed != 43 {
+		t.Errorf("calculations got seeds %v and %v should be 42 and 43", first.Seed, second.Seed)
+	}
+	session.SetSeed(42)
+	replay, _ := evaluate("rand()+randint(1, 6)*10+randn(5, 2)*100")
+	if replay.Number != first.Number {
+		t.Errorf("replay with seed 42 = %v should be %v", replay, first)
+	}
+	replay, _ = evaluate("seed(43)*0+rand()")
+	if replay.Number != second.Number {
+		t.Errorf("seed(43)*0+rand() = %v should be %v", replay, second)
+	}
+	replay, _ = evaluate("seed(7)*0+seed(43)*0+rand()")
+	if replay.Number != second.Number || replay.Seed == nil || *replay.Seed != 43 {
+		t.Errorf("seed(7)*0+seed(43)*0+rand() = %v should be %v with seed 43", replay, second)
+	}
+
+	for i := 0; i < 100; i++ {
+		out, _ = evaluate("rand()")
+		if out.Number < 0 || out.Number >= 1 {
+			t.Errorf("rand() = %v should be from 0 to 1", out)
+		}
+		out, _ = evaluate("randint(-2, 2)")
+		if out.Number < -2 || out.Number > 2 || out.Number != math.Trunc(out.Number) {
+			t.Errorf("randint(-2, 2) = %v should be an integer from -2 to 2", out)
+		}
+		out, _ = evaluate("choice({3, 5})")
+		if out.Number != 3 && out.Number != 5 {
+			t.Errorf("choice({3, 5}) = %v should be 3 or 5", out)
+		}
+	}
+	out, _ = evaluate("randn(7, 0)")
+	if out.Number != 7 {
+		t.Errorf("randn(7, 0) = %v should be 7", out)
+	}
+
+	for input, expectedError := range map[string]string{
+		"randint(3, 1)":         "lower bound of randint is bigger than the upper one",
+		"randint(1.5, 3)":       "bounds of randint have to be integers",
+		"randn(1)":              "expected 0 or 2 numbers, got 1",
+		"choice({})":            "cannot choose from an empty list",
+		"choice(1)":             "choice takes a list",
+		"seed(1.5)":             "seed has to be an integer",
+		"rand()+seed(1)+rand()": "seed can't be set after a random number was drawn",
+		"rand()+seed(42)":       "seed can't be set after a random number was drawn",
+		"rand(1)":               "rand takes 0 arguments, got 1",
+	} {
+		if _, err := evaluate(input); err == nil || err.Error() != expectedError {
+			t.Errorf("%s err = %v should be %s", input, err, expectedError)
+		}
+	}
+}
+
+func TestInterpretFinance(t *testing.T) {
+	ExpressionTestCase(t, "pmt(0, 10, 1000)", -100, nil)
+	ExpressionTestCase(t, "fv(0, 10, -100, -50)", 1050, nil)
+	ExpressionTestCase(t, "pv(0, 10, -100, 0, 1)", 1000, nil)
+	ExpressionTestCase(t, "nper(0, -100, 1000)", 10, nil)
+	ExpressionTestCase(t, "npv(0, -100, 60, 50)", 10, nil)
+	ExpressionTestCase(t, "npv(0, {-100, 60, 50})", 10, nil)
+	ExpressionTestCase(t, "irr({-100, 110})", 0.1, nil)
+	ExpressionTestCase(t, "compound(1000, 0.5, 2)", 2250, nil)
+	ExpressionTestCase(t, "pmt(0.1, 10, 1000, 0, 2)", 0, errors.New("type of payments has to be 0 for the end or 1 for the beginning of periods"))
+	ExpressionTestCase(t, "rate(10, -100, 1000, 0, 0, -2)", 0, errors.New("rate has to be greater than -1"))
+	ExpressionTestCase(t, "amortize(1000, 0.1, 2)+1", 0, errors.New("tables can't be used in calculations"))
+
+	out, err := Evaluate(mustParse(t, "amortize(1000, 0, 2)"))
+	expected := "period  payment  interest  principal  balance\n     1      500         0        500      500\n     2      500         0        500        0"
+	if err != nil || out.Table == nil || out.String() != expected {
+		t.Errorf("Evaluate(amortize(1000, 0, 2)) = %v, %v should be\n%s", out, err, expected)
+	}
+}
+
+func TestInterpretInspect(t *testing.T) {
+	out, err := Evaluate(mustParse(t, "inspect(0.1+0.2)"))
+	if err != nil || out.Inspection == nil || out.Inspection.Float64.Hex != "0x3FD3333333333334" {
+		t.Errorf("Evaluate(inspect(0.1+0.2)) = %v, %v", out, err)
+	} else if out.String() != out.Inspection.String() || !strings.HasPrefix(out.String(), "float64 0.30000000000000004\n") {
+		t.Errorf("Evaluate(inspect(0.1+0.2)) = %s", out)
+	}
+	ExpressionTestCase(t, "inspect(1)+1", 0, errors.New("inspections can't be used in calculations"))
+	ExpressionTestCase(t, "inspect({1, 2})", 0, errors.New("expected 1 number, got 2"))
+	ExpressionTestCase(t, "inspect(1 ± 0.1)", 0, errors.New("expected an exact number, got an uncertain one"))
+}
+
+func TestValueFormat(t *testing.T) {
+	opts := format.Options{Notation: format.Fixed, Digits: 2, Grouping: true}
+	FormatTestCase(t, "1000000", opts, "1,000,000.00")
+	FormatTestCase(t, "{0.125, 1/3}", opts, "{0.13, 0.33}")
+	FormatTestCase(t, "1 ± 0.5", opts, "1.0 ± 0.5")
+	FormatTestCase(t, "amortize(1000, 0.1, 1)", opts, "period   payment  interest  principal  balance\n  1.00  1,100.00    100.00   1,000.00     0.00")
+	FormatTestCase(t, "0.1+0.2", format.DefaultOptions(), "0.3")
+	FormatTestCase(t, "10^6", format.DefaultOptions(), "1000000")
+
+	opts = format.DefaultOptions()
+	opts.Locale = format.CsCZ
+	FormatTestCase(t, "{0.5, 2}", opts, "{0,5; 2}")
+	FormatTestCase(t, "1.5 ± 0.25", opts, "1,50 ± 0,25")
+
+	// only plain numbers, lists and decimals can be written in another form
+	decimal, _ := mathfunc.ParseDecimal("3")
+	interval := mathfunc.PointInterval(1)
+	for _, c := range []struct {
+		value    Value
+		expected bool
+	}{
+		{NumberValue(1), true},
+		{ListValue([]float64{1, 2}), true},
+		{Value{Number: 3, Decimal: &decimal}, true},
+		{Value{Number: 1, Uncertainty: 0.1}, false},
+		{Value{Number: 1, Interval: &interval}, false},
+		{Value{Number: 1, Precision: &Precision{}}, false},
+		{Value{Large: &mathfunc.LargeNumber{Mantissa: 1, Exponent: 400}}, false},
+		{Value{Table: &Table{}}, false},
+	} {
+		if c.value.IsNumeric() != c.expected {
+			t.Errorf("%v.IsNumeric() = %v; should be %v", c.value, !c.expected, c.expected)
+		}
+	}
+}
+
+func FormatTestCase(t *testing.T, input string, opts format.Options, expectedOutput string) {
+	out, err := Evaluate(mustParse(t, input))
+	if err != nil || out.Format(opts) != expectedOutput {
+		t.Errorf("Evaluate(%s).Format(%+v) = %s, %v should be %s", input, opts, out.Format(opts), err, expectedOutput)
+	}
+}
+
+func mustParse(t *testing.T, input string) *TreeNode {
+	tree, wrongSynt := Parse(input)
+	if len(wrongSynt) != 0 {
+		t.Fatalf("Parse(%s) wrong syntax at %v", input, wrongSynt)
+	}
+	return tree
+}
+
+func TestInterpretConstants(t *testing.T) {
+	ExpressionTestCase(t, "c", 299792458, nil)
+	ExpressionTestCase(t, "2*c", 599584916, nil)
+	ExpressionTestCase(t, "h/hbar", 2*math.Pi, nil)
+	ListTestCase(t, "map(c -> c+1, {1})", []float64{2}, nil)
+	UncertainTestCase(t, "G", "6.6743e-11", nil)
+	ExpressionTestCase(t, "G/G", 1, nil)
+	ExpressionTestCase(t, "alpha-alpha", 0, nil)
+	ExpressionTestCase(t, "max(G, 1)", 1, nil)
+	ListTestCase(t, "{1, 2}*alpha", []float64{7.2973525693e-3, 2 * 7.2973525693e-3}, nil)
+
+	// uncertainty of a constant is used only when it's asked for
+	UncertainTestCase(t, "uncertain(G)", "(6.67430 ± 0.00015)e-11", nil)
+	UncertainTestCase(t, "uncertain(m_e)*c^2", "(8.1871057768 ± 0.0000000025)e-14", nil)
+	UncertainTestCase(t, "uncertain(c)", "2.99792458e+08", nil)
+	UncertainTestCase(t, "uncertain(2)", "", errors.New("argument of uncertain has to be a symbol of a physical constant"))
+	UncertainTestCase(t, "uncertain(k)", "", errors.New("argument of uncertain has to be a symbol of a physical constant"))
+
+	for _, constant := range Constants {
+		if !isIdentifier(constant.Symbol) || reservedNames[constant.Symbol] {
+			t.Errorf("constant %s can't be written in an expression", constant.Symbol)
+		}
+		if _, ok := builtins[constant.Symbol]; ok {
+			t.Errorf("constant %s is hidden by a function", constant.Symbol)
+		}
+	}
+}
+
+func TestInterpretModular(t *testing.T) {
+	ModularTestCase(t, "mod 97 { 3^200 * 5 / 7 }", "72 (mod 97)", nil)
+	ModularTestCase(t, "mod 7 {3+5}", "1 (mod 7)", nil)
+	ModularTestCase(t, "mod 7 {2-5}", "4 (mod 7)", nil)
+	ModularTestCase(t, "mod 7 {-3}", "4 (mod 7)", nil)
+	ModularTestCase(t, "mod 7 {1/3}", "5 (mod 7)", nil)
+	ModularTestCase(t, "mod 7 {3^-1}", "5 (mod 7)", nil)
+	ModularTestCase(t, "mod 1000000007 {2^(10^15)}", "264444359 (mod 1000000007)", nil)
+	ModularTestCase(t, "mod 340282366920938463463374607431768211297 {2^200}", "750856270776273588977664 (mod 340282366920938463463374607431768211297)", nil)
+	ModularTestCase(t, "mod 1 {5}", "0 (mod 1)", nil)
+
+	ModularTestCase(t, "mod 6 {1/4}", "", errors.New("4 has no inverse modulo 6"))
+	ModularTestCase(t, "mod 7 {1.5*2}", "", errors.New("only integers can be used in modular mode, got 1.5"))
+	ModularTestCase(t, "mod 7 {5%3}", "", errors.New("'mod' can't be used in modular mode"))
+	ModularTestCase(t, "mod 7 {2^(1/2)}", "", errors.New("1 isn't divisible by 2"))
+	ModularTestCase(t, "mod 0 {1}", "", errors.New("modulus has to be a positive integer"))
+
+	for in, pos := range map[string]int{"mod {1}": 4, "mod 7 1": 6, "mod 7 {1": 8, "mod 7 {*2}": 7} {
+		if _, _, wrongSynt := ParseModular(in, format.EnUS); len(wrongSynt) == 0 || wrongSynt[0] != pos {
+			t.Errorf("ParseModular(%s) wrong syntax at %v should be at %d", in, wrongSynt, pos)
+		}
+	}
+	if _, modulus, _ := ParseModular("modinv(3, 7)", format.EnUS); modulus != nil {
+		t.Errorf("ParseModular(modinv(3, 7)) modulus = %v should be nil", modulus)
+	}
+}
+
+func ModularTestCase(t *testing.T, input string, expectedOutput string, expectedError error) {
+	tree, modulus, wrongSynt := ParseModular(input, format.EnUS)
+	if len(wrongSynt) != 0 {
+		t.Errorf("ParseModular(%s) wrong syntax at %v", input, wrongSynt)
+		return
+	}
+	out, err := InterpretModular(tree, modulus)
+	if expectedError == nil && out.String() != expectedOutput {
+		t.Errorf("InterpretModular(%s) out = %v should be %s", input, out, expectedOutput)
+	}
+	if (err == nil && expectedError != nil) || (err != nil && expectedError == nil) || (err != nil && expectedError != nil && err.Error() != expectedError.Error()) {
+		t.Errorf("InterpretModular(%s) err = %s should be %s", input, err, expectedError)
+	}
+}
+
+func TestInterpretDecimal(t *testing.T) {
+	ctx := mathfunc.DecimalContext{Scale: 10, Rounding: mathfunc.RoundHalfEven}
+	DecimalModeTestCase(t, "0.1 + 0.2", ctx, "0.3", nil)
+	DecimalModeTestCase(t, "1.10 * 3", ctx, "3.30", nil)
+	DecimalModeTestCase(t, "-(0.1 + 0.2) - 0.3", ctx, "-0.6", nil)
+	DecimalModeTestCase(t, "1/3", ctx, "0.3333333333", nil)
+	DecimalModeTestCase(t, "2/3", mathfunc.DecimalContext{Scale: 2, Rounding: mathfunc.RoundHalfUp}, "0.67", nil)
+	DecimalModeTestCase(t, "0.125/1", mathfunc.DecimalContext{Scale: 2, Rounding: mathfunc.RoundHalfEven}, "0.12", nil)
+	DecimalModeTestCase(t, "0.125/1", mathfunc.DecimalContext{Scale: 2, Rounding: mathfunc.RoundHalfUp}, "0.13", nil)
+	DecimalModeTestCase(t, "1.1^2 + |-0.5| + 3!", ctx, "7.71", nil)
+	DecimalModeTestCase(t, "√2", ctx, "1.4142135624", nil)
+	DecimalModeTestCase(t, "7.5 % 2", ctx, "1.5", nil)
+	DecimalModeTestCase(t, "0.1 + 0.2 > 0.3", ctx, "0", nil)
+
+	DecimalModeTestCase(t, "sin(1)", ctx, "", errors.New("'sin' can't be used in decimal mode"))
+	DecimalModeTestCase(t, "1/0", ctx, "", errors.New("cannot divide by zero"))
+	DecimalModeTestCase(t, "1", mathfunc.DecimalContext{Scale: -1}, "", errors.New("scale has to be from 0 to 1000"))
+
+	session := NewSession()
+	session.SetDecimal(&ctx)
+	tree, _ := Parse("0.1 + 0.2")
+	if res, err := session.Evaluate(tree); err != nil || res.String() != "0.3" || res.Number != 0.3 {
+		t.Errorf("Session.Evaluate(0.1 + 0.2) in decimal mode = %v, %v should be 0.3", res, err)
+	}
+	session.SetDecimal(nil)
+	if res, _ := session.Evaluate(tree); res.Decimal != nil {
+		t.Errorf("Session.Evaluate(0.1 + 0.2) in float mode = %v should have no decimal", res)
+	}
+	tree, _ = ParseLocale("0,1 + 0,2", format.CsCZ)
+	if res, _ := InterpretDecimal(tree, ctx); res.Format(format.Options{Locale: format.CsCZ}) != "0,3" {
+		t.Errorf("InterpretDecimal(0,1 + 0,2) formatted = %s should be 0,3", res.Format(format.Options{Locale: format.CsCZ}))
+	}
+}
+
+func DecimalModeTestCase(t *testing.T, input string, ctx mathfunc.DecimalContext, expectedOutput string, expectedError error) {
+	tree, wrongSynt := Parse(input)
+	if len(wrongSynt) != 0 {
+		t.Errorf("Parse(%s) wrong syntax at %v", input, wrongSynt)
+		return
+	}
+	out, err := InterpretDecimal(tree, ctx)
+	if expectedError == nil && out.String() != expectedOutput {
+		t.Errorf("InterpretDecimal(%s) out = %v should be %s", input, out, expectedOutput)
+	}
+	if (err == nil && expectedError != nil) || (err != nil && expectedError == nil) || (err != nil && expectedError != nil && err.Error() != expectedError.Error()) {
+		t.Errorf("InterpretDecimal(%s) err = %s should be %s", input, err, expectedError)
+	}
+}
+
+// expressions evaluated by every backend, results are in the order float, big float, fraction, decimal and complex,
+// an empty result is an expected error
+var backendCorpus = []struct {
+	input   string
+	results [5]string
+}{
+	{"0.1 + 0.2", [5]string{"0.30000000000000004", "0.3", "3/10", "0.3", "0.3"}},
+	{"1/3", [5]string{"0.3333333333333333", "0.33333333333333333333", "1/3", "0.3333333333", "0.3333333333"}},
+	{"2^10 - 3!", [5]string{"1018", "1018", "1018", "1018", "1018"}},
+	{"2^-2", [5]string{"0.25", "0.25", "1/4", "0.25", "0.25"}},
+	{"√(16/9)", [5]string{"1.3333333333333333", "1.3333333333333333333", "4/3", "1.3333333333", "1.333333333"}},
+	{"3√(-27)", [5]string{"-3", "-3", "-3", "-3", "1.5 + 2.598076211i"}},
+	{"√2", [5]string{"1.414213562373095", "1.4142135623730950488", "", "1.4142135624", "1.414213562"}},
+	{"√(-4)", [5]string{"", "", "", "", "2i"}},
+	{"-7.5 % 2", [5]string{"0.5", "0.5", "1/2", "0.5", "0.5"}},
+	{"|-2.5| + 5!!", [5]string{"17.5", "17.5", "35/2", "17.5", "17.5"}},
+	{"1 < 2", [5]string{"1", "1", "1", "1", "1"}},
+	{"1/0", [5]string{"", "", "", "", ""}},
+	{"sin(1)", [5]string{"", "", "", "", ""}},
+}
+
+func TestInterpretBackend(t *testing.T) {
+	decimal := DecimalBackend{mathfunc.DecimalContext{Scale: 10, Rounding: mathfunc.RoundHalfEven}}
+	for _, c := range backendCorpus {
+		tree, wrongSynt := Parse(c.input)
+		if len(wrongSynt) != 0 {
+			t.Errorf("Parse(%s) wrong syntax at %v", c.input, wrongSynt)
+			continue
+		}
+		BackendTestCase(t, "float", c.input, c.results[0], func() (string, error) {
+			res, err := InterpretBackend[float64](tree, FloatBackend{})
+			return strconv.FormatFloat(res, 'g', -1, 64), err
+		})
+		BackendTestCase(t, "big float", c.input, c.results[1], func() (string, error) {
+			res, err := InterpretBackend[*big.Float](tree, BigFloatBackend{Precision: 128})
+			if err != nil {
+				return "", err
+			}
+			return res.Text('g', 20), nil
+		})
+		BackendTestCase(t, "fraction", c.input, c.results[2], func() (string, error) {
+			res, err := InterpretBackend[*big.Rat](tree, RatBackend{})
+			if err != nil {
+				return "", err
+			}
+			return res.RatString(), nil
+		})
+		BackendTestCase(t, "decimal", c.input, c.results[3], func() (string, error) {
+			res, err := InterpretBackend[mathfunc.Decimal](tree, decimal)
+			if err != nil {
+				return "", err
+			}
+			return res.String(), nil
+		})
+		BackendTestCase(t, "complex", c.input, c.results[4], func() (string, error) {
+			res, err := InterpretBackend[complex128](tree, ComplexBackend{})
+			return FormatComplex(res), err
+		})
+	}
+
+	tree, _ := Parse("(1 + 2*i)^2")
+	if res, err := InterpretBackend[complex128](tree, ComplexBackend{}); err != nil || FormatComplex(res) != "-3 + 4i" {
+		t.Errorf("InterpretBackend((1 + 2*i)^2) in complex mode = %s, %v should be -3 + 4i", FormatComplex(res), err)
+	}
+	tree, _ = Parse("(1/3 + 1/6) * 2")
+	if res, err := InterpretBackend[*big.Rat](tree, RatBackend{}); err != nil || res.RatString() != "1" {
+		t.Errorf("InterpretBackend((1/3 + 1/6) * 2) in fraction mode = %v, %v should be 1", res, err)
+	}
+	tree, _ = Parse("3^(2^3) / 9")
+	if res, err := InterpretBackend[*big.Int](tree, ModularBackend{big.NewInt(5)}); err != nil || res.String() != "4" {
+		t.Errorf("InterpretBackend(3^(2^3) / 9) modulo 5 = %v, %v should be 4", res, err)
+	}
+	if res, err := InterpretBackend[*big.Int](tree, ModularBackend{}); err != nil || res.String() != "729" {
+		t.Errorf("InterpretBackend(3^(2^3) / 9) in exact integers = %v, %v should be 729", res, err)
+	}
+	tree, _ = Parse("-2.50 * 1")
+	if res, err := InterpretBackend[Value](tree, SigFigBackend{}); err != nil || res.String() != "-2.50" {
+		t.Errorf("InterpretBackend(-2.50 * 1) in significant figures mode = %v, %v should be -2.50", res, err)
+	}
+}
+
+func BackendTestCase(t *testing.T, mode string, input string, expectedOutput string, evaluate func() (string, error)) {
+	out, err := evaluate()
+	if expectedOutput == "" && err == nil {
+		t.Errorf("InterpretBackend(%s) in %s mode = %s should return an error", input, mode, out)
+	}
+	if expectedOutput != "" && (err != nil || out != expectedOutput) {
+		t.Errorf("InterpretBackend(%s) in %s mode = %s, %v should be %s", input, mode, out, err, expectedOutput)
+	}
+}
+
+func ExpressionTestCase(t *testing.T, input string, expectedOutput float64, expectedError error) {
+	tree, wrongSynt := Parse(input)
+	if len(wrongSynt) != 0 {
+		t.Errorf("Parse(%s) wrong syntax at %v", input, wrongSynt)
+		return
+	}
+	out, err := Interpret(tree)
+	if out != expectedOutput {
+		t.Errorf("Interpret(%s) out = %f should be %f", input, out, expectedOutput)
+	}
+	if (err == nil && expectedError != nil) || (err != nil && expectedError == nil) || (err != nil && expectedError != nil && err.Error() != expectedError.Error()) {
+		t.Errorf("Interpret(%s) err = %s should be %s", input, err, expectedError)
+	}
+}
+
+func TestParseLocale(t *testing.T) {
+	for _, c := range []struct {
+		input    string
+		locale   format.Locale
+		expected float64
+	}{
+		{"1,234.56", format.EnUS, 1234.56},
+		{"1,234,567*2", format.EnUS, 2469134},
+		{"max(1,234, 5)", format.EnUS, 234},
+		{"(1,234)", format.EnUS, 1234},
+		{"1 234,56", format.CsCZ, 1234.56},
+		{"1\u00a0234,5", format.CsCZ, 1234.5},
+		{"max(1,5; 2,5)", format.CsCZ, 2.5},
+		{"min({1,5; 2})", format.CsCZ, 1.5},
+		{"1.234.567,5", format.DeDE, 1234567.5},
+	} {
+		tree, wrongSynt := ParseLocale(c.input, c.locale)
+		if len(wrongSynt) != 0 {
+			t.Errorf("ParseLocale(%s, %s) wrong syntax at %v", c.input, c.locale.Name, wrongSynt)
+			continue
+		}
+		if out, err := Interpret(tree); err != nil || out != c.expected {
+			t.Errorf("ParseLocale(%s, %s) = %v, %v should be %v", c.input, c.locale.Name, out, err, c.expected)
+		}
+	}
+
+	for input, pos := range map[string]int{
+		"1,5":      1,
+		"1,2345":   1,
+		"1234,567": 4,
+		"1.5,000":  3,
+	} {
+		if _, wrongSynt := ParseLocale(input, format.EnUS); len(wrongSynt) == 0 || wrongSynt[0] != pos {
+			t.Errorf("ParseLocale(%s) wrong syntax at %v should be at %d", input, wrongSynt, pos)
+		}
+	}
+	if _, wrongSynt := ParseLocale("max(1; 2)", format.EnUS); len(wrongSynt) == 0 {
+		t.Errorf("ParseLocale(max(1; 2)) gave no error")
+	}
+	if _, wrongSynt := ParseLocale("1.5", format.CsCZ); len(wrongSynt) == 0 {
+		t.Errorf("ParseLocale(1.5, cs_CZ) gave no error")
+	}
+}
+
+func TestRadixLiterals(t *testing.T) {
+	ExpressionTestCase(t, "16#FF", 255, nil)
+	ExpressionTestCase(t, "16#ff+1", 256, nil)
+	ExpressionTestCase(t, "7#1234", 466, nil)
+	ExpressionTestCase(t, "2#0.101", 0.625, nil)
+	ExpressionTestCase(t, "-36#Z*2", -70, nil)
+	ExpressionTestCase(t, "max(2#10, 8#7)", 7, nil)
+	ExpressionTestCase(t, "2^2#11", 8, nil)
+
+	for input, pos := range map[string]int{
+		"2#102":    1,
+		"37#1":     2,
+		"16#FF(1)": 5,
+		"16#FF 1":  6,
+		"1.5#1":    3,
+		"2#.1":     1,
+	} {
+		if _, wrongSynt := Parse(input); len(wrongSynt) == 0 || wrongSynt[0] != pos {
+			t.Errorf("Parse(%s) wrong syntax at %v should be at %d", input, wrongSynt, pos)
+		}
+	}
+	if tree, wrongSynt := ParseLocale("2#0,1+1", format.CsCZ); len(wrongSynt) != 0 {
+		t.Errorf("ParseLocale(2#0,1+1, cs_CZ) wrong syntax at %v", wrongSynt)
+	} else if out, err := Interpret(tree); err != nil || out != 1.5 {
+		t.Errorf("ParseLocale(2#0,1+1, cs_CZ) = %v, %v should be 1.5", out, err)
+	}
+}
+
+func TestSplitConversion(t *testing.T) {
+	for _, c := range []struct {
+		input      string
+		expression string
+		base       int
+		wrongSynt  []int
+	}{
+		{"255 to hex", "255 ", 16, nil},
+		{"0.1 to bin", "0.1 ", 2, nil},
+		{"100 to base 7", "100 ", 7, nil},
+		{"total+1 to  oct ", "total+1 ", 8, nil},
+		{"255", "255", 0, nil},
+		{"to hex", "", 16, nil},
+		{"255 to base 37", "", 0, []int{12}},
+		{"255 to hex 2", "", 0, []int{7}},
+		{"255 to decimal", "", 0, []int{7}},
+	} {
+		expression, base, wrongSynt := SplitConversion(c.input)
+		if expression != c.expression || base != c.base || !reflect.DeepEqual(wrongSynt, c.wrongSynt) {
+			t.Errorf("SplitConversion(%s) = %q, %d, %v should be %q, %d, %v", c.input, expression, base, wrongSynt,
+				c.expression, c.base, c.wrongSynt)
+		}
+	}
+}
+
+// describeTree: lists the nodes of the tree in the order of Inspect as kind, operator and the spanned text
+func describeTree(input string, tree *TreeNode) []string {
+	var nodes []string
+	Inspect(tree, func(n *TreeNode) bool {
+		if n != nil {
+			s := n.Span()
+			nodes = append(nodes, fmt.Sprintf("%v %s %q", n.Kind(), n.Op(), input[s.Start:s.End]))
+		}
+		return true
+	})
+	return nodes
+}
+
+func TestAST(t *testing.T) {
+	for _, c := range []struct {
+		input string
+		nodes []string
+	}{
+		{"3*(1+2)", []string{`binary * "3*(1+2)"`, `number 3 "3"`, `binary + "(1+2)"`, `number 1 "1"`, `number 2 "2"`}},
+		{"((7))", []string{`number 7 "((7))"`}},
+		{"-x", []string{`unary neg "-x"`, `identifier x "x"`}},
+		{"+2*-x", []string{`binary * "+2*-x"`, `unary pos "+2"`, `number 2 "2"`, `unary neg "-x"`, `identifier x "x"`}},
+		{"max(1, 2.5, pi)", []string{`call max "max(1, 2.5, pi)"`, `number 1 "1"`, `number 2.5 "2.5"`,
+			`identifier pi "pi"`}},
+		{"|2-5|! + rand()", []string{`binary + "|2-5|! + rand()"`, `unary fac "|2-5|!"`, `unary abs "|2-5|"`,
+			`binary - "2-5"`, `number 2 "2"`, `number 5 "5"`, `identifier rand "rand()"`}},
+		{"3√8 +/- {1, 2}", []string{`binary ± "3√8 +/- {1, 2}"`, `binary root "3√8"`, `number 8 "8"`,
+			`number 3 "3"`, `call list "{1, 2}"`, `number 1 "1"`, `number 2 "2"`}},
+		{"√16#FF", []string{`binary root "√16#FF"`, `number 255 "16#FF"`, `number 2 "√"`}},
+	} {
+		tree, wrongSynt := Parse(c.input)
+		if len(wrongSynt) != 0 {
+			t.Errorf("Parse(%s) wrong syntax at %v", c.input, wrongSynt)
+			continue
+		}
+		if nodes := describeTree(c.input, tree); !reflect.DeepEqual(nodes, c.nodes) {
+			t.Errorf("Parse(%s) nodes = %q should be %q", c.input, nodes, c.nodes)
+		}
+	}
+
+	tree, _ := ParseLocale("max(1,5; 2)", format.CsCZ)
+	if args := tree.Args(); len(args) != 2 || args[0].Value() != 1.5 || args[0].Token().Type() != NUMBER {
+		t.Errorf("ParseLocale(max(1,5; 2), cs_CZ) arguments = %v should be 1.5 and 2", args)
+	}
+	if args := tree.Left().Args(); args != nil {
+		t.Errorf("Args() of a comma = %v should be nil", args)
+	}
+
+	tree, _, _ = ParseModular("mod 7 { 2^10 }", format.EnUS)
+	if s := tree.Span(); s != (Span{8, 12}) {
+		t.Errorf("ParseModular(mod 7 { 2^10 }) span = %v should be {8 12}", s)
+	}
+
+	// children are skipped when the function returns false
+	count := 0
+	Inspect(tree, func(n *TreeNode) bool {
+		if n != nil {
+			count++
+		}
+		return false
+	})
+	if count != 1 {
+		t.Errorf("Inspect() visited %d nodes should be 1", count)
+	}
+}
+
+// sameTree: compares structure and tokens of two trees, spans are ignored
+func sameTree(a, b *TreeNode) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.token == b.token && sameTree(a.leftNode, b.leftNode) && sameTree(a.rightNode, b.rightNode)
+}
+
+func TestTreeString(t *testing.T) {
+	for _, c := range []struct {
+		input  string
+		output string
+	}{
+		{"1+2*3", "1 + 2 * 3"},
+		{"(1+2)*3", "(1 + 2) * 3"},
+		{"1-(2-3)", "1 - (2 - 3)"},
+		{"(1-2)-3", "1 - 2 - 3"},
+		{"2^3^2", "2^3^2"},
+		{"(2^3)^2", "(2^3)^2"},
+		{"-x", "-x"},
+		{"+x", "+x"},
+		{"x*-1", "x * -1"},
+		{"-(-x)", "-(-x)"},
+		{"2-(-3)", "2 - (-3)"},
+		{"-(2^2)", "-(2^2)"},
+		{"-2^2", "-2^2"},
+		{"2^-x", "2^-x"},
+		{"(3!)^2", "(3!)^2"},
+		{"(x!)!", "(x!)!"},
+		{"5!!+1", "5!! + 1"},
+		{"√x^2", "√x^2"},
+		{"(√x)^2", "(√x)^2"},
+		{"3√8", "3√8"},
+		{"(1+2)√8", "(1 + 2)√8"},
+		{"|2-5|*-|x|", "|2 - 5| * -|x|"},
+		{"max(1, 2+3)", "max(1, 2 + 3)"},
+		{"{1,2}%3", "{1, 2} % 3"},
+		{"map(x->x^2, {1, 2})", "map(x -> x^2, {1, 2})"},
+		{"1 +/- 0.1 < 2", "1 ± 0.1 < 2"},
+		{"16#FF", "255"},
+	} {
+		tree, wrongSynt := Parse(c.input)
+		if len(wrongSynt) != 0 {
+			t.Errorf("Parse(%s) wrong syntax at %v", c.input, wrongSynt)
+			continue
+		}
+		output := tree.String()
+		if output != c.output {
+			t.Errorf("Parse(%s).String() = %s should be %s", c.input, output, c.output)
+		}
+		if back, wrongSynt := Parse(output); len(wrongSynt) != 0 || !sameTree(tree, back) {
+			t.Errorf("Parse(%s) wrong syntax at %v or gave another tree than Parse(%s)", output, wrongSynt, c.input)
+		}
+	}
+
+	// trees created outside of the parser
+	tree := NewParent(NewToken(OPERATOR, "pow", 0), NewNode(NewToken(NUMBER, "", -0.5)), NewNode(NewToken(NUMBER, "1e3", 1000)))
+	if output := tree.String(); output != "-0.5^1000" {
+		t.Errorf("String() = %s should be -0.5^1000", output)
+	}
+}
+
+func TestTreeJSON(t *testing.T) {
+	for _, input := range []string{"-x + max(1, 2.50, pi)!", "mod 7 { 3√8 }", "{1, 2} +/- 0.5", "f(x) -> |x|"} {
+		tree, _, wrongSynt := ParseModular(input, format.EnUS)
+		if len(wrongSynt) != 0 {
+			t.Errorf("ParseModular(%s) wrong syntax at %v", input, wrongSynt)
+			continue
+		}
+		data, err := json.Marshal(tree)
+		if err != nil {
+			t.Errorf("json.Marshal(%s) gave error: %v", input, err)
+			continue
+		}
+		var back TreeNode
+		if err := json.Unmarshal(data, &back); err != nil {
+			t.Errorf("json.Unmarshal(%s) gave error: %v", data, err)
+		} else if !sameTree(tree, &back) || !reflect.DeepEqual(describeTree(input, tree), describeTree(input, &back)) {
+			t.Errorf("json.Unmarshal(%s) = %v should be %v", data, &back, tree)
+		}
+	}
+
+	tree, _ := Parse("-2.50")
+	data, _ := json.Marshal(tree)
+	expected := `{"kind":"unary","op":"neg","span":{"start":0,"end":5},"children":[` +
+		`{"kind":"number","text":"2.50","value":2.5,"span":{"start":1,"end":5}}]}`
+	if string(data) != expected {
+		t.Errorf("json.Marshal(-2.50) = %s should be %s", data, expected)
+	}
+
+	for _, data := range []string{
+		`{"kind":"ternary","op":"?"}`,
+		`{"kind":"binary","op":"+","children":[{"kind":"number","value":1}]}`,
+		`{"kind":"call","op":"max"}`,
+		`{"kind":"call","op":"1x","children":[{"kind":"identifier","op":"x"}]}`,
+		`{"kind":"unary","op":"sin","children":[{"kind":"identifier","op":"x"}]}`,
+		`{"kind":"unary","op":"neg","children":[{"kind":"identifier","op":"x"},{"kind":"identifier","op":"y"}]}`,
+		`{"kind":"binary","op":"sin","children":[{"kind":"identifier","op":"x"},{"kind":"identifier","op":"y"}]}`,
+		`{"kind":"number","text":"1"}`,
+		`{"kind":"binary","op":"+","children":[{"kind":"number","value":1},null]}`,
+	} {
+		var tree TreeNode
+		if err := json.Unmarshal([]byte(data), &tree); err == nil {
+			t.Errorf("json.Unmarshal(%s) gave no error", data)
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	for _, c := range []struct {
+		input string
+		latex string
+	}{
+		{"1/2 + 3√x", `\frac{1}{2} + \sqrt[3]{x}`},
+		{"√(x+1)", `\sqrt{x + 1}`},
+		{"root(8, 3)", `\sqrt[3]{8}`},
+		{"-2^2", `\left(-2\right)^{2}`},
+		{"-(2^2)", `-2^{2}`},
+		{"(a/b)^2", `\left(\frac{a}{b}\right)^{2}`},
+		{"2^(1/2)", `2^{\frac{1}{2}}`},
+		{"|x-1|*-y", `\left|x - 1\right| \cdot \left(-y\right)`},
+		{"n!/(k!*(n-k)!)", `\frac{n!}{k! \cdot \left(n - k\right)!}`},
+		{"(a-b)-c + (a-(b-c))", `a - b - c + \left(a - \left(b - c\right)\right)`},
+		{"2*(3 ± 1)", `2 \cdot \left(3 \pm 1\right)`},
+		{"x -> x^2", `x \mapsto x^{2}`},
+		{"sum(k, 1, 10, k^2+1)", `\sum_{k=1}^{10} \left(k^{2} + 1\right)`},
+		{"max({1, 2}) % 3", `\max\left(\left\{1, 2\right\}\right) \bmod 3`},
+		{"nCr(5, 2)", `\operatorname{nCr}\left(5, 2\right)`},
+		{"epsilon_vac*N_A", `\varepsilon_{\mathrm{vac}} \cdot N_{A}`},
+	} {
+		tree, wrongSynt := Parse(c.input)
+		if len(wrongSynt) != 0 {
+			t.Errorf("Parse(%s) wrong syntax at %v", c.input, wrongSynt)
+		} else if latex := tree.LaTeX(); latex != c.latex {
+			t.Errorf("Parse(%s).LaTeX() = %s should be %s", c.input, latex, c.latex)
+		}
+	}
+
+	tree, _ := Parse("1/2 - 3√x! < -|y|")
+	expected := `<math xmlns="http://www.w3.org/1998/Math/MathML"><mrow><mrow>` +
+		`<mfrac><mn>1</mn><mn>2</mn></mfrac><mo>−</mo><mroot><mrow><mi>x</mi><mo>!</mo></mrow><mn>3</mn></mroot>` +
+		`</mrow><mo>&lt;</mo><mrow><mo>−</mo><mrow><mo>|</mo><mi>y</mi><mo>|</mo></mrow></mrow></mrow></math>`
+	if mathML := tree.MathML(); mathML != expected {
+		t.Errorf("Parse(1/2 - 3√x! < -|y|).MathML() = %s should be %s", mathML, expected)
+	}
+}
+
+func TestExplain(t *testing.T) {
+	tree, _ := Parse("-2^2 + max(1, 3!) - sum(k, 1, 3, k)")
+	_, values, err := NewSession().Explain(tree)
+	if err != nil {
+		t.Errorf("Explain(-2^2 + max(1, 3!) - sum(k, 1, 3, k)) err = %v should be nil", err)
+	}
+	annotate := func(n *TreeNode) string {
+		if value, ok := values[n]; ok && n.Kind() != NumberNode {
+			return value.String()
+		}
+		return ""
+	}
+	expected := `- = 4
+|-- + = 10
+|   |-- ^ = 4
+|   |   |-- -x = -2
+|   |   |   ` + "`" + `-- 2
+|   |   ` + "`" + `-- 2
+|   ` + "`" + `-- max() = 6
+|       |-- 1
+|       ` + "`" + `-- ! = 6
+|           ` + "`" + `-- 3
+` + "`" + `-- sum() = 6
+    |-- k
+    |-- 1
+    |-- 3
+    ` + "`" + `-- k
+`
+	if output := tree.ASCIITree(annotate); output != expected {
+		t.Errorf("ASCIITree() = %s should be %s", output, expected)
+	}
+	// the index of the sum can't be evaluated on its own
+	if _, ok := values[tree.Right().Args()[0]]; ok {
+		t.Errorf("Explain() evaluated the index of a sum")
+	}
+
+	// both random numbers are drawn once, so they add up to the result
+	tree, _ = Parse("rand() + rand()")
+	session := NewSession()
+	session.SetSeed(42)
+	res, values, err := session.Explain(tree)
+	left, right := values[tree.Left()], values[tree.Right()]
+	if err != nil || res.Seed == nil || *res.Seed != 42 || left.Number == right.Number || left.Number+right.Number != res.Number {
+		t.Errorf("Explain(rand() + rand()) = %v, %v + %v, %v should add up", res, left, right, err)
+	}
+	decimal := mathfunc.DecimalContext{Scale: 2, Rounding: mathfunc.RoundHalfEven}
+	session.SetDecimal(&decimal)
+	tree, _ = Parse("1/3 + 1")
+	if res, values, err = session.Explain(tree); err != nil || res.String() != "1.33" || values[tree.Left()].String() != "0.33" {
+		t.Errorf("Explain(1/3 + 1) in decimal mode = %v, %v, %v should be 1.33 with 1/3 = 0.33", res, values[tree.Left()], err)
+	}
+
+	tree, _ = Parse("√|x|")
+	expected = "digraph expression {\n\tgraph [ordering=out];\n\tnode [shape=box];\n" +
+		"\tn0 [label=\"√\"];\n\tn1 [label=\"|x|\\n= \\\"1\\\"\"];\n\tn2 [label=\"x\"];\n\tn3 [label=\"2\"];\n" +
+		"\tn0 -> n1;\n\tn0 -> n3;\n\tn1 -> n2;\n}\n"
+	output := tree.DOT(func(n *TreeNode) string {
+		if n.Op() == "abs" {
+			return `"1"`
+		}
+		return ""
+	})
+	if output != expected {
+		t.Errorf("DOT() = %s should be %s", output, expected)
+	}
+}
+
 func TestToSlice(t *testing.T) {
 	in := "1010+10/5"
 	expOut := []string{"1010", "+", "10", "/", "5"}
 
-	out, err := toSlice(in)
+	out, _, err := toSlice(in, format.EnUS)
 	if len(err) > 0 {
 		t.Errorf("Error given should be no error")
 	} else {
@@ -139,7 +1297,7 @@ func TestToSlice(t *testing.T) {
 	in = "(50+(30/10)*5-2^5+5.5)"
 	expOut = []string{"(", "50", "+", "(", "30", "/", "10", ")", "*", "5", "-", "2", "^", "5", "+", "5.5", ")"}
 
-	out, err = toSlice(in)
+	out, _, err = toSlice(in, format.EnUS)
 	if len(err) > 0 {
 		t.Errorf("Error given should be no error")
 	} else {
@@ -155,9 +1313,9 @@ func TestToSlice(t *testing.T) {
 	}
 
 	in = "√(5^|-5|-1)+5%5"
-	expOut = []string{"2", "√", "(", "5", "^", "-", "5", "|", "-", "1", ")", "+", "5", "%", "5"}
+	expOut = []string{"2", "√", "(", "5", "^", "|", "-", "5", "|", "-", "1", ")", "+", "5", "%", "5"}
 
-	out, _ = toSlice(in)
+	out, _, _ = toSlice(in, format.EnUS)
 
 	if len(expOut) == len(out) {
 		for i := 0; i < len(expOut); i++ {
@@ -170,14 +1328,14 @@ func TestToSlice(t *testing.T) {
 	}
 
 	in = ""
-	out, _ = toSlice(in)
+	out, _, _ = toSlice(in, format.EnUS)
 
 	if out != nil {
 		t.Errorf("Slice should be nil")
 	}
 
 	in = ")"
-	out, err = toSlice(in)
+	out, _, err = toSlice(in, format.EnUS)
 
 	if len(err) <= 0 {
 		t.Errorf("No error given should be error")
@@ -185,7 +1343,7 @@ func TestToSlice(t *testing.T) {
 
 	in = "3√9"
 	expOut = []string{"3", "√", "9"}
-	out, err = toSlice(in)
+	out, _, err = toSlice(in, format.EnUS)
 
 	if len(err) > 0 {
 		t.Errorf("Error given should be no error")
@@ -203,7 +1361,7 @@ func TestToSlice(t *testing.T) {
 
 	in = "(√16)"
 	expOut = []string{"(", "2", "√", "16", ")"}
-	out, err = toSlice(in)
+	out, _, err = toSlice(in, format.EnUS)
 
 	if len(err) > 0 {
 		t.Errorf("Error given should be no error")
@@ -220,7 +1378,7 @@ func TestToSlice(t *testing.T) {
 	}
 
 	in = "(^"
-	out, err = toSlice(in)
+	out, _, err = toSlice(in, format.EnUS)
 
 	if len(err) <= 0 {
 		t.Errorf("No error given should be error")
@@ -228,7 +1386,7 @@ func TestToSlice(t *testing.T) {
 
 	in = "--5"
 	expOut = []string{"+", "5"}
-	out, err = toSlice(in)
+	out, _, err = toSlice(in, format.EnUS)
 
 	if len(err) > 0 {
 		t.Errorf("Error given should be no error")
@@ -246,7 +1404,7 @@ func TestToSlice(t *testing.T) {
 
 	in = "+-5"
 	expOut = []string{"-", "5"}
-	out, err = toSlice(in)
+	out, _, err = toSlice(in, format.EnUS)
 
 	if len(err) > 0 {
 		t.Errorf("Error given should be no error")
@@ -264,7 +1422,7 @@ func TestToSlice(t *testing.T) {
 
 	in = "++5"
 	expOut = []string{"+", "5"}
-	out, err = toSlice(in)
+	out, _, err = toSlice(in, format.EnUS)
 
 	if len(err) > 0 {
 		t.Errorf("Error given should be no error")
@@ -282,7 +1440,7 @@ func TestToSlice(t *testing.T) {
 
 	in = "-+5"
 	expOut = []string{"-", "5"}
-	out, err = toSlice(in)
+	out, _, err = toSlice(in, format.EnUS)
 
 	if len(err) > 0 {
 		t.Errorf("Error given should be no error")
@@ -299,49 +1457,90 @@ func TestToSlice(t *testing.T) {
 	}
 
 	in = "1*%5"
-	_, err = toSlice(in)
+	_, _, err = toSlice(in, format.EnUS)
 
 	if len(err) <= 0 {
 		t.Errorf("No error given should be error")
 	}
 
 	in = "5|||"
-	_, err = toSlice(in)
+	_, _, err = toSlice(in, format.EnUS)
 
 	if len(err) <= 0 {
 		t.Errorf("No error given should be error")
 	}
 
 	in = "()("
-	_, err = toSlice(in)
+	_, _, err = toSlice(in, format.EnUS)
 
 	if len(err) <= 0 {
 		t.Errorf("No error given should be error")
 	}
 
 	in = "(()"
-	_, err = toSlice(in)
+	_, _, err = toSlice(in, format.EnUS)
 
 	if len(err) <= 0 {
 		t.Errorf("No error given should be error")
 	}
 
 	in = "(*. 5"
-	_, err = toSlice(in)
+	_, _, err = toSlice(in, format.EnUS)
 
 	if len(err) <= 0 {
 		t.Errorf("No error given should be error")
 	}
 
 	in = "5..5"
-	_, err = toSlice(in)
+	_, _, err = toSlice(in, format.EnUS)
 
 	if len(err) <= 0 {
 		t.Errorf("No error given should be error")
 	}
 
 	in = "5..5"
-	_, err = toSlice(in)
+	_, _, err = toSlice(in, format.EnUS)
+
+	if len(err) <= 0 {
+		t.Errorf("No error given should be error")
+	}
+
+	in = "sum(k, 1, 2.5, k^2)"
+	expOut = []string{"sum(", "k", ",", "1", ",", "2.5", ",", "k", "^", "2", ")"}
+	out, _, err = toSlice(in, format.EnUS)
+
+	if len(err) > 0 {
+		t.Errorf("Error given should be no error")
+	} else if !reflect.DeepEqual(out, expOut) {
+		t.Errorf("Sliced out %v should be %v", out, expOut)
+	}
+
+	in = "f()+1"
+	expOut = []string{"f", "+", "1"}
+	out, _, err = toSlice(in, format.EnUS)
+
+	if len(err) > 0 {
+		t.Errorf("Error given should be no error")
+	} else if !reflect.DeepEqual(out, expOut) {
+		t.Errorf("Sliced out %v should be %v", out, expOut)
+	}
+
+	in = "2k"
+	_, _, err = toSlice(in, format.EnUS)
+
+	if len(err) <= 0 {
+		t.Errorf("No error given should be error")
+	}
+
+	in = "sum(,k)"
+	_, _, err = toSlice(in, format.EnUS)
+
+	if len(err) <= 0 {
+		t.Errorf("No error given should be error")
+	}
+
+	in = "m+1"
+	_, _, err = toSlice(in, format.EnUS)
 
 	if len(err) <= 0 {
 		t.Errorf("No error given should be error")
@@ -445,10 +1644,14 @@ func TestInToPost(t *testing.T) {
 	expectedOutput = strings.Fields("2 2 p - 8 ! +")
 	inToPostTestCase(t, input, expectedOutput)
 
+	input = strings.Fields("sum( k , 1 , 3 , k ^ 2 ) - 1")
+	expectedOutput = strings.Fields("k 1 , 3 , k 2 ^ , sum( 1 -")
+	inToPostTestCase(t, input, expectedOutput)
+
 }
 
 func inToPostTestCase(t *testing.T, input []string, expectedOutput []string) {
-	output := inToPost(input)
+	output, _ := inToPost(input, nil)
 
 	if !reflect.DeepEqual(output, expectedOutput) {
 		t.Errorf("inToPost(%v) = %v, should be %v", input, output, expectedOutput)
@@ -458,210 +1661,223 @@ func inToPostTestCase(t *testing.T, input []string, expectedOutput []string) {
 func TestToTreeOper(t *testing.T) {
 	// Basic operations
 	stack := []*TreeNode{
-		&TreeNode{Token{NUMBER, "", 2}, nil, nil},
-		&TreeNode{Token{NUMBER, "", 3}, nil, nil}}
+		&TreeNode{token: Token{NUMBER, "", 2}},
+		&TreeNode{token: Token{NUMBER, "", 3}}}
 	token := "+"
 	expectedOutput := []*TreeNode{
-		&TreeNode{Token{OPERATOR, "+", 0},
-			&TreeNode{Token{NUMBER, "", 2}, nil, nil},
-			&TreeNode{Token{NUMBER, "", 3}, nil, nil}}}
+		&TreeNode{token: Token{OPERATOR, "+", 0},
+			leftNode:  &TreeNode{token: Token{NUMBER, "", 2}},
+			rightNode: &TreeNode{token: Token{NUMBER, "", 3}}}}
 	toTreeOperTestCase(t, "plus", stack, token, expectedOutput)
 
 	stack = []*TreeNode{
-		&TreeNode{Token{NUMBER, "", 2}, nil, nil},
-		&TreeNode{Token{NUMBER, "", 3}, nil, nil}}
+		&TreeNode{token: Token{NUMBER, "", 2}},
+		&TreeNode{token: Token{NUMBER, "", 3}}}
 	token = "-"
 	expectedOutput = []*TreeNode{
-		&TreeNode{Token{OPERATOR, "-", 0},
-			&TreeNode{Token{NUMBER, "", 2}, nil, nil},
-			&TreeNode{Token{NUMBER, "", 3}, nil, nil}}}
+		&TreeNode{token: Token{OPERATOR, "-", 0},
+			leftNode:  &TreeNode{token: Token{NUMBER, "", 2}},
+			rightNode: &TreeNode{token: Token{NUMBER, "", 3}}}}
 	toTreeOperTestCase(t, "minus", stack, token, expectedOutput)
 
 	stack = []*TreeNode{
-		&TreeNode{Token{NUMBER, "", 2}, nil, nil},
-		&TreeNode{Token{NUMBER, "", 3}, nil, nil}}
+		&TreeNode{token: Token{NUMBER, "", 2}},
+		&TreeNode{token: Token{NUMBER, "", 3}}}
 	token = "*"
 	expectedOutput = []*TreeNode{
-		&TreeNode{Token{OPERATOR, "*", 0},
-			&TreeNode{Token{NUMBER, "", 2}, nil, nil},
-			&TreeNode{Token{NUMBER, "", 3}, nil, nil}}}
+		&TreeNode{token: Token{OPERATOR, "*", 0},
+			leftNode:  &TreeNode{token: Token{NUMBER, "", 2}},
+			rightNode: &TreeNode{token: Token{NUMBER, "", 3}}}}
 	toTreeOperTestCase(t, "times", stack, token, expectedOutput)
 
 	stack = []*TreeNode{
-		&TreeNode{Token{NUMBER, "", 2}, nil, nil},
-		&TreeNode{Token{NUMBER, "", 3}, nil, nil}}
+		&TreeNode{token: Token{NUMBER, "", 2}},
+		&TreeNode{token: Token{NUMBER, "", 3}}}
 	token = "/"
 	expectedOutput = []*TreeNode{
-		&TreeNode{Token{OPERATOR, "/", 0},
-			&TreeNode{Token{NUMBER, "", 2}, nil, nil},
-			&TreeNode{Token{NUMBER, "", 3}, nil, nil}}}
+		&TreeNode{token: Token{OPERATOR, "/", 0},
+			leftNode:  &TreeNode{token: Token{NUMBER, "", 2}},
+			rightNode: &TreeNode{token: Token{NUMBER, "", 3}}}}
 	toTreeOperTestCase(t, "divide", stack, token, expectedOutput)
 
 	stack = []*TreeNode{
-		&TreeNode{Token{NUMBER, "", 2}, nil, nil},
-		&TreeNode{Token{NUMBER, "", 3}, nil, nil}}
+		&TreeNode{token: Token{NUMBER, "", 2}},
+		&TreeNode{token: Token{NUMBER, "", 3}}}
 	token = "^"
 	expectedOutput = []*TreeNode{
-		&TreeNode{Token{OPERATOR, "pow", 0},
-			&TreeNode{Token{NUMBER, "", 2}, nil, nil},
-			&TreeNode{Token{NUMBER, "", 3}, nil, nil}}}
+		&TreeNode{token: Token{OPERATOR, "pow", 0},
+			leftNode:  &TreeNode{token: Token{NUMBER, "", 2}},
+			rightNode: &TreeNode{token: Token{NUMBER, "", 3}}}}
 	toTreeOperTestCase(t, "power", stack, token, expectedOutput)
 
 	stack = []*TreeNode{
-		&TreeNode{Token{NUMBER, "", 2}, nil, nil},
-		&TreeNode{Token{NUMBER, "", 3}, nil, nil}}
+		&TreeNode{token: Token{NUMBER, "", 2}},
+		&TreeNode{token: Token{NUMBER, "", 3}}}
 	token = "%"
 	expectedOutput = []*TreeNode{
-		&TreeNode{Token{OPERATOR, "mod", 0},
-			&TreeNode{Token{NUMBER, "", 2}, nil, nil},
-			&TreeNode{Token{NUMBER, "", 3}, nil, nil}}}
+		&TreeNode{token: Token{OPERATOR, "mod", 0},
+			leftNode:  &TreeNode{token: Token{NUMBER, "", 2}},
+			rightNode: &TreeNode{token: Token{NUMBER, "", 3}}}}
 	toTreeOperTestCase(t, "modulo", stack, token, expectedOutput)
 
 	// one operand operations
 	stack = []*TreeNode{
-		&TreeNode{Token{NUMBER, "", 3}, nil, nil}}
+		&TreeNode{token: Token{NUMBER, "", 3}}}
 	token = "!"
 	expectedOutput = []*TreeNode{
-		&TreeNode{Token{OPERATOR, "fac", 0},
-			&TreeNode{Token{NUMBER, "", 3}, nil, nil}, nil}}
+		&TreeNode{token: Token{OPERATOR, "fac", 0},
+			leftNode: &TreeNode{token: Token{NUMBER, "", 3}}}}
 	toTreeOperTestCase(t, "fac", stack, token, expectedOutput)
 
 	stack = []*TreeNode{
-		&TreeNode{Token{NUMBER, "", 3}, nil, nil}}
+		&TreeNode{token: Token{NUMBER, "", 3}}}
 	token = "abs"
 	expectedOutput = []*TreeNode{
-		&TreeNode{Token{OPERATOR, "abs", 0},
-			&TreeNode{Token{NUMBER, "", 3}, nil, nil}, nil}}
+		&TreeNode{token: Token{OPERATOR, "abs", 0},
+			leftNode: &TreeNode{token: Token{NUMBER, "", 3}}}}
 	toTreeOperTestCase(t, "abs", stack, token, expectedOutput)
 
 	// root
 	stack = []*TreeNode{
-		&TreeNode{Token{NUMBER, "", 2}, nil, nil},
-		&TreeNode{Token{NUMBER, "", 3}, nil, nil}}
+		&TreeNode{token: Token{NUMBER, "", 2}},
+		&TreeNode{token: Token{NUMBER, "", 3}}}
 	token = "√"
 	expectedOutput = []*TreeNode{
-		&TreeNode{Token{OPERATOR, "root", 0},
-			&TreeNode{Token{NUMBER, "", 3}, nil, nil},
-			&TreeNode{Token{NUMBER, "", 2}, nil, nil}}}
+		&TreeNode{token: Token{OPERATOR, "root", 0},
+			leftNode:  &TreeNode{token: Token{NUMBER, "", 3}},
+			rightNode: &TreeNode{token: Token{NUMBER, "", 2}}}}
 	toTreeOperTestCase(t, "root", stack, token, expectedOutput)
 
 	// unary +,- operators
 	stack = []*TreeNode{
-		&TreeNode{Token{NUMBER, "", 3}, nil, nil}}
+		&TreeNode{token: Token{NUMBER, "", 3}}}
 	token = "m"
 	expectedOutput = []*TreeNode{
-		&TreeNode{Token{OPERATOR, "*", 0.0},
-			&TreeNode{Token{NUMBER, "", 3}, nil, nil},
-			&TreeNode{Token{NUMBER, "-1", -1}, nil, nil}}}
+		&TreeNode{token: Token{OPERATOR, "*", 0.0},
+			leftNode:  &TreeNode{token: Token{NUMBER, "", 3}},
+			rightNode: &TreeNode{token: Token{NUMBER, "-1", -1}}}}
 	toTreeOperTestCase(t, "unary minus", stack, token, expectedOutput)
 
 	stack = []*TreeNode{
-		&TreeNode{Token{NUMBER, "", 3}, nil, nil}}
+		&TreeNode{token: Token{NUMBER, "", 3}}}
 	token = "p"
 	expectedOutput = []*TreeNode{
-		&TreeNode{Token{OPERATOR, "*", 0.0},
-			&TreeNode{Token{NUMBER, "", 3}, nil, nil},
-			&TreeNode{Token{NUMBER, "1", 1}, nil, nil}}}
-	toTreeOperTestCase(t, "unary minus", stack, token, expectedOutput)
+		&TreeNode{token: Token{OPERATOR, "*", 0.0},
+			leftNode:  &TreeNode{token: Token{NUMBER, "", 3}},
+			rightNode: &TreeNode{token: Token{NUMBER, "+1", 1}}}}
+	toTreeOperTestCase(t, "unary plus", stack, token, expectedOutput)
 
 	// test nested operations
 	stack = []*TreeNode{
-		&TreeNode{Token{NUMBER, "", 2}, nil, nil},
-		&TreeNode{Token{OPERATOR, "*", 0.0},
-			&TreeNode{Token{NUMBER, "", 3}, nil, nil},
-			&TreeNode{Token{NUMBER, "", 5}, nil, nil}}}
+		&TreeNode{token: Token{NUMBER, "", 2}},
+		&TreeNode{token: Token{OPERATOR, "*", 0.0},
+			leftNode:  &TreeNode{token: Token{NUMBER, "", 3}},
+			rightNode: &TreeNode{token: Token{NUMBER, "", 5}}}}
 	token = "+"
 	expectedOutput = []*TreeNode{
-		&TreeNode{Token{OPERATOR, "+", 0},
-			&TreeNode{Token{NUMBER, "", 2}, nil, nil},
-			&TreeNode{Token{OPERATOR, "*", 0.0},
-				&TreeNode{Token{NUMBER, "", 3}, nil, nil},
-				&TreeNode{Token{NUMBER, "", 5}, nil, nil}}}}
+		&TreeNode{token: Token{OPERATOR, "+", 0},
+			leftNode: &TreeNode{token: Token{NUMBER, "", 2}},
+			rightNode: &TreeNode{token: Token{OPERATOR, "*", 0.0},
+				leftNode:  &TreeNode{token: Token{NUMBER, "", 3}},
+				rightNode: &TreeNode{token: Token{NUMBER, "", 5}}}}}
 	toTreeOperTestCase(t, "nested plus, times", stack, token, expectedOutput)
+
+	stack = []*TreeNode{&TreeNode{token: Token{NUMBER, "", 1}}}
+	for _, token := range []string{"+", "<", "->", "√"} {
+		if _, err := toTreeOper(stack, token, Span{}); err == nil {
+			t.Errorf("toTreeOper(%s) with one operand gave no error", token)
+		}
+	}
 }
 
 func toTreeOperTestCase(t *testing.T, tName string, input []*TreeNode, token string, expectedOutput []*TreeNode) {
-	output := toTreeOper(input, token)
+	output, err := toTreeOper(input, token, Span{})
 
-	if !reflect.DeepEqual(output, expectedOutput) {
+	if err != nil || !reflect.DeepEqual(output, expectedOutput) {
 		t.Errorf("toTreeOper(%s) is incorrect, token = %s", tName, token)
 	}
 }
 
 func TestPostToTree(t *testing.T) {
 	input := strings.Fields("2 2 +")
-	expectedOutput := &TreeNode{Token{OPERATOR, "+", 0.0},
-		&TreeNode{Token{NUMBER, "2", 2.0}, nil, nil},
-		&TreeNode{Token{NUMBER, "2", 2.0}, nil, nil}}
+	expectedOutput := &TreeNode{token: Token{OPERATOR, "+", 0.0},
+		leftNode:  &TreeNode{token: Token{NUMBER, "2", 2.0}},
+		rightNode: &TreeNode{token: Token{NUMBER, "2", 2.0}}}
 	postToTreeTestCase(t, input, expectedOutput)
 
 	input = strings.Fields("2 2 -")
-	expectedOutput = &TreeNode{Token{OPERATOR, "-", 0.0},
-		&TreeNode{Token{NUMBER, "2", 2.0}, nil, nil},
-		&TreeNode{Token{NUMBER, "2", 2.0}, nil, nil}}
+	expectedOutput = &TreeNode{token: Token{OPERATOR, "-", 0.0},
+		leftNode:  &TreeNode{token: Token{NUMBER, "2", 2.0}},
+		rightNode: &TreeNode{token: Token{NUMBER, "2", 2.0}}}
 	postToTreeTestCase(t, input, expectedOutput)
 
 	input = strings.Fields("2 2 *")
-	expectedOutput = &TreeNode{Token{OPERATOR, "*", 0.0},
-		&TreeNode{Token{NUMBER, "2", 2.0}, nil, nil},
-		&TreeNode{Token{NUMBER, "2", 2.0}, nil, nil}}
+	expectedOutput = &TreeNode{token: Token{OPERATOR, "*", 0.0},
+		leftNode:  &TreeNode{token: Token{NUMBER, "2", 2.0}},
+		rightNode: &TreeNode{token: Token{NUMBER, "2", 2.0}}}
 	postToTreeTestCase(t, input, expectedOutput)
 
 	input = strings.Fields("2 2 /")
-	expectedOutput = &TreeNode{Token{OPERATOR, "/", 0.0},
-		&TreeNode{Token{NUMBER, "2", 2.0}, nil, nil},
-		&TreeNode{Token{NUMBER, "2", 2.0}, nil, nil}}
+	expectedOutput = &TreeNode{token: Token{OPERATOR, "/", 0.0},
+		leftNode:  &TreeNode{token: Token{NUMBER, "2", 2.0}},
+		rightNode: &TreeNode{token: Token{NUMBER, "2", 2.0}}}
 	postToTreeTestCase(t, input, expectedOutput)
 
 	input = strings.Fields("2 2 ^")
-	expectedOutput = &TreeNode{Token{OPERATOR, "pow", 0.0},
-		&TreeNode{Token{NUMBER, "2", 2.0}, nil, nil},
-		&TreeNode{Token{NUMBER, "2", 2.0}, nil, nil}}
+	expectedOutput = &TreeNode{token: Token{OPERATOR, "pow", 0.0},
+		leftNode:  &TreeNode{token: Token{NUMBER, "2", 2.0}},
+		rightNode: &TreeNode{token: Token{NUMBER, "2", 2.0}}}
 	postToTreeTestCase(t, input, expectedOutput)
 
 	input = strings.Fields("8 2 √")
-	expectedOutput = &TreeNode{Token{OPERATOR, "root", 0.0},
-		&TreeNode{Token{NUMBER, "2", 2.0}, nil, nil},
-		&TreeNode{Token{NUMBER, "8", 8.0}, nil, nil}}
+	expectedOutput = &TreeNode{token: Token{OPERATOR, "root", 0.0},
+		leftNode:  &TreeNode{token: Token{NUMBER, "2", 2.0}},
+		rightNode: &TreeNode{token: Token{NUMBER, "8", 8.0}}}
 	postToTreeTestCase(t, input, expectedOutput)
 
 	input = strings.Fields("2 2 %")
-	expectedOutput = &TreeNode{Token{OPERATOR, "mod", 0.0},
-		&TreeNode{Token{NUMBER, "2", 2.0}, nil, nil},
-		&TreeNode{Token{NUMBER, "2", 2.0}, nil, nil}}
+	expectedOutput = &TreeNode{token: Token{OPERATOR, "mod", 0.0},
+		leftNode:  &TreeNode{token: Token{NUMBER, "2", 2.0}},
+		rightNode: &TreeNode{token: Token{NUMBER, "2", 2.0}}}
 	postToTreeTestCase(t, input, expectedOutput)
 
 	input = strings.Fields("2 4 * 5 +")
-	expectedOutput = &TreeNode{Token{OPERATOR, "+", 0.0},
-		&TreeNode{Token{OPERATOR, "*", 0.0},
-			&TreeNode{Token{NUMBER, "2", 2.0}, nil, nil},
-			&TreeNode{Token{NUMBER, "4", 4.0}, nil, nil}},
-		&TreeNode{Token{NUMBER, "5", 5.0}, nil, nil}}
+	expectedOutput = &TreeNode{token: Token{OPERATOR, "+", 0.0},
+		leftNode: &TreeNode{token: Token{OPERATOR, "*", 0.0},
+			leftNode:  &TreeNode{token: Token{NUMBER, "2", 2.0}},
+			rightNode: &TreeNode{token: Token{NUMBER, "4", 4.0}}},
+		rightNode: &TreeNode{token: Token{NUMBER, "5", 5.0}}}
 	postToTreeTestCase(t, input, expectedOutput)
 
 	input = strings.Fields("2 4 * 7 / 5 +")
-	expectedOutput = &TreeNode{Token{OPERATOR, "+", 0.0},
-		&TreeNode{Token{OPERATOR, "/", 0.0},
-			&TreeNode{Token{OPERATOR, "*", 0.0},
-				&TreeNode{Token{NUMBER, "2", 2.0}, nil, nil},
-				&TreeNode{Token{NUMBER, "4", 4.0}, nil, nil}},
-			&TreeNode{Token{NUMBER, "7", 7.0}, nil, nil}},
-		&TreeNode{Token{NUMBER, "5", 5.0}, nil, nil}}
+	expectedOutput = &TreeNode{token: Token{OPERATOR, "+", 0.0},
+		leftNode: &TreeNode{token: Token{OPERATOR, "/", 0.0},
+			leftNode: &TreeNode{token: Token{OPERATOR, "*", 0.0},
+				leftNode:  &TreeNode{token: Token{NUMBER, "2", 2.0}},
+				rightNode: &TreeNode{token: Token{NUMBER, "4", 4.0}}},
+			rightNode: &TreeNode{token: Token{NUMBER, "7", 7.0}}},
+		rightNode: &TreeNode{token: Token{NUMBER, "5", 5.0}}}
 	postToTreeTestCase(t, input, expectedOutput)
 
 	input = strings.Fields("2 4 5 + *")
-	expectedOutput = &TreeNode{Token{OPERATOR, "*", 0.0},
-		&TreeNode{Token{NUMBER, "2", 2.0}, nil, nil},
-		&TreeNode{Token{OPERATOR, "+", 0.0},
-			&TreeNode{Token{NUMBER, "4", 4.0}, nil, nil},
-			&TreeNode{Token{NUMBER, "5", 5.0}, nil, nil}}}
+	expectedOutput = &TreeNode{token: Token{OPERATOR, "*", 0.0},
+		leftNode: &TreeNode{token: Token{NUMBER, "2", 2.0}},
+		rightNode: &TreeNode{token: Token{OPERATOR, "+", 0.0},
+			leftNode:  &TreeNode{token: Token{NUMBER, "4", 4.0}},
+			rightNode: &TreeNode{token: Token{NUMBER, "5", 5.0}}}}
 	postToTreeTestCase(t, input, expectedOutput)
+
+	for _, input := range [][]string{{"1", "+"}, {"x", "->"}, {"1", "<"}, {"!"}, {}} {
+		if _, wrongSynt := postToTree(input, nil); len(wrongSynt) == 0 {
+			t.Errorf("postToTree(%v) gave no error", input)
+		}
+	}
 }
 
 func postToTreeTestCase(t *testing.T, input []string, expectedOutput *TreeNode) {
-	output := postToTree(input)
+	output, wrongSynt := postToTree(input, nil)
 
-	if !reflect.DeepEqual(output, expectedOutput) {
+	if len(wrongSynt) != 0 || !reflect.DeepEqual(output, expectedOutput) {
 		t.Errorf("postToTree(%v) is incorrect", input)
 		fmt.Printf(">>> Got output:\n")
 		printTree(output, 0)
@@ -0,0 +1,199 @@
+package interpreter
+
+import (
+	"fmt"
+	"ivs-calculator/pkg/mathfunc"
+	"math"
+	"math/big"
+	"strconv"
+)
+
+/**
+ * InterpretInterval: calculates guaranteed bounds of the result of the expression
+ *
+ * Every number is an interval, bounds of the results are rounded outwards, so the exact result always lies
+ * in the resulting interval. Operator ± creates an interval from a value and its tolerance, e.g. 10 ± 0.1 is [9.9, 10.1].
+ *
+ * @param root Pointer to the AST node being evaluated
+ * @return Value the resulting interval, its Number is the midpoint of the interval
+ * @return error if the expression uses anything else than numbers and arithmetic operators,
+ * a calculation fails or the result is too big
+ */
+func InterpretInterval(root *TreeNode) (Value, error) {
+	res, err := InterpretBackend[mathfunc.Interval](root, IntervalBackend{})
+	if err != nil {
+		return Value{}, err
+	}
+	return Value{Number: res.Lo/2 + res.Hi/2, Interval: &res}, nil
+}
+
+/**
+ * IntervalBackend: intervals with outward rounded bounds of the interval mode
+ */
+type IntervalBackend struct{}
+
+/**
+ * Name: name of the mode used in error messages
+ *
+ * @return string interval
+ */
+func (IntervalBackend) Name() string {
+	return "interval"
+}
+
+/**
+ * Supports: whether the backend implements the operator
+ *
+ * @param op name of the operator
+ * @return bool true for arithmetic operators except of double factorial, and for ±
+ */
+func (IntervalBackend) Supports(op string) bool {
+	switch op {
+	case "+", "-", "*", "/", "mod", "pow", "root", "±", "abs", "fac":
+		return true
+	}
+	return false
+}
+
+/**
+ * Literal: returns the smallest interval containing the exact value of a number literal
+ *
+ * @param literal string value of the number token
+ * @param x the literal parsed as a float
+ * @return mathfunc.Interval interval containing the literal
+ * @return error never
+ */
+func (IntervalBackend) Literal(literal string, x float64) (mathfunc.Interval, error) {
+	return literalInterval(literal, x), nil
+}
+
+/**
+ * Identifier: intervals have no named numbers
+ *
+ * @param name the identifier
+ * @return mathfunc.Interval empty interval
+ * @return bool always false
+ */
+func (IntervalBackend) Identifier(name string) (mathfunc.Interval, bool) {
+	return mathfunc.Interval{}, false
+}
+
+/**
+ * Unary: applies abs or fac
+ *
+ * @param op name of the operator
+ * @param a operand
+ * @return mathfunc.Interval bounds of the result
+ * @return error if the factorial fails or is too big
+ */
+func (IntervalBackend) Unary(op string, a mathfunc.Interval) (mathfunc.Interval, error) {
+	if op == "abs" {
+		return mathfunc.IntervalAbsoluteValue(a), nil
+	}
+	return checkInterval(mathfunc.IntervalFactorial(a))
+}
+
+/**
+ * Binary: applies an operator with two operands, value ± tolerance creates an interval
+ *
+ * @param op name of the operator
+ * @param a left operand
+ * @param b right operand
+ * @return mathfunc.Interval bounds of the result
+ * @return error if the operation fails or the result is too big
+ */
+func (IntervalBackend) Binary(op string, a, b mathfunc.Interval) (mathfunc.Interval, error) {
+	switch op {
+	case "+":
+		return checkInterval(mathfunc.IntervalAdd(a, b), nil)
+	case "-":
+		return checkInterval(mathfunc.IntervalSubtract(a, b), nil)
+	case "*":
+		return checkInterval(mathfunc.IntervalMultiply(a, b), nil)
+	case "/":
+		return checkInterval(mathfunc.IntervalDivide(a, b))
+	case "mod":
+		return checkInterval(mathfunc.IntervalModulo(a, b))
+	case "pow":
+		return checkInterval(mathfunc.IntervalPower(a, b))
+	case "root":
+		return checkInterval(mathfunc.IntervalRoot(a, b))
+	default:
+		// value ± tolerance
+		tolerance := mathfunc.IntervalAbsoluteValue(b)
+		return checkInterval(mathfunc.IntervalAdd(a, mathfunc.Interval{Lo: -tolerance.Hi, Hi: tolerance.Hi}), nil)
+	}
+}
+
+/**
+ * checkInterval: passes result of an interval operation, unless it failed or overflowed
+ *
+ * @param res result of the operation
+ * @param err error of the operation
+ * @return mathfunc.Interval the result
+ * @return error if the operation failed or a bound of the result is infinite
+ */
+func checkInterval(res mathfunc.Interval, err error) (mathfunc.Interval, error) {
+	if err != nil {
+		return mathfunc.Interval{}, err
+	}
+	if math.IsInf(res.Lo, 0) || math.IsInf(res.Hi, 0) {
+		return mathfunc.Interval{}, fmt.Errorf("result is too big")
+	}
+	return res, nil
+}
+
+/**
+ * literalInterval: returns the smallest interval containing the exact value of a number literal
+ *
+ * Decimal literals like 0.1 can't be represented by a float exactly,
+ * their interval spans between the parsed float and its neighbour on the other side of the exact value.
+ *
+ * @param literal string value of the number token
+ * @param x the literal parsed as a float
+ * @return mathfunc.Interval interval containing the literal
+ */
+func literalInterval(literal string, x float64) mathfunc.Interval {
+	exact, ok := new(big.Rat).SetString(literal)
+	if !ok || math.IsInf(x, 0) {
+		return mathfunc.PointInterval(x)
+	}
+	switch exact.Cmp(new(big.Rat).SetFloat64(x)) {
+	case -1:
+		return mathfunc.Interval{Lo: math.Nextafter(x, math.Inf(-1)), Hi: x}
+	case 1:
+		return mathfunc.Interval{Lo: x, Hi: math.Nextafter(x, math.Inf(1))}
+	default:
+		return mathfunc.PointInterval(x)
+	}
+}
+
+/**
+ * formatInterval: formats an interval, e.g. [9.9, 10.1]
+ *
+ * Bounds are written with the shortest decimal numbers which still contain the whole interval.
+ *
+ * @param a the interval
+ * @return string formatted interval
+ */
+func formatInterval(a mathfunc.Interval) string {
+	return "[" + formatBound(a.Lo, math.Inf(-1)) + ", " + formatBound(a.Hi, math.Inf(1)) + "]"
+}
+
+/**
+ * formatBound: formats a bound of an interval so that the written number doesn't lie inside of the interval
+ *
+ * @param x the bound
+ * @param direction -Inf for the lower bound, +Inf for the upper bound
+ * @return string formatted bound
+ */
+func formatBound(x, direction float64) string {
+	s := strconv.FormatFloat(x, 'g', -1, 64)
+	written, _ := new(big.Rat).SetString(s)
+	cmp := written.Cmp(new(big.Rat).SetFloat64(x))
+	if cmp != 0 && (cmp < 0) != (direction < 0) {
+		// the shortest representation of the neighbouring float lies strictly outside of x
+		return strconv.FormatFloat(math.Nextafter(x, direction), 'g', -1, 64)
+	}
+	return s
+}
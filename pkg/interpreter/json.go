@@ -0,0 +1,137 @@
+package interpreter
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+/**
+ * jsonNode: JSON form of a node, e.g. {"kind":"binary","op":"+","children":[...]}
+ *
+ * Numbers keep their literal in text and their value, identifiers, operators and calls their name in op.
+ * Arguments of calls are listed in children, the same as operands of operators. Unary signs are unary nodes
+ * neg and pos.
+ */
+type jsonNode struct {
+	Kind     string      `json:"kind"`
+	Op       string      `json:"op,omitempty"`
+	Text     string      `json:"text,omitempty"`
+	Value    *float64    `json:"value,omitempty"`
+	Span     *Span       `json:"span,omitempty"`
+	Children []*TreeNode `json:"children,omitempty"`
+}
+
+// numbers of children of the node kinds, calls have at least one
+var kindChildren = map[NodeKind]int{NumberNode: 0, IdentifierNode: 0, UnaryNode: 1, BinaryNode: 2, CallNode: 1}
+
+/**
+ * MarshalJSON: encodes the tree as JSON, spans are included only when they are known
+ *
+ * @return []byte the JSON
+ * @return error if a number isn't finite
+ */
+func (n *TreeNode) MarshalJSON() ([]byte, error) {
+	kind := n.Kind()
+	node := jsonNode{Kind: kind.String(), Children: n.Children()}
+	if kind == NumberNode {
+		value := n.token.floatValue
+		node.Text, node.Value = n.token.stringValue, &value
+	} else {
+		node.Op = n.Op()
+	}
+	if !n.span.IsZero() {
+		span := n.span
+		node.Span = &span
+	}
+	return json.Marshal(node)
+}
+
+/**
+ * UnmarshalJSON: decodes the tree from JSON written by MarshalJSON
+ *
+ * @param data the JSON
+ * @return error if the JSON isn't a valid tree, e.g. a node has a wrong number of children
+ */
+func (n *TreeNode) UnmarshalJSON(data []byte) error {
+	var node jsonNode
+	if err := json.Unmarshal(data, &node); err != nil {
+		return err
+	}
+	kind, ok := parseNodeKind(node.Kind)
+	if !ok {
+		return fmt.Errorf("unknown node kind: %q", node.Kind)
+	}
+	if count := len(node.Children); count != kindChildren[kind] && !(kind == CallNode && count > 0) {
+		return fmt.Errorf("%v node %q can't have %d children", kind, node.Op+node.Text, count)
+	}
+	for _, child := range node.Children {
+		if child == nil {
+			return fmt.Errorf("%v node %q has an empty child", kind, node.Op+node.Text)
+		}
+	}
+
+	*n = TreeNode{}
+	if node.Span != nil {
+		n.span = *node.Span
+	}
+	switch kind {
+	case NumberNode:
+		if node.Value == nil {
+			return fmt.Errorf("number %q has no value", node.Text)
+		}
+		n.token = Token{NUMBER, node.Text, *node.Value}
+		return nil
+	case UnaryNode:
+		for sign, name := range signNames {
+			// unary signs are stored as a multiplication, the same as the parser stores them
+			if node.Op == name {
+				value := 1.0
+				if sign == "-" {
+					value = -1
+				}
+				n.token = Token{OPERATOR, "*", 0.0}
+				n.leftNode, n.rightNode = node.Children[0], NewNode(NewToken(NUMBER, sign+"1", value))
+				return nil
+			}
+		}
+		if !unaryOperators[node.Op] {
+			return fmt.Errorf("unknown unary operator: %q", node.Op)
+		}
+		n.leftNode = node.Children[0]
+	case BinaryNode:
+		if isIdentifier(node.Op) && node.Op != "mod" && node.Op != "pow" && node.Op != "root" {
+			return fmt.Errorf("unknown binary operator: %q", node.Op)
+		}
+		n.leftNode, n.rightNode = node.Children[0], node.Children[1]
+	default:
+		if !isIdentifier(node.Op) {
+			return fmt.Errorf("invalid name of %v: %q", kind, node.Op)
+		}
+		// arguments are chained by commas from the left, the same as the parser chains them
+		for _, arg := range node.Children {
+			if n.leftNode == nil {
+				n.leftNode = arg
+			} else {
+				n.leftNode = NewParent(NewToken(OPERATOR, ",", 0.0), n.leftNode, arg)
+			}
+		}
+	}
+	n.token = Token{OPERATOR, node.Op, 0.0}
+	return nil
+}
+
+/**
+ * parseNodeKind: finds the node kind by its name
+ *
+ * @param name name of the kind, e.g. binary
+ * @return NodeKind the kind
+ * @return bool false if there's no such kind
+ */
+func parseNodeKind(name string) (NodeKind, bool) {
+	for kind := NumberNode; kind <= CallNode; kind++ {
+		if kind.String() == name {
+			return kind, true
+		}
+	}
+	return 0, false
+}
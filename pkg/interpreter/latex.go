@@ -0,0 +1,180 @@
+package interpreter
+
+import "strings"
+
+// LaTeX of the operators, binary operators are surrounded by spaces
+var latexOperators = map[string]string{
+	"+": " + ", "-": " - ", "*": ` \cdot `, "mod": ` \bmod `, "<": " < ", ">": " > ", "±": ` \pm `,
+	"->": ` \mapsto `, ",": ", ", "fac": "!", "dfac": "!!",
+}
+
+// LaTeX of the brackets, by their opening symbol
+var latexBrackets = map[string][2]string{
+	"(": {`\left(`, `\right)`},
+	"|": {`\left|`, `\right|`},
+	"{": {`\left\{`, `\right\}`},
+}
+
+/**
+ * latexNotation: markup of LaTeX math
+ */
+type latexNotation struct{}
+
+/**
+ * number: writes the number as it is
+ *
+ * @param literal the number
+ * @return string the number
+ */
+func (latexNotation) number(literal string) string {
+	return literal
+}
+
+/**
+ * identifier: writes Greek letters by their commands, names longer than a letter upright
+ * and the part after an underscore as a subscript, e.g. N_{A}
+ *
+ * @param name the identifier
+ * @return string LaTeX of the identifier
+ */
+func (latexNotation) identifier(name string) string {
+	name, subscript := splitIdentifier(name)
+	text := latexName(name)
+	if subscript != "" {
+		text += "_{" + latexName(subscript) + "}"
+	}
+	return text
+}
+
+/**
+ * latexName: writes a Greek letter by its command, a name longer than a letter upright
+ *
+ * @param name the name
+ * @return string LaTeX of the name
+ */
+func latexName(name string) string {
+	if letter, ok := greekLetters[name]; ok {
+		return letter[0]
+	}
+	if len([]rune(name)) > 1 {
+		return `\mathrm{` + strings.ReplaceAll(name, "_", `\_`) + "}"
+	}
+	return name
+}
+
+/**
+ * function: writes functions with a command by it, e.g. \max, the others as operator names
+ *
+ * @param name name of the function
+ * @return string LaTeX of the name
+ */
+func (latexNotation) function(name string) string {
+	if mathFunctions[name] {
+		return `\` + name
+	}
+	return `\operatorname{` + strings.ReplaceAll(name, "_", `\_`) + "}"
+}
+
+/**
+ * operator: writes the operator by its symbol
+ *
+ * @param op name of the operator
+ * @return string LaTeX of the operator
+ */
+func (latexNotation) operator(op string) string {
+	if symbol, ok := latexOperators[op]; ok {
+		return symbol
+	}
+	return " " + op + " "
+}
+
+/**
+ * sign: writes the unary sign without spaces
+ *
+ * @param op - or +
+ * @return string the sign
+ */
+func (latexNotation) sign(op string) string {
+	return op
+}
+
+/**
+ * row: writes the parts after each other
+ *
+ * @param parts the parts
+ * @return string the row
+ */
+func (latexNotation) row(parts ...string) string {
+	return strings.TrimSpace(strings.Join(parts, ""))
+}
+
+/**
+ * brackets: encloses the expression in brackets sized to it
+ *
+ * @param open the opening bracket
+ * @param inner the expression
+ * @return string the bracketed expression
+ */
+func (latexNotation) brackets(open string, inner string) string {
+	pair := latexBrackets[open]
+	return pair[0] + inner + pair[1]
+}
+
+/**
+ * sequence: separates the items by commas
+ *
+ * @param items the items
+ * @return string the sequence
+ */
+func (latexNotation) sequence(items []string) string {
+	return strings.Join(items, ", ")
+}
+
+/**
+ * fraction: writes the fraction by \frac
+ *
+ * @param numerator the numerator
+ * @param denominator the denominator
+ * @return string the fraction
+ */
+func (latexNotation) fraction(numerator, denominator string) string {
+	return `\frac{` + numerator + "}{" + denominator + "}"
+}
+
+/**
+ * root: writes the root by \sqrt, the degree is in square brackets
+ *
+ * @param radicand the radicand
+ * @param degree the degree, empty for square roots
+ * @return string the root
+ */
+func (latexNotation) root(radicand, degree string) string {
+	if degree == "" {
+		return `\sqrt{` + radicand + "}"
+	}
+	return `\sqrt[` + degree + "]{" + radicand + "}"
+}
+
+/**
+ * power: writes the exponent as a superscript
+ *
+ * @param base the base
+ * @param exponent the exponent
+ * @return string the power
+ */
+func (latexNotation) power(base, exponent string) string {
+	return base + "^{" + exponent + "}"
+}
+
+/**
+ * series: writes \sum or \prod with the range of the index under and above it
+ *
+ * @param op sum or prod
+ * @param index the index variable
+ * @param from the lower bound
+ * @param to the upper bound
+ * @return string the big operator
+ */
+func (latexNotation) series(op, index, from, to string) string {
+	return `\` + op + "_{" + index + "=" + from + "}^{" + to + "} "
+}
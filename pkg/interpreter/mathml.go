@@ -0,0 +1,186 @@
+package interpreter
+
+import "strings"
+
+// MathML of the operators
+var mathMLOperators = map[string]string{
+	"+": "<mo>+</mo>", "-": "<mo>−</mo>", "*": "<mo>·</mo>", "mod": "<mo>mod</mo>", "<": "<mo>&lt;</mo>",
+	">": "<mo>&gt;</mo>", "±": "<mo>±</mo>", "->": "<mo>↦</mo>", ",": "<mo>,</mo>", "fac": "<mo>!</mo>",
+	"dfac": "<mo>!!</mo>",
+}
+
+// closing brackets of MathML, by their opening ones
+var mathMLBrackets = map[string]string{"(": ")", "|": "|", "{": "}"}
+
+// big operators of series in MathML
+var mathMLSeries = map[string]string{"sum": "∑", "prod": "∏"}
+
+// escapes text of MathML elements
+var mathMLEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+/**
+ * mathMLNotation: markup of Presentation MathML
+ */
+type mathMLNotation struct{}
+
+/**
+ * number: writes the number as an mn element
+ *
+ * @param literal the number
+ * @return string the element
+ */
+func (mathMLNotation) number(literal string) string {
+	return "<mn>" + mathMLEscaper.Replace(literal) + "</mn>"
+}
+
+/**
+ * identifier: writes the identifier as an mi element, Greek letters by their symbols
+ * and the part after an underscore as a subscript
+ *
+ * @param name the identifier
+ * @return string the element
+ */
+func (mathMLNotation) identifier(name string) string {
+	name, subscript := splitIdentifier(name)
+	if subscript == "" {
+		return mathMLName(name)
+	}
+	return "<msub>" + mathMLName(name) + mathMLName(subscript) + "</msub>"
+}
+
+/**
+ * mathMLName: writes a name as an mi element, Greek letters by their symbols
+ *
+ * @param name the name
+ * @return string the element
+ */
+func mathMLName(name string) string {
+	if letter, ok := greekLetters[name]; ok {
+		name = letter[1]
+	}
+	return "<mi>" + mathMLEscaper.Replace(name) + "</mi>"
+}
+
+/**
+ * function: writes name of the function as an mi element
+ *
+ * @param name name of the function
+ * @return string the element
+ */
+func (mathMLNotation) function(name string) string {
+	return "<mi>" + mathMLEscaper.Replace(name) + "</mi>"
+}
+
+/**
+ * operator: writes the operator as an mo element
+ *
+ * @param op name of the operator
+ * @return string the element
+ */
+func (mathMLNotation) operator(op string) string {
+	if symbol, ok := mathMLOperators[op]; ok {
+		return symbol
+	}
+	return "<mo>" + mathMLEscaper.Replace(op) + "</mo>"
+}
+
+/**
+ * sign: writes the unary sign as an mo element
+ *
+ * @param op - or +
+ * @return string the element
+ */
+func (m mathMLNotation) sign(op string) string {
+	return m.operator(op)
+}
+
+/**
+ * row: groups the parts in an mrow element
+ *
+ * @param parts the parts
+ * @return string the element, the part itself if there's only one
+ */
+func (mathMLNotation) row(parts ...string) string {
+	if len(parts) == 1 {
+		return parts[0]
+	}
+	return "<mrow>" + strings.Join(parts, "") + "</mrow>"
+}
+
+/**
+ * brackets: encloses the expression in stretchy brackets
+ *
+ * @param open the opening bracket
+ * @param inner the expression
+ * @return string the element
+ */
+func (mathMLNotation) brackets(open string, inner string) string {
+	return "<mrow><mo>" + open + "</mo>" + inner + "<mo>" + mathMLBrackets[open] + "</mo></mrow>"
+}
+
+/**
+ * sequence: separates the items by commas
+ *
+ * @param items the items
+ * @return string the element
+ */
+func (m mathMLNotation) sequence(items []string) string {
+	parts := make([]string, 0, 2*len(items))
+	for i, item := range items {
+		if i > 0 {
+			parts = append(parts, m.operator(","))
+		}
+		parts = append(parts, item)
+	}
+	return m.row(parts...)
+}
+
+/**
+ * fraction: writes the fraction as an mfrac element
+ *
+ * @param numerator the numerator
+ * @param denominator the denominator
+ * @return string the element
+ */
+func (mathMLNotation) fraction(numerator, denominator string) string {
+	return "<mfrac>" + numerator + denominator + "</mfrac>"
+}
+
+/**
+ * root: writes the root as an msqrt or mroot element
+ *
+ * @param radicand the radicand
+ * @param degree the degree, empty for square roots
+ * @return string the element
+ */
+func (mathMLNotation) root(radicand, degree string) string {
+	if degree == "" {
+		return "<msqrt>" + radicand + "</msqrt>"
+	}
+	return "<mroot>" + radicand + degree + "</mroot>"
+}
+
+/**
+ * power: writes the power as an msup element
+ *
+ * @param base the base
+ * @param exponent the exponent
+ * @return string the element
+ */
+func (mathMLNotation) power(base, exponent string) string {
+	return "<msup>" + base + exponent + "</msup>"
+}
+
+/**
+ * series: writes the big operator as an munderover element with the range of the index under and above it
+ *
+ * @param op sum or prod
+ * @param index the index variable
+ * @param from the lower bound
+ * @param to the upper bound
+ * @return string the element
+ */
+func (mathMLNotation) series(op, index, from, to string) string {
+	return "<munderover><mo>" + mathMLSeries[op] + "</mo><mrow>" + index + "<mo>=</mo>" + from + "</mrow>" + to +
+		"</munderover>"
+}
@@ -0,0 +1,311 @@
+package interpreter
+
+import (
+	"fmt"
+	"ivs-calculator/pkg/format"
+	"math"
+	"math/big"
+	"strings"
+	"unicode"
+)
+
+// biggest number of bits of an exponent calculated without a modulus
+const maxExactPowerBits = 1 << 16
+
+/**
+ * Residue: integer reduced modulo a modulus
+ */
+type Residue struct {
+	Remainder *big.Int // from 0 to Modulus-1
+	Modulus   *big.Int
+}
+
+/**
+ * String: formats the residue, e.g. 5 (mod 97)
+ *
+ * @return string formatted residue
+ */
+func (r Residue) String() string {
+	return fmt.Sprintf("%v (mod %v)", r.Remainder, r.Modulus)
+}
+
+/**
+ * ParseModular: parses an expression, which can be written in a modular context, e.g. mod 97 { 3^200 * 5 / 7 }
+ *
+ * The context has to enclose the whole expression. Expressions without it are parsed the same way as by ParseLocale.
+ *
+ * @param input infix expression to get parsed
+ * @param locale decimal mark, grouping mark and argument separator used in the expression
+ * @return *TreeNode root of a binary expression tree
+ * @return *big.Int modulus of the context, nil if the expression isn't written in one
+ * @return []int slice with positions of syntax errors, if such've been found
+ */
+func ParseModular(input string, locale format.Locale) (*TreeNode, *big.Int, []int) {
+	trimmed := strings.TrimLeftFunc(input, unicode.IsSpace)
+	if !strings.HasPrefix(trimmed, "mod") || len(trimmed) == len("mod") || !unicode.IsSpace(rune(trimmed[len("mod")])) {
+		root, wrongSynt := ParseLocale(input, locale)
+		return root, nil, wrongSynt
+	}
+
+	start := len(input) - len(trimmed) + len("mod")
+	rest := strings.TrimLeftFunc(input[start:], unicode.IsSpace)
+	pos := len(input) - len(rest)
+	digits := len(rest) - len(strings.TrimLeftFunc(rest, unicode.IsDigit))
+	modulus, ok := new(big.Int).SetString(rest[:digits], 10)
+	if !ok {
+		return nil, nil, []int{pos}
+	}
+
+	rest = strings.TrimLeftFunc(rest[digits:], unicode.IsSpace)
+	pos = len(input) - len(rest)
+	body := strings.TrimRightFunc(rest, unicode.IsSpace)
+	if !strings.HasPrefix(body, "{") {
+		return nil, nil, []int{pos}
+	}
+	if !strings.HasSuffix(body, "}") {
+		return nil, nil, []int{pos + len(body)}
+	}
+	root, wrongSynt := ParseLocale(body[1:len(body)-1], locale)
+	for i := range wrongSynt {
+		wrongSynt[i] += pos + 1
+	}
+	shiftSpans(root, pos+1)
+	return root, modulus, wrongSynt
+}
+
+/**
+ * InterpretModular: calculates the result of the expression in integers modulo a modulus
+ *
+ * Results of + - * / ^ are reduced modulo the modulus, division multiplies by the modular inverse of the divisor.
+ * Exponents are exact integers, they aren't reduced.
+ *
+ * @param root Pointer to the AST node being evaluated
+ * @param modulus the modulus, it has to be positive
+ * @return Value the result, its Residue holds the exact remainder
+ * @return error if the expression uses anything else than integers and arithmetic operators,
+ * a divisor has no inverse or the modulus isn't positive
+ */
+func InterpretModular(root *TreeNode, modulus *big.Int) (Value, error) {
+	if modulus == nil || modulus.Sign() <= 0 {
+		return Value{}, fmt.Errorf("modulus has to be a positive integer")
+	}
+	res, err := InterpretBackend[*big.Int](root, ModularBackend{modulus})
+	if err != nil {
+		return Value{}, err
+	}
+	number, _ := new(big.Float).SetInt(res).Float64()
+	return Value{Number: number, Residue: &Residue{Remainder: res, Modulus: new(big.Int).Set(modulus)}}, nil
+}
+
+/**
+ * ModularBackend: integers modulo a modulus, or exact integers if there's no modulus, which are used in exponents
+ */
+type ModularBackend struct {
+	Modulus *big.Int // nil for exact integers
+}
+
+/**
+ * Name: name of the mode used in error messages
+ *
+ * @return string modular
+ */
+func (ModularBackend) Name() string {
+	return "modular"
+}
+
+// operators of the modular mode
+var modularOperators = map[string]bool{"+": true, "-": true, "*": true, "/": true, "pow": true}
+
+/**
+ * Supports: whether the backend implements the operator
+ *
+ * @param op name of the operator
+ * @return bool true for + - * / and pow
+ */
+func (ModularBackend) Supports(op string) bool {
+	return modularOperators[op]
+}
+
+/**
+ * Literal: returns the integer written in the literal reduced modulo the modulus
+ *
+ * @param literal string value of the number token
+ * @param x the literal parsed as a float
+ * @return *big.Int the reduced integer
+ * @return error if the number isn't an integer
+ */
+func (b ModularBackend) Literal(literal string, x float64) (*big.Int, error) {
+	res, err := literalInteger(literal, x)
+	if err != nil {
+		return nil, err
+	}
+	return reduce(res, b.Modulus), nil
+}
+
+/**
+ * Identifier: no identifier can be used
+ *
+ * @param name the identifier
+ * @return *big.Int nil
+ * @return bool always false
+ */
+func (ModularBackend) Identifier(name string) (*big.Int, bool) {
+	return nil, false
+}
+
+/**
+ * Unary: no operator with one operand is supported
+ *
+ * @param op name of the operator
+ * @param x operand
+ * @return *big.Int nil
+ * @return error always
+ */
+func (ModularBackend) Unary(op string, x *big.Int) (*big.Int, error) {
+	return nil, fmt.Errorf("'%v' can't be used in modular mode", op)
+}
+
+/**
+ * Binary: applies + - * / or pow, division multiplies by the modular inverse of the divisor
+ *
+ * @param op name of the operator
+ * @param a left operand
+ * @param b right operand, an exponent has to be an exact integer
+ * @return *big.Int result reduced to the range from 0 to modulus-1 if there's a modulus
+ * @return error if the divisor has no inverse, an exact division has a remainder or the power fails
+ */
+func (m ModularBackend) Binary(op string, a, b *big.Int) (*big.Int, error) {
+	res := new(big.Int)
+	switch op {
+	case "+":
+		res.Add(a, b)
+	case "-":
+		res.Sub(a, b)
+	case "*":
+		res.Mul(a, b)
+	case "pow":
+		return m.power(a, b)
+	default:
+		if m.Modulus == nil {
+			if b.Sign() == 0 {
+				return nil, fmt.Errorf("cannot divide by zero")
+			}
+			if new(big.Int).Rem(a, b).Sign() != 0 {
+				return nil, fmt.Errorf("%v isn't divisible by %v", a, b)
+			}
+			return res.Quo(a, b), nil
+		}
+		inverse, err := modularInverse(b, m.Modulus)
+		if err != nil {
+			return nil, err
+		}
+		res.Mul(a, inverse)
+	}
+	return reduce(res, m.Modulus), nil
+}
+
+/**
+ * EvaluateNode: evaluates powers, whose exponents are exact integers, which aren't reduced,
+ * the other nodes are left to InterpretBackend
+ *
+ * @param node Pointer to the operator node
+ * @return *big.Int the power
+ * @return bool false if the node isn't a power
+ * @return error if the operands can't be evaluated or the power fails
+ */
+func (m ModularBackend) EvaluateNode(node *TreeNode) (*big.Int, bool, error) {
+	if node.token.stringValue != "pow" {
+		return nil, false, nil
+	}
+	base, err := InterpretBackend[*big.Int](node.leftNode, m)
+	if err != nil {
+		return nil, true, err
+	}
+	exponent, err := InterpretBackend[*big.Int](node.rightNode, ModularBackend{})
+	if err != nil {
+		return nil, true, err
+	}
+	res, err := m.power(base, exponent)
+	return res, true, err
+}
+
+/**
+ * power: calculates a power modulo the modulus
+ *
+ * @param base the base
+ * @param exponent the exact exponent
+ * @return *big.Int the power
+ * @return error if the base has no inverse for a negative exponent or the exact power is too big
+ */
+func (m ModularBackend) power(base, exponent *big.Int) (*big.Int, error) {
+	modulus := m.Modulus
+	if exponent.Sign() < 0 {
+		if modulus == nil {
+			return nil, fmt.Errorf("cannot raise to a negative power without a modulus")
+		}
+		var err error
+		if base, err = modularInverse(base, modulus); err != nil {
+			return nil, err
+		}
+		exponent = new(big.Int).Neg(exponent)
+	}
+	if modulus == nil && base.BitLen() > 1 && (!exponent.IsInt64() || exponent.Int64() > maxExactPowerBits/int64(base.BitLen())) {
+		return nil, fmt.Errorf("result of %v^%v is too big", base, exponent)
+	}
+	return new(big.Int).Exp(base, exponent, modulus), nil
+}
+
+/**
+ * modularInverse: returns x for which a*x is 1 modulo the modulus
+ *
+ * @param a the inverted number
+ * @param modulus the modulus
+ * @return *big.Int the inverse
+ * @return error if a and the modulus aren't coprime
+ */
+func modularInverse(a, modulus *big.Int) (*big.Int, error) {
+	if modulus.Cmp(big.NewInt(1)) == 0 {
+		return new(big.Int), nil
+	}
+	inverse := new(big.Int).ModInverse(a, modulus)
+	if inverse == nil {
+		return nil, fmt.Errorf("%v has no inverse modulo %v", a, modulus)
+	}
+	return inverse, nil
+}
+
+/**
+ * reduce: reduces an integer to the range from 0 to modulus-1
+ *
+ * @param x the integer, it may be overwritten
+ * @param modulus the modulus, nil leaves the integer unchanged
+ * @return *big.Int the reduced integer
+ */
+func reduce(x, modulus *big.Int) *big.Int {
+	if modulus == nil {
+		return x
+	}
+	return x.Mod(x, modulus)
+}
+
+/**
+ * literalInteger: returns the exact integer written in a number literal
+ *
+ * @param literal string value of the number token, empty for numbers created outside of the parser
+ * @param x the literal parsed as a float
+ * @return *big.Int the integer
+ * @return error if the number isn't an integer
+ */
+func literalInteger(literal string, x float64) (*big.Int, error) {
+	if res, ok := new(big.Int).SetString(literal, 10); ok {
+		return res, nil
+	}
+	if literal != "" {
+		return nil, fmt.Errorf("only integers can be used in modular mode, got %v", literal)
+	}
+	if x != math.Trunc(x) || math.IsInf(x, 0) {
+		return nil, fmt.Errorf("only integers can be used in modular mode, got %v", x)
+	}
+	res, _ := big.NewFloat(x).Int(nil)
+	return res, nil
+}
@@ -0,0 +1,209 @@
+package interpreter
+
+import (
+	"strconv"
+	"strings"
+)
+
+// symbols of the operators, which are written differently than they are named in the tree
+var operatorSymbols = map[string]string{
+	"mod":  "%",
+	"pow":  "^",
+	"root": "√",
+	"fac":  "!",
+	"dfac": "!!",
+}
+
+// precedence of the printed nodes, which aren't operators of operOrder
+const (
+	postfixPrec = 7 // factorials, they're right associative
+	prefixPrec  = 8 // unary signs and signed literals
+	atomPrec    = 9 // numbers, identifiers, calls, lists and absolute values
+)
+
+/**
+ * String: prints the expression in infix notation with the fewest parentheses needed to parse it back
+ *
+ * Parse(tree.String()) gives an equal tree, only spans of the nodes differ. Unary signs stored as
+ * a multiplication by -1 or +1 are printed as signs, so -x stays -x. Operators are printed
+ * with their symbols, e.g. pow as ^ and mod as %, numbers with a decimal point.
+ * Absolute values nested in absolute values can't be parsed, since bars don't tell apart where they open.
+ *
+ * @return string the expression
+ */
+func (n *TreeNode) String() string {
+	text, _ := printNode(n)
+	return text
+}
+
+/**
+ * printNode: prints the node and returns the precedence of its printed form
+ *
+ * @param n the printed node
+ * @return string the printed node
+ * @return int precedence of the node, e.g. 4 for + or atomPrec for numbers
+ */
+func printNode(n *TreeNode) (string, int) {
+	if n == nil {
+		return "", atomPrec
+	}
+	op := n.token.stringValue
+	switch n.Kind() {
+	case NumberNode:
+		return printNumber(n)
+	case IdentifierNode:
+		return op, atomPrec
+	case CallNode:
+		args := make([]string, 0)
+		for _, arg := range callArgs(n) {
+			text, prec := printNode(arg)
+			if prec <= operOrder[","].prec {
+				text = "(" + text + ")"
+			}
+			args = append(args, text)
+		}
+		if op == "list" {
+			return "{" + strings.Join(args, ", ") + "}", atomPrec
+		}
+		return op + "(" + strings.Join(args, ", ") + ")", atomPrec
+	case UnaryNode:
+		if sign := unarySign(n); sign != "" {
+			return sign + printOperand(n.leftNode, atomPrec), prefixPrec
+		}
+		text, _ := printNode(n.leftNode)
+		if op == "abs" {
+			return "|" + text + "|", atomPrec
+		}
+		return printOperand(n.leftNode, prefixPrec) + operatorSymbols[op], postfixPrec
+	}
+
+	symbol := op
+	if s, ok := operatorSymbols[op]; ok {
+		symbol = s
+	}
+	order := operOrder[symbol]
+	if op == "root" {
+		radicand := printRight(n.leftNode, order.prec)
+		// degree 2 is added by the parser for roots written without a degree
+		if degree := n.rightNode; degree.token.tokenType == NUMBER && degree.token.stringValue == "2" {
+			return symbol + radicand, order.prec
+		}
+		degree := printOperand(n.rightNode, postfixPrec+1)
+		// a root after a closing bar would have the default degree
+		if strings.HasSuffix(degree, "|") {
+			degree = "(" + degree + ")"
+		}
+		return degree + symbol + radicand, order.prec
+	}
+
+	left, prec := printNode(n.leftNode)
+	if prec < order.prec || prec == order.prec && order.rAssoc {
+		left = "(" + left + ")"
+	}
+	right := printRight(n.rightNode, order.prec)
+	// two signs in a row are merged into one by the parser, e.g. 2 - -3 is 2 + 3
+	if (op == "+" || op == "-") && (strings.HasPrefix(right, "-") || strings.HasPrefix(right, "+")) {
+		right = "(" + right + ")"
+	}
+	switch op {
+	case "pow":
+		return left + symbol + right, order.prec
+	case ",":
+		return left + symbol + " " + right, order.prec
+	}
+	return left + " " + symbol + " " + right, order.prec
+}
+
+/**
+ * printOperand: prints the node and encloses it in parentheses, if its precedence is lower than the minimal one
+ *
+ * @param n the printed node
+ * @param minPrec the minimal precedence, which doesn't need parentheses
+ * @return string the printed node
+ */
+func printOperand(n *TreeNode, minPrec int) string {
+	text, prec := printNode(n)
+	if prec < minPrec {
+		return "(" + text + ")"
+	}
+	return text
+}
+
+/**
+ * printRight: prints the right operand of a binary operator
+ *
+ * @param n the printed operand
+ * @param opPrec precedence of the operator
+ * @return string the printed operand, in parentheses if the parser would apply the operator first
+ */
+func printRight(n *TreeNode, opPrec int) string {
+	text, prec := printNode(n)
+	// operators of the same precedence are grouped from the left, only the right associative ones from the right
+	rAssoc := prec == postfixPrec || prec == opPrec && operOrder[rootSymbol(n)].rAssoc
+	if prec < opPrec || prec == opPrec && !rAssoc {
+		return "(" + text + ")"
+	}
+	return text
+}
+
+/**
+ * rootSymbol: returns the symbol of the operator in the node, e.g. ^ for pow
+ *
+ * @param n the node
+ * @return string the symbol
+ */
+func rootSymbol(n *TreeNode) string {
+	if symbol, ok := operatorSymbols[n.token.stringValue]; ok {
+		return symbol
+	}
+	return n.token.stringValue
+}
+
+/**
+ * printNumber: prints the number as it was written, numbers without a literal
+ * or in an exponent form are printed with a decimal point
+ *
+ * @param n the number node
+ * @return string the printed number
+ * @return int precedence of the number, signed numbers are prefixes
+ */
+func printNumber(n *TreeNode) (string, int) {
+	literal := n.token.stringValue
+	sign := ""
+	if strings.HasPrefix(literal, "-") || strings.HasPrefix(literal, "+") {
+		sign, literal = literal[:1], literal[1:]
+	}
+	if !isPlainLiteral(literal) {
+		x := n.token.floatValue
+		sign = ""
+		if x < 0 {
+			sign, x = "-", -x
+		}
+		literal = strconv.FormatFloat(x, 'f', -1, 64)
+	}
+	if sign != "" {
+		return sign + literal, prefixPrec
+	}
+	return literal, atomPrec
+}
+
+/**
+ * isPlainLiteral: checks whether the literal can be parsed back, i.e. it consists of digits and a decimal point
+ *
+ * @param literal the literal without a sign
+ * @return bool true for literals like 12 or 0.5
+ */
+func isPlainLiteral(literal string) bool {
+	if literal == "" || literal[0] == '.' {
+		return false
+	}
+	points := 0
+	for _, r := range literal {
+		if r == '.' {
+			points++
+		} else if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return points <= 1
+}
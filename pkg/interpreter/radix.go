@@ -0,0 +1,94 @@
+package interpreter
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// names of bases, which can be used in conversions, e.g. 255 to hex
+var radixNames = map[string]int{"bin": 2, "oct": 8, "dec": 10, "hex": 16}
+
+/**
+ * SplitConversion: splits a conversion to another base from the end of the expression,
+ * e.g. 255 to hex, 0.1 to bin or 100 to base 7
+ *
+ * @param input infix expression, which can end with a conversion
+ * @return string the expression without the conversion
+ * @return int the base from 2 to 36, 0 if the expression doesn't end with a conversion
+ * @return []int slice with positions of syntax errors in the conversion, if such've been found
+ */
+func SplitConversion(input string) (string, int, []int) {
+	start := -1
+	for i := 0; i+len("to") <= len(input); i++ {
+		// the last "to", which is a separate word
+		if input[i:i+len("to")] == "to" && (i == 0 || unicode.IsSpace(rune(input[i-1]))) &&
+			i+len("to") < len(input) && unicode.IsSpace(rune(input[i+len("to")])) {
+			start = i
+		}
+	}
+	if start == -1 {
+		return input, 0, nil
+	}
+	words := strings.Fields(input[start+len("to"):])
+	wordPos := func(word string) int {
+		return start + len("to") + strings.Index(input[start+len("to"):], word)
+	}
+	if len(words) == 1 {
+		if base, ok := radixNames[words[0]]; ok {
+			return input[:start], base, nil
+		}
+		return "", 0, []int{wordPos(words[0])}
+	}
+	if len(words) != 2 || words[0] != "base" {
+		return "", 0, []int{wordPos(words[0])}
+	}
+	base, err := strconv.Atoi(words[1])
+	if err != nil || base < 2 || base > 36 {
+		return "", 0, []int{wordPos(words[1])}
+	}
+	return input[:start], base, nil
+}
+
+/**
+ * isRadixDigit: checks whether rune can be a digit of a number in another base
+ *
+ * @param r checked rune
+ * @return bool true for ASCII digits and letters
+ */
+func isRadixDigit(r rune) bool {
+	return r < unicode.MaxASCII && (unicode.IsDigit(r) || unicode.IsLetter(r))
+}
+
+/**
+ * parseRadix: converts a number written in another base to a float, e.g. 7#1234 or 2#0.1
+ *
+ * @param base the base from 2 to 36 written in decimal
+ * @param digits digits of the number, letters are digits from 10 to 35 in either case
+ * @param decimalMark mark separating the fractional digits
+ * @return float64 the number
+ * @return error if the base isn't from 2 to 36 or a digit isn't smaller than the base
+ */
+func parseRadix(base string, digits string, decimalMark rune) (float64, error) {
+	b, err := strconv.Atoi(base)
+	if err != nil || b < 2 || b > 36 {
+		return 0, fmt.Errorf("base has to be from 2 to 36")
+	}
+	integer, fraction := digits, ""
+	if i := strings.IndexRune(digits, decimalMark); i != -1 {
+		integer, fraction = digits[:i], digits[i+len(string(decimalMark)):]
+	}
+	if integer == "" {
+		return 0, fmt.Errorf("number in base %d has no integer digits", b)
+	}
+	// exact value integer.fraction = integer fraction / base^len(fraction)
+	value, ok := new(big.Int).SetString(integer+fraction, b)
+	if !ok {
+		return 0, fmt.Errorf("%s isn't a number in base %d", digits, b)
+	}
+	scale := new(big.Int).Exp(big.NewInt(int64(b)), big.NewInt(int64(len(fraction))), nil)
+	res, _ := new(big.Rat).SetFrac(value, scale).Float64()
+	return res, nil
+}
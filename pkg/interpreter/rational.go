@@ -0,0 +1,238 @@
+package interpreter
+
+import (
+	"fmt"
+	"ivs-calculator/pkg/mathfunc"
+	"math"
+	"math/big"
+)
+
+// biggest number whose factorial is calculated exactly
+const maxExactFactorial = 5000
+
+// biggest denominator of an exponent of an exact fraction, e.g. 3 in 8^(2/3)
+const maxRationalRootDegree = 100
+
+/**
+ * RatBackend: exact fractions, e.g. 1/3 + 1/6 is exactly 1/2
+ *
+ * Roots and fractional powers are calculated only when their results are fractions too, e.g. √(4/9) is 2/3.
+ */
+type RatBackend struct{}
+
+/**
+ * Name: name of the mode used in error messages
+ *
+ * @return string fraction
+ */
+func (RatBackend) Name() string {
+	return "fraction"
+}
+
+/**
+ * Supports: whether the backend implements the operator
+ *
+ * @param op name of the operator
+ * @return bool true for arithmetic operators and comparisons
+ */
+func (RatBackend) Supports(op string) bool {
+	return arithmeticOperators[op]
+}
+
+/**
+ * Literal: returns the exact fraction written in a number literal, e.g. 0.1 is 1/10
+ *
+ * @param literal string value of the number token
+ * @param x the literal parsed as a float, used for numbers without a literal
+ * @return *big.Rat the fraction
+ * @return error if the number isn't finite
+ */
+func (RatBackend) Literal(literal string, x float64) (*big.Rat, error) {
+	if res, ok := new(big.Rat).SetString(literal); ok {
+		return res, nil
+	}
+	if math.IsInf(x, 0) || math.IsNaN(x) {
+		return nil, fmt.Errorf("%v isn't a fraction", x)
+	}
+	return new(big.Rat).SetFloat64(x), nil
+}
+
+/**
+ * Identifier: fractions have no named numbers, constants aren't exact
+ *
+ * @param name the identifier
+ * @return *big.Rat nil
+ * @return bool always false
+ */
+func (RatBackend) Identifier(name string) (*big.Rat, bool) {
+	return nil, false
+}
+
+/**
+ * Unary: applies abs, fac or dfac
+ *
+ * @param op name of the operator
+ * @param x operand
+ * @return *big.Rat result of the operator
+ * @return error if a factorial isn't of a natural number
+ */
+func (RatBackend) Unary(op string, x *big.Rat) (*big.Rat, error) {
+	if op == "abs" {
+		return new(big.Rat).Abs(x), nil
+	}
+	if !x.IsInt() {
+		return nil, fmt.Errorf("factorial in fraction mode works only with natural numbers")
+	}
+	res, err := exactFactorial(op, x.Num())
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Rat).SetInt(res), nil
+}
+
+/**
+ * Binary: applies an operator with two operands
+ *
+ * @param op name of the operator
+ * @param a left operand
+ * @param b right operand
+ * @return *big.Rat result of the operator
+ * @return error if the operation fails or its result isn't a fraction
+ */
+func (RatBackend) Binary(op string, a, b *big.Rat) (*big.Rat, error) {
+	switch op {
+	case "+":
+		return new(big.Rat).Add(a, b), nil
+	case "-":
+		return new(big.Rat).Sub(a, b), nil
+	case "*":
+		return new(big.Rat).Mul(a, b), nil
+	case "/":
+		if b.Sign() == 0 {
+			return nil, fmt.Errorf("cannot divide by zero")
+		}
+		return new(big.Rat).Quo(a, b), nil
+	case "mod":
+		if b.Sign() == 0 {
+			return nil, fmt.Errorf("cannot divide by zero")
+		}
+		// a - b*floor(a/b), the remainder has the sign of the divisor
+		q := new(big.Rat).Quo(a, b)
+		floor := new(big.Int).Div(q.Num(), q.Denom())
+		return new(big.Rat).Sub(a, new(big.Rat).Mul(b, new(big.Rat).SetInt(floor))), nil
+	case "pow":
+		return ratPower(a, b)
+	case "root":
+		if b.Sign() <= 0 {
+			return nil, fmt.Errorf("can't calculate root of a non-positive degree: %v", b.RatString())
+		}
+		return ratPower(a, new(big.Rat).Inv(b))
+	case "<":
+		return ratBool(a.Cmp(b) < 0), nil
+	default:
+		return ratBool(a.Cmp(b) > 0), nil
+	}
+}
+
+/**
+ * ratBool: converts result of a comparison to a fraction
+ *
+ * @param b result of the comparison
+ * @return *big.Rat 1 if b is true, 0 otherwise
+ */
+func ratBool(b bool) *big.Rat {
+	if b {
+		return big.NewRat(1, 1)
+	}
+	return new(big.Rat)
+}
+
+/**
+ * ratPower: raises a fraction to a rational power p/q as the q-th root of base^p
+ *
+ * @param base the base
+ * @param exponent the exponent
+ * @return *big.Rat the power
+ * @return error if the power is undefined, too big or isn't a fraction
+ */
+func ratPower(base, exponent *big.Rat) (*big.Rat, error) {
+	if base.Sign() == 0 {
+		switch exponent.Sign() {
+		case 0:
+			return nil, fmt.Errorf("0^0 is undefined")
+		case -1:
+			return nil, fmt.Errorf("cannot raise 0 to a negative power")
+		}
+		return new(big.Rat), nil
+	}
+	p, q := exponent.Num(), exponent.Denom()
+	bits := int64(base.Num().BitLen() + base.Denom().BitLen())
+	if !p.IsInt64() || new(big.Int).Abs(p).Int64() > maxExactPowerBits/bits {
+		return nil, fmt.Errorf("result of %v^%v is too big", base.RatString(), exponent.RatString())
+	}
+	if !q.IsInt64() || q.Int64() > maxRationalRootDegree {
+		return nil, fmt.Errorf("%v^%v isn't a fraction", base.RatString(), exponent.RatString())
+	}
+	n := new(big.Int).Abs(p)
+	num := new(big.Int).Exp(base.Num(), n, nil)
+	den := new(big.Int).Exp(base.Denom(), n, nil)
+	if p.Sign() < 0 {
+		num, den = den, num
+	}
+	degree := int(q.Int64())
+	negative := (num.Sign() < 0) != (den.Sign() < 0)
+	if negative && degree%2 == 0 {
+		return nil, fmt.Errorf("%v^%v isn't a real number", base.RatString(), exponent.RatString())
+	}
+	numRoot, ok := exactRoot(new(big.Int).Abs(num), degree)
+	if !ok {
+		return nil, fmt.Errorf("%v^%v isn't a fraction", base.RatString(), exponent.RatString())
+	}
+	denRoot, ok := exactRoot(new(big.Int).Abs(den), degree)
+	if !ok {
+		return nil, fmt.Errorf("%v^%v isn't a fraction", base.RatString(), exponent.RatString())
+	}
+	if negative {
+		numRoot.Neg(numRoot)
+	}
+	return new(big.Rat).SetFrac(numRoot, denRoot), nil
+}
+
+/**
+ * exactRoot: returns the nth root of a natural number, if it's an integer
+ *
+ * @param a the natural number
+ * @param n degree of the root
+ * @return *big.Int the root
+ * @return bool false if the root isn't an integer
+ */
+func exactRoot(a *big.Int, n int) (*big.Int, bool) {
+	root := mathfunc.IntegerRoot(a, n)
+	return root, new(big.Int).Exp(root, big.NewInt(int64(n)), nil).Cmp(a) == 0
+}
+
+/**
+ * exactFactorial: calculates factorial or double factorial of a natural number exactly
+ *
+ * @param op fac or dfac
+ * @param n the natural number
+ * @return *big.Int the factorial
+ * @return error if the number is negative or bigger than 5000
+ */
+func exactFactorial(op string, n *big.Int) (*big.Int, error) {
+	if n.Sign() < 0 {
+		return nil, fmt.Errorf("cannot calculate factorial of negative integers")
+	}
+	if !n.IsInt64() || n.Int64() > maxExactFactorial {
+		return nil, fmt.Errorf("factorial of %v is too big", n)
+	}
+	step := int64(1)
+	if op == "dfac" {
+		step = 2
+	}
+	res := big.NewInt(1)
+	for i := n.Int64(); i > 1; i -= step {
+		res.Mul(res, big.NewInt(i))
+	}
+	return res, nil
+}
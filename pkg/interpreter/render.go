@@ -0,0 +1,212 @@
+package interpreter
+
+import "strings"
+
+// precedence of rendered math, it follows the usual conventions, e.g. -x^2 is -(x^2), not the order of the parser
+const (
+	mathComma      = 1
+	mathLambda     = 2
+	mathComparison = 3
+	mathSum        = 4  // +, -, ±, unary signs and sums of series
+	mathProduct    = 5  // * and mod
+	mathPower      = 7  // powers, their base has to be an atom
+	mathPostfix    = 8  // factorials
+	mathFraction   = 9  // fractions are grouped, but they aren't atoms for powers and factorials
+	mathAtom       = 10 // numbers, identifiers, roots, absolute values and calls
+)
+
+// precedence of binary operators in rendered math
+var mathOrder = map[string]int{
+	",": mathComma, "->": mathLambda, "<": mathComparison, ">": mathComparison,
+	"+": mathSum, "-": mathSum, "±": mathSum, "*": mathProduct, "mod": mathProduct,
+}
+
+// functions written by their own commands, the others are written as operator names
+var mathFunctions = map[string]bool{"max": true, "min": true, "gcd": true}
+
+// Greek letters of identifiers, e.g. alpha or the alpha of epsilon_vac, written as a LaTeX command and in Unicode
+var greekLetters = map[string][2]string{
+	"alpha": {`\alpha`, "α"}, "beta": {`\beta`, "β"}, "gamma": {`\gamma`, "γ"}, "delta": {`\delta`, "δ"},
+	"epsilon": {`\varepsilon`, "ε"}, "theta": {`\theta`, "θ"}, "lambda": {`\lambda`, "λ"}, "mu": {`\mu`, "μ"},
+	"pi": {`\pi`, "π"}, "sigma": {`\sigma`, "σ"}, "tau": {`\tau`, "τ"}, "phi": {`\varphi`, "φ"},
+	"omega": {`\omega`, "ω"}, "hbar": {`\hbar`, "ℏ"},
+}
+
+/**
+ * mathNotation: markup of rendered math, LaTeX or MathML
+ *
+ * Every method returns a single element of the markup, so the results can be nested in each other.
+ */
+type mathNotation interface {
+	// number: a number without a sign, e.g. 2.5
+	number(literal string) string
+	// identifier: a variable or a constant, e.g. x or N_A
+	identifier(name string) string
+	// function: name of a called function, e.g. max
+	function(name string) string
+	// operator: a factorial or a binary operator named as in the tree, e.g. fac or mod
+	operator(op string) string
+	// sign: a unary sign, - or +
+	sign(op string) string
+	// row: parts written after each other
+	row(parts ...string) string
+	// brackets: an expression enclosed in brackets, open is (, | or {
+	brackets(open string, inner string) string
+	// sequence: arguments of a function or elements of a list separated by commas
+	sequence(items []string) string
+	fraction(numerator, denominator string) string
+	// root: a root, the degree is empty for square roots
+	root(radicand, degree string) string
+	power(base, exponent string) string
+	// series: the big operator of a sum or a product, e.g. sum for k from 1 to n, op is sum or prod
+	series(op, index, from, to string) string
+}
+
+/**
+ * LaTeX: renders the expression in LaTeX math, e.g. \frac{1}{2} + \sqrt[3]{x}
+ *
+ * Operators are bracketed by their usual precedence, unary signs stored as a multiplication by -1 or +1
+ * are written as signs.
+ *
+ * @return string the LaTeX math without delimiters like $
+ */
+func (n *TreeNode) LaTeX() string {
+	text, _ := renderMath(n, latexNotation{})
+	return text
+}
+
+/**
+ * MathML: renders the expression in Presentation MathML
+ *
+ * Operators are bracketed by their usual precedence, the same as by LaTeX.
+ *
+ * @return string the math element
+ */
+func (n *TreeNode) MathML() string {
+	text, _ := renderMath(n, mathMLNotation{})
+	return `<math xmlns="http://www.w3.org/1998/Math/MathML">` + text + `</math>`
+}
+
+/**
+ * renderMath: renders the node in the notation
+ *
+ * @param n the rendered node
+ * @param notation markup of the math
+ * @return string the rendered node
+ * @return int precedence of the node, e.g. mathSum for +
+ */
+func renderMath(n *TreeNode, notation mathNotation) (string, int) {
+	if n == nil {
+		return "", mathAtom
+	}
+	// rendered operand, in brackets if its precedence is lower than the minimal one
+	operand := func(child *TreeNode, minPrec int) string {
+		text, prec := renderMath(child, notation)
+		if prec < minPrec {
+			return notation.brackets("(", text)
+		}
+		return text
+	}
+	op := n.token.stringValue
+	switch n.Kind() {
+	case NumberNode:
+		literal, _ := printNumber(n)
+		if strings.HasPrefix(literal, "-") || strings.HasPrefix(literal, "+") {
+			return notation.row(notation.sign(literal[:1]), notation.number(literal[1:])), mathSum
+		}
+		return notation.number(literal), mathAtom
+	case IdentifierNode:
+		return notation.identifier(op), mathAtom
+	case UnaryNode:
+		if sign := unarySign(n); sign != "" {
+			return notation.row(notation.sign(sign), operand(n.leftNode, mathProduct)), mathSum
+		}
+		if op == "abs" {
+			text, _ := renderMath(n.leftNode, notation)
+			return notation.brackets("|", text), mathAtom
+		}
+		return notation.row(operand(n.leftNode, mathAtom), notation.operator(op)), mathPostfix
+	case CallNode:
+		return renderCall(n, notation)
+	}
+
+	switch op {
+	case "/":
+		left, _ := renderMath(n.leftNode, notation)
+		right, _ := renderMath(n.rightNode, notation)
+		return notation.fraction(left, right), mathFraction
+	case "pow":
+		exponent, _ := renderMath(n.rightNode, notation)
+		return notation.power(operand(n.leftNode, mathAtom), exponent), mathPower
+	case "root":
+		radicand, _ := renderMath(n.leftNode, notation)
+		degree, _ := renderMath(n.rightNode, notation)
+		if d := n.rightNode; d.token.tokenType == NUMBER && d.token.stringValue == "2" {
+			degree = ""
+		}
+		return notation.root(radicand, degree), mathAtom
+	}
+	prec, ok := mathOrder[op]
+	if !ok {
+		prec = mathSum
+	}
+	// operators are grouped from the left, only lambdas from the right
+	leftPrec, rightPrec := prec, prec+1
+	if op == "->" {
+		leftPrec, rightPrec = prec+1, prec
+	}
+	return notation.row(operand(n.leftNode, leftPrec), notation.operator(op), operand(n.rightNode, rightPrec)), prec
+}
+
+/**
+ * renderCall: renders a function call, lists are written in braces, roots by the root sign
+ * and sums and products of series by their big operators
+ *
+ * @param n the call node
+ * @param notation markup of the math
+ * @return string the rendered call
+ * @return int precedence of the call
+ */
+func renderCall(n *TreeNode, notation mathNotation) (string, int) {
+	op := n.token.stringValue
+	args := callArgs(n)
+	items := make([]string, len(args))
+	for i, arg := range args {
+		text, prec := renderMath(arg, notation)
+		if prec <= mathComma {
+			text = notation.brackets("(", text)
+		}
+		items[i] = text
+	}
+	switch {
+	case op == "list":
+		return notation.brackets("{", notation.sequence(items)), mathAtom
+	case op == "root" && len(items) <= 2:
+		degree := ""
+		if len(items) == 2 {
+			degree = items[1]
+		}
+		return notation.root(items[0], degree), mathAtom
+	case (op == "sum" || op == "prod") && len(items) == 4 && args[0].Kind() == IdentifierNode:
+		body, prec := renderMath(args[3], notation)
+		if prec < mathProduct {
+			body = notation.brackets("(", body)
+		}
+		return notation.row(notation.series(op, items[0], items[1], items[2]), body), mathSum
+	}
+	return notation.row(notation.function(op), notation.brackets("(", notation.sequence(items))), mathAtom
+}
+
+/**
+ * splitIdentifier: splits an identifier to its name and its subscript, e.g. N_A to N and A
+ *
+ * @param name the identifier
+ * @return string the name
+ * @return string the subscript, empty if there's none
+ */
+func splitIdentifier(name string) (string, string) {
+	if i := strings.Index(name, "_"); i > 0 && i < len(name)-1 {
+		return name[:i], name[i+1:]
+	}
+	return name, ""
+}
@@ -0,0 +1,110 @@
+package interpreter
+
+import (
+	"fmt"
+	"math"
+)
+
+// maximal number of terms of a sum or a product
+const maxSeriesTerms = 1000000
+
+// biggest absolute value of a bound of a sum or a product, floats can't count beyond it
+const maxSeriesBound = 1 << 53
+
+/**
+ * callArgs: returns arguments of a function call node
+ *
+ * Arguments are stored in the left subtree of the node as a chain of ',' operators.
+ *
+ * @param node Pointer to the function call node
+ * @return []*TreeNode arguments of the call in the order they were written in
+ */
+func callArgs(node *TreeNode) []*TreeNode {
+	return flattenArgs(node.leftNode)
+}
+
+/**
+ * flattenArgs: converts chain of ',' operators into a slice of its operands
+ *
+ * @param node Pointer to the root of the chain
+ * @return []*TreeNode operands of the chain
+ */
+func flattenArgs(node *TreeNode) []*TreeNode {
+	if node == nil {
+		return nil
+	}
+	if node.token.tokenType == OPERATOR && node.token.stringValue == "," {
+		return append(flattenArgs(node.leftNode), node.rightNode)
+	}
+	return []*TreeNode{node}
+}
+
+/**
+ * evalSeries: evaluates big operators sum(k, from, to, body) and prod(k, from, to, body)
+ *
+ * Body is evaluated for each integer value of the index variable k from the lower bound
+ * to the upper bound (both inclusive), the variable is visible only inside of the body.
+ * Sum over an empty range is 0, product over an empty range is 1.
+ * Terms can be also lists of the same length, these are summed or multiplied element-wise.
+ *
+ * @param node Pointer to the sum or prod node
+ * @param sc Pointer to the innermost scope of variables, can be nil
+ * @return Value sum or product of all the terms
+ * @return error if the arguments are invalid, the range has too many terms
+ * or when an error occurs when interpreting any of the terms
+ */
+func evalSeries(node *TreeNode, sc *scope) (Value, error) {
+	name := node.token.stringValue
+	args := callArgs(node)
+	if len(args) != 4 {
+		return Value{}, fmt.Errorf("%s takes 4 arguments: index, lower bound, upper bound and body", name)
+	}
+
+	index := args[0]
+	if index.token.tokenType != OPERATOR || index.leftNode != nil || index.rightNode != nil || !isIdentifier(index.token.stringValue) {
+		return Value{}, fmt.Errorf("index of %s has to be a variable name", name)
+	}
+
+	lower, err := interpretNumber(args[1], sc)
+	if err != nil {
+		return Value{}, err
+	}
+	upper, err := interpretNumber(args[2], sc)
+	if err != nil {
+		return Value{}, err
+	}
+	if lower != math.Trunc(lower) || upper != math.Trunc(upper) {
+		return Value{}, fmt.Errorf("bounds of %s have to be integers", name)
+	}
+	if math.Abs(lower) > maxSeriesBound || math.Abs(upper) > maxSeriesBound {
+		return Value{}, fmt.Errorf("bounds of %s have to be from -2^53 to 2^53", name)
+	}
+	if upper-lower >= maxSeriesTerms {
+		return Value{}, fmt.Errorf("%s can't have more than %d terms", name, maxSeriesTerms)
+	}
+
+	op, res := "+", NumberValue(0)
+	if name == "prod" {
+		op, res = "*", NumberValue(1)
+	}
+	for k := int64(lower); k <= int64(upper); k++ {
+		term, err := interpret(args[3], &scope{name: index.token.stringValue, value: float64(k), parent: sc})
+		if err != nil {
+			return Value{}, err
+		}
+		res, err = applyBinary(op, res, term)
+		if err != nil {
+			return Value{}, err
+		}
+	}
+
+	if math.IsInf(res.Number, 0) {
+		return Value{}, fmt.Errorf("result of %s is too big", name)
+	}
+	for _, x := range res.List {
+		if math.IsInf(x, 0) {
+			return Value{}, fmt.Errorf("result of %s is too big", name)
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,344 @@
+package interpreter
+
+import (
+	"fmt"
+	"ivs-calculator/pkg/mathfunc"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+/**
+ * Session: state shared by the calculations of one user, which is the seed of random numbers
+ * and the numeric mode, in which the calculations are evaluated
+ *
+ * Every calculation using random numbers gets its own generator seeded by the seed of the session,
+ * after that the seed of the session is increased by one. Setting the seed to the one a calculation got
+ * and running the calculation again therefore gives the same result.
+ */
+type Session struct {
+	mutex   sync.Mutex
+	seed    int64                    // seed of the next calculation using random numbers
+	decimal *mathfunc.DecimalContext // nil if calculations use floats
+}
+
+// session used by Evaluate and Interpret
+var defaultSession = NewSession()
+
+/**
+ * NewSession: creates a session with a seed based on the current time
+ *
+ * @return *Session the session
+ */
+func NewSession() *Session {
+	return &Session{seed: time.Now().UnixNano()}
+}
+
+/**
+ * SetSeed: sets the seed of the next calculation using random numbers
+ *
+ * @param seed the seed
+ */
+func (s *Session) SetSeed(seed int64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.seed = seed
+}
+
+/**
+ * SetDecimal: selects the numeric mode of the calculations, decimal with the given scale and rounding, or floats
+ *
+ * @param ctx scale and rounding of the decimal mode, nil for floats
+ */
+func (s *Session) SetDecimal(ctx *mathfunc.DecimalContext) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if ctx == nil {
+		s.decimal = nil
+		return
+	}
+	decimal := *ctx
+	s.decimal = &decimal
+}
+
+/**
+ * Decimal: returns the settings of the decimal mode of the session
+ *
+ * @return *mathfunc.DecimalContext scale and rounding of the decimal mode, nil if calculations use floats
+ */
+func (s *Session) Decimal() *mathfunc.DecimalContext {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.decimal == nil {
+		return nil
+	}
+	decimal := *s.decimal
+	return &decimal
+}
+
+/**
+ * nextSeed: returns the seed of a calculation using random numbers and moves to the seed of the next one
+ *
+ * @return int64 the seed
+ */
+func (s *Session) nextSeed() int64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	seed := s.seed
+	s.seed++
+	return seed
+}
+
+/**
+ * Evaluate: calculates the result of the expression within the session, in the decimal mode if it's selected
+ *
+ * @param root Pointer to the AST node being evaluated
+ * @return Value result of the whole expression, its Seed is set if random numbers were used
+ * @return error if there was an error when evaluating the AST - see standardBackend and InterpretDecimal for details
+ */
+func (s *Session) Evaluate(root *TreeNode) (Value, error) {
+	if decimal := s.Decimal(); decimal != nil {
+		return InterpretDecimal(root, *decimal)
+	}
+	return s.evaluate(root, nil)
+}
+
+/**
+ * Explain: calculates the result like Evaluate and records the values of the subtrees it was calculated from
+ *
+ * Random numbers are drawn only once, so the values of the subtrees add up to the result. Bodies of sums and lambdas
+ * are left out, they are evaluated for many values of their variable. In the decimal mode every subtree
+ * is evaluated on its own, see IntermediateValues.
+ *
+ * @param root Pointer to the AST node being evaluated
+ * @return Value result of the whole expression, its Seed is set if random numbers were used
+ * @return map[*TreeNode]Value values of the subtrees by their roots
+ * @return error if there was an error when evaluating the AST - see standardBackend and InterpretDecimal for details
+ */
+func (s *Session) Explain(root *TreeNode) (Value, map[*TreeNode]Value, error) {
+	if decimal := s.Decimal(); decimal != nil {
+		evaluate := func(n *TreeNode) (Value, error) {
+			return InterpretDecimal(n, *decimal)
+		}
+		res, err := evaluate(root)
+		if err != nil {
+			return Value{}, nil, err
+		}
+		return res, IntermediateValues(root, evaluate), nil
+	}
+	values := make(map[*TreeNode]Value)
+	res, err := s.evaluate(root, func(n *TreeNode, x Value) {
+		values[n] = x
+	})
+	if err != nil {
+		return Value{}, nil, err
+	}
+	return res, values, nil
+}
+
+/**
+ * evaluate: calculates the result of the expression in the standard mode within the session
+ *
+ * @param root Pointer to the AST node being evaluated
+ * @param record records values of the subtrees evaluated in the outermost scope, can be nil
+ * @return Value result of the whole expression, its Seed is set if random numbers were used
+ * @return error if there was an error when evaluating the AST - see standardBackend for details
+ */
+func (s *Session) evaluate(root *TreeNode, record func(*TreeNode, Value)) (Value, error) {
+	random := &randomState{session: s}
+	res, err := InterpretBackend[Value](root, standardBackend{&scope{random: random, record: record}})
+	if err != nil {
+		return Value{}, err
+	}
+	if random.generator != nil {
+		seed := random.seed
+		res.Seed = &seed
+	}
+	return res, nil
+}
+
+/**
+ * randomState: generator of random numbers of a single calculation
+ */
+type randomState struct {
+	session   *Session
+	generator *rand.Rand // nil until the calculation uses a random number
+	seed      int64      // seed of the generator
+}
+
+/**
+ * source: returns the generator of the calculation, it's created on the first use
+ *
+ * @return *rand.Rand the generator
+ */
+func (r *randomState) source() *rand.Rand {
+	if r.generator == nil {
+		r.seed = r.session.nextSeed()
+		r.generator = rand.New(rand.NewSource(r.seed))
+	}
+	return r.generator
+}
+
+/**
+ * randomOf: finds the random number generator of the calculation the scope belongs to
+ *
+ * @return *randomState the generator, calculations outside of a session use the default session
+ */
+func (sc *scope) randomOf() *randomState {
+	for ; sc != nil; sc = sc.parent {
+		if sc.random != nil {
+			return sc.random
+		}
+	}
+	return &randomState{session: defaultSession}
+}
+
+/**
+ * randomBuiltin: function using random numbers, which can be called from an expression by its name
+ */
+type randomBuiltin struct {
+	minArgs int
+	maxArgs int
+	call    func(r *randomState, args []Value) (Value, error)
+}
+
+// functions using random numbers of the calculation
+var randomBuiltins = map[string]randomBuiltin{
+	"rand":    {0, 0, randomUniform},
+	"randint": {2, 2, randomInteger},
+	"randn":   {0, 2, randomNormal},
+	"choice":  {1, 1, randomChoice},
+	"seed":    {1, 1, setSeed},
+}
+
+/**
+ * lookupBuiltin: finds a function by its name, functions using random numbers are bound to the generator of the scope
+ *
+ * @param name name of the function
+ * @param sc Pointer to the innermost scope of variables, can be nil
+ * @return builtin the function
+ * @return bool false if there's no such function
+ */
+func lookupBuiltin(name string, sc *scope) (builtin, bool) {
+	if f, ok := builtins[name]; ok {
+		return f, true
+	}
+	f, ok := randomBuiltins[name]
+	if !ok {
+		return builtin{}, false
+	}
+	random := sc.randomOf()
+	return builtin{f.minArgs, f.maxArgs, func(args []Value) (Value, error) {
+		return f.call(random, args)
+	}}, true
+}
+
+/**
+ * randomUniform: returns a random number from 0 up to 1, 1 excluded
+ *
+ * @param r generator of the calculation
+ * @param args no arguments
+ * @return Value the random number
+ * @return error never
+ */
+func randomUniform(r *randomState, args []Value) (Value, error) {
+	return NumberValue(r.source().Float64()), nil
+}
+
+/**
+ * randomInteger: returns a random integer from a to b, both included
+ *
+ * @param r generator of the calculation
+ * @param args a and b
+ * @return Value the random integer
+ * @return error if the bounds aren't integers or a is bigger than b
+ */
+func randomInteger(r *randomState, args []Value) (Value, error) {
+	numbers, err := numbersOf(args)
+	if err != nil {
+		return Value{}, err
+	}
+	if len(numbers) != 2 {
+		return Value{}, fmt.Errorf("expected 2 numbers, got %d", len(numbers))
+	}
+	a, b := numbers[0], numbers[1]
+	if a != math.Trunc(a) || b != math.Trunc(b) {
+		return Value{}, fmt.Errorf("bounds of randint have to be integers")
+	}
+	if a > b {
+		return Value{}, fmt.Errorf("lower bound of randint is bigger than the upper one")
+	}
+	if b-a >= math.MaxInt64 {
+		return Value{}, fmt.Errorf("range of randint is too big")
+	}
+	return NumberValue(a + float64(r.source().Int63n(int64(b-a)+1))), nil
+}
+
+/**
+ * randomNormal: returns a random number from the normal distribution, randn() uses the standard normal distribution
+ *
+ * @param r generator of the calculation
+ * @param args the mean and the standard deviation, or nothing
+ * @return Value the random number
+ * @return error if only one argument is given or the standard deviation is negative
+ */
+func randomNormal(r *randomState, args []Value) (Value, error) {
+	numbers, err := numbersOf(args)
+	if err != nil {
+		return Value{}, err
+	}
+	mu, sigma := 0.0, 1.0
+	switch len(numbers) {
+	case 0:
+	case 2:
+		mu, sigma = numbers[0], numbers[1]
+	default:
+		return Value{}, fmt.Errorf("expected 0 or 2 numbers, got %d", len(numbers))
+	}
+	if sigma < 0 {
+		return Value{}, fmt.Errorf("standard deviation can't be negative")
+	}
+	return NumberValue(mu + sigma*r.source().NormFloat64()), nil
+}
+
+/**
+ * randomChoice: returns a random element of a list
+ *
+ * @param r generator of the calculation
+ * @param args the list
+ * @return Value the element
+ * @return error if the argument isn't a list or the list is empty
+ */
+func randomChoice(r *randomState, args []Value) (Value, error) {
+	if !args[0].IsList {
+		return Value{}, fmt.Errorf("choice takes a list")
+	}
+	if len(args[0].List) == 0 {
+		return Value{}, fmt.Errorf("cannot choose from an empty list")
+	}
+	return NumberValue(args[0].List[r.source().Intn(len(args[0].List))]), nil
+}
+
+/**
+ * setSeed: sets the seed of the session, random numbers after it in the same calculation use the seed too
+ *
+ * The seed can't be set after a random number was drawn, the seed reported with the result couldn't replay
+ * the calculation then.
+ *
+ * @param r generator of the calculation
+ * @param args the seed
+ * @return Value the seed
+ * @return error if the seed isn't an integer or a random number was already drawn
+ */
+func setSeed(r *randomState, args []Value) (Value, error) {
+	seed := args[0]
+	if seed.IsList || seed.Uncertainty != 0 || seed.Number != math.Trunc(seed.Number) || math.Abs(seed.Number) >= 1<<63 {
+		return Value{}, fmt.Errorf("seed has to be an integer")
+	}
+	if r.generator != nil {
+		return Value{}, fmt.Errorf("seed can't be set after a random number was drawn")
+	}
+	r.session.SetSeed(int64(seed.Number))
+	return seed, nil
+}
@@ -0,0 +1,283 @@
+package interpreter
+
+import (
+	"fmt"
+	"ivs-calculator/pkg/mathfunc"
+	"math"
+	"strings"
+)
+
+/**
+ * Precision: significant figures of a measured number
+ */
+type Precision struct {
+	SigFigs int // number of significant figures, 0 if no significant digit is left
+	Place   int // decimal place of the last significant digit, e.g. -2 for hundredths
+}
+
+/**
+ * literalPrecision: counts significant figures of a number literal as it was written
+ *
+ * Leading zeros are not significant, trailing zeros are significant only when the literal has a decimal point,
+ * e.g. 0.0250 has 3 significant figures, 1500 has 2 and 1500. has 4.
+ * Literals with a sign are created by the parser for unary operators, they are exact.
+ *
+ * @param literal string value of the number token
+ * @return *Precision precision of the literal, nil if the number is exact
+ */
+func literalPrecision(literal string) *Precision {
+	if literal == "" || strings.HasPrefix(literal, "-") || strings.HasPrefix(literal, "+") {
+		return nil
+	}
+	integer, fraction := literal, ""
+	if dot := strings.Index(literal, "."); dot != -1 {
+		integer, fraction = literal[:dot], literal[dot+1:]
+		digits := strings.TrimLeft(integer+fraction, "0")
+		return &Precision{SigFigs: len(digits), Place: -len(fraction)}
+	}
+	digits := strings.TrimLeft(integer, "0")
+	significant := strings.TrimRight(digits, "0")
+	if significant == "" {
+		return &Precision{SigFigs: 0, Place: 0}
+	}
+	return &Precision{SigFigs: len(significant), Place: len(digits) - len(significant)}
+}
+
+/**
+ * InterpretSigFigs: calculates the result of the expression while tracking significant figures
+ *
+ * Results of addition, subtraction and modulo are as precise as the least precise decimal place of their operands,
+ * results of multiplication and division have as many significant figures as the operand with the fewest of them.
+ * Powers, roots, absolute values and factorials keep the significant figures of their base.
+ * Numbers are not rounded until the result is formatted.
+ *
+ * @param root Pointer to the AST node being evaluated
+ * @return Value result of the expression with its precision
+ * @return error if the expression uses anything else than numbers and arithmetic operators or a calculation fails
+ */
+func InterpretSigFigs(root *TreeNode) (Value, error) {
+	return InterpretBackend[Value](root, SigFigBackend{})
+}
+
+/**
+ * SigFigBackend: floats with the significant figures of the measured numbers they were calculated from,
+ * the precision is nil for exact numbers
+ */
+type SigFigBackend struct{}
+
+/**
+ * Name: name of the mode used in error messages
+ *
+ * @return string significant figures
+ */
+func (SigFigBackend) Name() string {
+	return "significant figures"
+}
+
+/**
+ * Supports: whether the backend implements the operator
+ *
+ * @param op name of the operator
+ * @return bool true for arithmetic operators and comparisons
+ */
+func (SigFigBackend) Supports(op string) bool {
+	return arithmeticOperators[op]
+}
+
+/**
+ * Literal: returns the number literal with the significant figures it was written with
+ *
+ * @param literal string value of the number token
+ * @param x the literal parsed as a float
+ * @return Value the number with its precision
+ * @return error never
+ */
+func (SigFigBackend) Literal(literal string, x float64) (Value, error) {
+	return Value{Number: x, Precision: literalPrecision(literal)}, nil
+}
+
+/**
+ * Identifier: constants can't be used, their significant figures are unknown
+ *
+ * @param name the identifier
+ * @return Value zero value
+ * @return bool always false
+ */
+func (SigFigBackend) Identifier(name string) (Value, bool) {
+	return Value{}, false
+}
+
+/**
+ * Unary: applies abs, fac or dfac, the result keeps the precision of the operand
+ *
+ * @param op name of the operator
+ * @param x operand
+ * @return Value result of the operator with its precision
+ * @return error if the operator fails
+ */
+func (SigFigBackend) Unary(op string, x Value) (Value, error) {
+	res, err := applyOperator(op, x.Number, 0)
+	if err != nil {
+		return Value{}, err
+	}
+	return Value{Number: res, Precision: x.Precision}, nil
+}
+
+/**
+ * Binary: applies an operator with two operands and calculates the precision of the result
+ *
+ * @param op name of the operator
+ * @param a left operand
+ * @param b right operand
+ * @return Value result of the operator with its precision, comparisons are exact
+ * @return error if the operator fails
+ */
+func (SigFigBackend) Binary(op string, a, b Value) (Value, error) {
+	res, err := applyOperator(op, a.Number, b.Number)
+	if err != nil {
+		return Value{}, err
+	}
+
+	var precision *Precision
+	switch op {
+	case "+", "-", "mod":
+		precision = leastPrecisePlace(res, a.Precision, b.Precision)
+	case "*", "/":
+		precision = fewestSigFigs(res, a.Precision, b.Precision)
+	case "pow", "root":
+		precision = fewestSigFigs(res, a.Precision, nil)
+	}
+	return Value{Number: res, Precision: precision}, nil
+}
+
+/**
+ * EvaluateNode: evaluates multiplication by 1, or by +1 or -1 of a unary sign, which doesn't change the precision
+ * of the other operand, the other nodes are left to InterpretBackend
+ *
+ * @param node Pointer to the operator node
+ * @return Value the product with the precision of the other operand
+ * @return bool false if the node isn't such a multiplication
+ * @return error if the other operand can't be evaluated
+ */
+func (b SigFigBackend) EvaluateNode(node *TreeNode) (Value, bool, error) {
+	if node.token.stringValue != "*" || node.leftNode == nil || node.rightNode == nil {
+		return Value{}, false, nil
+	}
+	operand, unit := node.leftNode, node.rightNode
+	if !isUnitLiteral(unit) {
+		operand, unit = unit, operand
+	}
+	if !isUnitLiteral(unit) {
+		return Value{}, false, nil
+	}
+	x, err := InterpretBackend[Value](operand, b)
+	if err != nil {
+		return Value{}, true, err
+	}
+	return Value{Number: mathfunc.Multiply(x.Number, evalNumber(unit)), Precision: x.Precision}, true, nil
+}
+
+/**
+ * isUnitLiteral: checks whether the node is the literal 1, or +1 or -1 of a unary sign
+ *
+ * @param node Pointer to the checked node
+ * @return bool true if the node is 1, +1 or -1
+ */
+func isUnitLiteral(node *TreeNode) bool {
+	literal := node.token.stringValue
+	return node.token.tokenType == NUMBER && (literal == "1" || literal == "+1" || literal == "-1")
+}
+
+/**
+ * magnitude: returns decimal place of the highest digit of a non-zero number
+ *
+ * @param x the number
+ * @return int decimal place of the highest digit, e.g. 2 for 123.4
+ */
+func magnitude(x float64) int {
+	return int(math.Floor(math.Log10(math.Abs(x))))
+}
+
+/**
+ * leastPrecisePlace: precision of a result of addition or subtraction
+ *
+ * @param res the result
+ * @param a precision of the left operand, nil if exact
+ * @param b precision of the right operand, nil if exact
+ * @return *Precision precision of the result rounded to the least precise decimal place, nil if both are exact
+ */
+func leastPrecisePlace(res float64, a, b *Precision) *Precision {
+	if a == nil && b == nil {
+		return nil
+	}
+	place := math.MinInt32
+	if a != nil {
+		place = a.Place
+	}
+	if b != nil && b.Place > place {
+		place = b.Place
+	}
+	sigFigs := 0
+	if res != 0 && magnitude(res) >= place {
+		sigFigs = magnitude(res) - place + 1
+	}
+	return &Precision{SigFigs: sigFigs, Place: place}
+}
+
+/**
+ * fewestSigFigs: precision of a result of multiplication or division
+ *
+ * @param res the result
+ * @param a precision of the left operand, nil if exact
+ * @param b precision of the right operand, nil if exact
+ * @return *Precision precision of the result with the fewest significant figures, nil if both are exact
+ */
+func fewestSigFigs(res float64, a, b *Precision) *Precision {
+	if a == nil && b == nil {
+		return nil
+	}
+	if a == nil || b != nil && b.SigFigs < a.SigFigs {
+		a, b = b, a
+	}
+	if res == 0 || a.SigFigs == 0 {
+		place := a.Place
+		if b != nil && b.Place > place {
+			place = b.Place
+		}
+		return &Precision{SigFigs: 0, Place: place}
+	}
+	return &Precision{SigFigs: a.SigFigs, Place: magnitude(res) - a.SigFigs + 1}
+}
+
+/**
+ * formatSigFigs: formats number rounded to its significant figures, e.g. 2.50 or 1.5e+03
+ *
+ * Trailing zeros are kept when they are significant. Numbers whose last significant digit is left of the decimal point
+ * are written in exponential form, so that no insignificant zero is shown.
+ *
+ * @param x the number
+ * @param p precision of the number
+ * @return string formatted number
+ */
+func formatSigFigs(x float64, p Precision) string {
+	if math.IsNaN(x) || math.IsInf(x, 0) {
+		return fmt.Sprintf("%g", x)
+	}
+	if x == 0 || p.SigFigs <= 0 {
+		// no significant digit is left, only the decimal place is known
+		if p.Place > 0 {
+			return "0"
+		}
+		return fmt.Sprintf("%.*f", -p.Place, 0.0)
+	}
+
+	place := magnitude(x) - p.SigFigs + 1
+	rounded := math.Round(x/math.Pow10(place)) * math.Pow10(place)
+	if magnitude(rounded) > magnitude(x) { // rounded up to the next digit, e.g. 9.96 to 10.0
+		place++
+	}
+	if place > 0 || place == 0 && math.Mod(rounded, 10) == 0 {
+		return fmt.Sprintf("%.*e", p.SigFigs-1, rounded)
+	}
+	return fmt.Sprintf("%.*f", -place, rounded)
+}
@@ -1,22 +1,74 @@
 package interpreter
 
+import "fmt"
+
+/**
+ * TokenType: type of a token, either an operator or a number
+ *
+ * Identifiers and function names are operators too, see TreeNode.Kind for the kind of a node.
+ */
+type TokenType int
+
 /**
  * Constants to define type of a token
  */
 const (
-	OPERATOR = iota
+	OPERATOR TokenType = iota
 	NUMBER
 )
 
+/**
+ * String: returns the name of the token type
+ *
+ * @return string operator or number
+ */
+func (t TokenType) String() string {
+	switch t {
+	case OPERATOR:
+		return "operator"
+	case NUMBER:
+		return "number"
+	}
+	return fmt.Sprintf("TokenType(%d)", int(t))
+}
+
 /**
  * Token: Data structure for tokens
  */
 type Token struct {
-	tokenType   int
+	tokenType   TokenType
 	stringValue string
 	floatValue  float64
 }
 
+/**
+ * Type: returns the type of the token
+ *
+ * @return TokenType OPERATOR or NUMBER
+ */
+func (t Token) Type() TokenType {
+	return t.tokenType
+}
+
+/**
+ * Text: returns the string value of the token, which is the name of an operator, identifier or function,
+ * or the literal of a number as it was parsed, e.g. 0.5 for 0,5 in the cs_CZ locale
+ *
+ * @return string the string value, empty for numbers created without a literal
+ */
+func (t Token) Text() string {
+	return t.stringValue
+}
+
+/**
+ * Value: returns the float value of a number token
+ *
+ * @return float64 the number, 0 for operators
+ */
+func (t Token) Value() float64 {
+	return t.floatValue
+}
+
 /**
  * TreeNode: Data structure for nodes of the tree
  */
@@ -24,6 +76,7 @@ type TreeNode struct {
 	token     Token
 	leftNode  *TreeNode
 	rightNode *TreeNode
+	span      Span // position of the node in the parsed expression, zero for nodes created outside of the parser
 }
 
 /**
@@ -60,7 +113,7 @@ func NewNode(t *Token) *TreeNode {
  * @param flVal Float value of the token
  * @return *Token Pointer to the new token
  */
-func NewToken(tType int, strVal string, flVal float64) *Token {
+func NewToken(tType TokenType, strVal string, flVal float64) *Token {
 	t := &Token{tokenType: tType, stringValue: strVal, floatValue: flVal}
 	return t
 }
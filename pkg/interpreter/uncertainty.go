@@ -0,0 +1,181 @@
+package interpreter
+
+import (
+	"fmt"
+	"ivs-calculator/pkg/mathfunc"
+	"math"
+)
+
+/**
+ * applyUncertainUnary: applies one operand operator on an uncertain number
+ *
+ * Uncertainty is propagated to the first order, meaning it is multiplied by the absolute value
+ * of the derivative of the operator.
+ *
+ * @param op name of the operator
+ * @param v uncertain operand
+ * @return Value result of the operator with its uncertainty
+ * @return error if the operator or its derivative fails
+ */
+func applyUncertainUnary(op string, v Value) (Value, error) {
+	res, err := applyOperator(op, v.Number, 0)
+	if err != nil {
+		return Value{}, err
+	}
+	derivative := 1.0
+	switch op {
+	case "abs":
+	case "fac":
+		// (x!)' = x! * digamma(x+1)
+		digamma, err := mathfunc.Digamma(v.Number + 1)
+		if err != nil {
+			return Value{}, err
+		}
+		derivative = res * digamma
+	default:
+		return Value{}, fmt.Errorf("cannot propagate uncertainty through '%v'", op)
+	}
+	return Value{Number: res, Uncertainty: math.Abs(derivative * v.Uncertainty)}, nil
+}
+
+/**
+ * applyUncertainBinary: applies two operand operator on numbers, at least one of them uncertain
+ *
+ * Operator ± creates an uncertain number from its left operand and the uncertainty in its right operand.
+ * Other operators propagate the uncertainties to the first order, assuming the operands are independent:
+ * σ_f = √((∂f/∂a σ_a)² + (∂f/∂b σ_b)²)
+ *
+ * @param op name of the operator
+ * @param left left operand
+ * @param right right operand
+ * @return Value result of the operator with its uncertainty
+ * @return error if the operator fails or the uncertainty can't be propagated
+ */
+func applyUncertainBinary(op string, left, right Value) (Value, error) {
+	if op == "±" {
+		if right.Uncertainty != 0 {
+			return Value{}, fmt.Errorf("uncertainty can't be uncertain")
+		}
+		return Value{Number: left.Number, Uncertainty: math.Hypot(left.Uncertainty, right.Number)}, nil
+	}
+
+	res, err := applyOperator(op, left.Number, right.Number)
+	if err != nil {
+		return Value{}, err
+	}
+	da, db, err := partialDerivatives(op, left.Number, right.Number, res, right.Uncertainty != 0)
+	if err != nil {
+		return Value{}, err
+	}
+	uncertainty := math.Hypot(da*left.Uncertainty, db*right.Uncertainty)
+	if math.IsNaN(uncertainty) || math.IsInf(uncertainty, 0) {
+		return Value{}, fmt.Errorf("cannot propagate uncertainty through '%v'", op)
+	}
+	return Value{Number: res, Uncertainty: uncertainty}, nil
+}
+
+/**
+ * partialDerivatives: returns partial derivatives of a two operand operator
+ *
+ * @param op name of the operator
+ * @param a left operand
+ * @param b right operand
+ * @param f result of the operator
+ * @param needB whether the derivative by b is needed, meaning b is uncertain
+ * @return float64 partial derivative by the left operand
+ * @return float64 partial derivative by the right operand
+ * @return error if the operator isn't differentiable in the point
+ */
+func partialDerivatives(op string, a, b, f float64, needB bool) (float64, float64, error) {
+	switch op {
+	case "+":
+		return 1, 1, nil
+	case "-":
+		return 1, -1, nil
+	case "*":
+		return b, a, nil
+	case "/":
+		return 1 / b, -a / (b * b), nil
+	case "mod":
+		// a mod b = a - b*q, where q is constant almost everywhere
+		return 1, -(a - f) / b, nil
+	case "pow":
+		// (a^b)' = b*a^(b-1) by a and a^b*ln(a) by b
+		db := 0.0
+		if needB {
+			if a <= 0 {
+				return 0, 0, fmt.Errorf("cannot propagate uncertainty of an exponent of a non-positive base")
+			}
+			db = f * math.Log(a)
+		}
+		return b * math.Pow(a, b-1), db, nil
+	case "root":
+		// a is the radicand, b is the degree: (a^(1/b))' = a^(1/b)/(b*a) by a and -a^(1/b)*ln(a)/b² by b
+		if a == 0 {
+			return 0, 0, fmt.Errorf("cannot propagate uncertainty of a root of zero")
+		}
+		db := 0.0
+		if needB {
+			db = -f * math.Log(math.Abs(a)) / (b * b)
+		}
+		return f / (b * a), db, nil
+	case "<", ">":
+		return 0, 0, nil
+	default:
+		return 0, 0, fmt.Errorf("cannot propagate uncertainty through '%v'", op)
+	}
+}
+
+/**
+ * formatUncertain: formats number with its uncertainty, e.g. 12.3 ± 0.4
+ *
+ * Uncertainty is rounded to two significant digits if its three highest digits are between 100 and 354,
+ * to one significant digit if they are between 355 and 949, and if they are between 950 and 999,
+ * it is rounded up to 1000 and kept with two significant digits. The number is rounded to the same decimal place.
+ *
+ * @param x the number
+ * @param sigma uncertainty of the number
+ * @return string formatted number
+ */
+func formatUncertain(x, sigma float64) string {
+	sigma = math.Abs(sigma)
+	if math.IsNaN(x) || math.IsInf(x, 0) || math.IsInf(sigma, 0) {
+		return fmt.Sprintf("%g ± %g", x, sigma)
+	}
+
+	exp := int(math.Floor(math.Log10(sigma))) // position of the highest digit
+	lead := math.Round(sigma / math.Pow10(exp-2))
+	digits := 2
+	if lead >= 950 { // rounded up to the next digit
+		exp++
+	} else if lead >= 355 {
+		digits = 1
+	}
+	place := exp - digits + 1 // position of the last kept digit
+
+	// very large and very small numbers are written with a common power of ten
+	shift := 0
+	if place > 6 || place < -8 {
+		shift = exp
+		if math.Abs(x) > sigma {
+			shift = int(math.Floor(math.Log10(math.Abs(x))))
+		}
+		place -= shift
+		x /= math.Pow10(shift)
+		sigma /= math.Pow10(shift)
+	}
+
+	decimals := 0
+	if place < 0 {
+		decimals = -place
+	}
+	unit := math.Pow10(place)
+	xRounded := math.Round(x/unit) * unit
+	sigmaRounded := math.Round(sigma/unit) * unit
+
+	res := fmt.Sprintf("%.*f ± %.*f", decimals, xRounded, decimals, sigmaRounded)
+	if shift != 0 {
+		return fmt.Sprintf("(%s)e%+d", res, shift)
+	}
+	return res
+}
@@ -0,0 +1,412 @@
+package interpreter
+
+import (
+	"errors"
+	"fmt"
+	"ivs-calculator/pkg/format"
+	"ivs-calculator/pkg/mathfunc"
+	"strings"
+	"unicode/utf8"
+)
+
+/**
+ * Value: result of an expression, either a number or a list of numbers
+ *
+ * Numbers can have a standard uncertainty, lists can't.
+ * In the significant figures mode numbers carry their precision, in the interval mode their bounds.
+ * Factorials too big for a float64 value are kept in the form of mantissa and exponent.
+ * In the modular mode numbers are exact integers reduced modulo the modulus, in the decimal mode exact decimals.
+ * Some functions return a table, which can only be shown, the same as an inspection of how a number is stored.
+ */
+type Value struct {
+	Number      float64
+	Uncertainty float64
+	List        []float64
+	IsList      bool
+	Precision   *Precision            // nil unless significant figures are tracked
+	Interval    *mathfunc.Interval    // nil unless evaluated in the interval mode
+	Large       *mathfunc.LargeNumber // factorial too big for Number, it can't be used in further calculations
+	Residue     *Residue              // nil unless evaluated in the modular mode
+	Decimal     *mathfunc.Decimal     // nil unless evaluated in the decimal mode
+	Seed        *int64                // seed of the random numbers used by the calculation, nil if it used none
+	Table       *Table                // nil unless the result is a table
+	Inspection  *format.Inspection    // nil unless the result is an inspection of a number
+}
+
+/**
+ * Table: result of a function with named columns of numbers, e.g. an amortization schedule
+ */
+type Table struct {
+	Columns []string
+	Rows    [][]float64
+}
+
+/**
+ * String: formats the table with its header and a row per line, columns are aligned to the right
+ *
+ * @return string formatted table
+ */
+func (t Table) String() string {
+	return t.formatCells(func(x float64) string {
+		return fmt.Sprintf("%.10g", x)
+	})
+}
+
+/**
+ * Format: formats the table like String, numbers are formatted according to the options
+ *
+ * @param opts settings of formatting numbers
+ * @return string formatted table
+ */
+func (t Table) Format(opts format.Options) string {
+	return t.formatCells(func(x float64) string {
+		return format.Number(x, opts)
+	})
+}
+
+/**
+ * formatCells: formats the table with its header and a row per line, columns are aligned to the right
+ *
+ * @param number function formatting the numbers in the table
+ * @return string formatted table
+ */
+func (t Table) formatCells(number func(x float64) string) string {
+	cells := make([][]string, 0, len(t.Rows)+1)
+	cells = append(cells, t.Columns)
+	for _, row := range t.Rows {
+		line := make([]string, len(row))
+		for i, x := range row {
+			line[i] = number(x)
+		}
+		cells = append(cells, line)
+	}
+	widths := make([]int, len(t.Columns))
+	for _, line := range cells {
+		for i, cell := range line {
+			if n := utf8.RuneCountInString(cell); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+	lines := make([]string, len(cells))
+	for i, line := range cells {
+		padded := make([]string, len(line))
+		for j, cell := range line {
+			padded[j] = strings.Repeat(" ", widths[j]-utf8.RuneCountInString(cell)) + cell
+		}
+		lines[i] = strings.Join(padded, "  ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+/**
+ * NumberValue: creates a value holding a single number
+ *
+ * @param x the number
+ * @return Value holding the number
+ */
+func NumberValue(x float64) Value {
+	return Value{Number: x}
+}
+
+/**
+ * ListValue: creates a value holding a list of numbers
+ *
+ * @param list elements of the list
+ * @return Value holding the list
+ */
+func ListValue(list []float64) Value {
+	return Value{List: list, IsList: true}
+}
+
+/**
+ * uncertainNumber: number with its standard uncertainty, the payload of uncertain values
+ */
+type uncertainNumber struct {
+	number      float64
+	uncertainty float64
+}
+
+/**
+ * String: formats the number with its rounded uncertainty, e.g. 12.3 ± 0.4
+ */
+func (u uncertainNumber) String() string {
+	return formatUncertain(u.number, u.uncertainty)
+}
+
+/**
+ * sigFigNumber: number with its significant figures, the payload of values of the significant figures mode
+ */
+type sigFigNumber struct {
+	number    float64
+	precision Precision
+}
+
+/**
+ * String: formats the number rounded to its significant figures, e.g. 2.50
+ */
+func (n sigFigNumber) String() string {
+	return formatSigFigs(n.number, n.precision)
+}
+
+/**
+ * intervalNumber: bounds of a number, the payload of values of the interval mode
+ */
+type intervalNumber mathfunc.Interval
+
+/**
+ * String: formats the interval with its bounds, e.g. [9.9, 10.1]
+ */
+func (i intervalNumber) String() string {
+	return formatInterval(mathfunc.Interval(i))
+}
+
+/**
+ * payload: returns the part of the value, which decides how it's written, nil for plain numbers and lists
+ *
+ * The fields are checked in this order: table, inspection, uncertainty, large factorial, residue, decimal, interval
+ * and significant figures, only the first one set is used.
+ *
+ * @return fmt.Stringer the payload, which formats the value
+ */
+func (v Value) payload() fmt.Stringer {
+	switch {
+	case v.Table != nil:
+		return v.Table
+	case v.Inspection != nil:
+		return v.Inspection
+	case v.IsList:
+		return nil
+	case v.Uncertainty != 0:
+		return uncertainNumber{v.Number, v.Uncertainty}
+	case v.Large != nil:
+		return v.Large
+	case v.Residue != nil:
+		return v.Residue
+	case v.Decimal != nil:
+		return v.Decimal
+	case v.Interval != nil:
+		return intervalNumber(*v.Interval)
+	case v.Precision != nil:
+		return sigFigNumber{v.Number, *v.Precision}
+	}
+	return nil
+}
+
+/**
+ * IsNumeric: checks whether the value is held by its Number or List, which is true for plain numbers, lists and decimals,
+ * so that it can be written in another form, e.g. as a fraction
+ *
+ * @return bool false for values of the other modes, tables and inspections
+ */
+func (v Value) IsNumeric() bool {
+	switch v.payload().(type) {
+	case nil, *mathfunc.Decimal:
+		return true
+	}
+	return false
+}
+
+/**
+ * String: formats the value, lists are written in braces, e.g. {1, 2, 3}, other values by their payload
+ *
+ * @return string formatted value
+ */
+func (v Value) String() string {
+	if p := v.payload(); p != nil {
+		return p.String()
+	}
+	if !v.IsList {
+		return fmt.Sprintf("%g", v.Number)
+	}
+	elements := make([]string, len(v.List))
+	for i, x := range v.List {
+		elements[i] = fmt.Sprintf("%g", x)
+	}
+	return "{" + strings.Join(elements, ", ") + "}"
+}
+
+/**
+ * Format: formats the value like String, plain numbers, elements of lists and numbers in tables are formatted
+ * according to the options, other numbers use the decimal mark of the locale
+ *
+ * @param opts settings of formatting numbers
+ * @return string formatted value
+ */
+func (v Value) Format(opts format.Options) string {
+	locale := opts.Locale
+	if locale.DecimalMark == 0 {
+		locale = format.EnUS
+	}
+	switch p := v.payload().(type) {
+	case nil:
+	case *Table:
+		return p.Format(opts)
+	case *format.Inspection:
+		return p.String()
+	default:
+		// these are written with decimal points and separated by commas
+		return strings.Map(func(r rune) rune {
+			switch r {
+			case '.':
+				return locale.DecimalMark
+			case ',':
+				return locale.ArgumentSeparator
+			}
+			return r
+		}, p.String())
+	}
+	if !v.IsList {
+		return format.Number(v.Number, opts)
+	}
+	elements := make([]string, len(v.List))
+	for i, x := range v.List {
+		elements[i] = format.Number(x, opts)
+	}
+	return "{" + strings.Join(elements, string(locale.ArgumentSeparator)+" ") + "}"
+}
+
+/**
+ * applyUnary: applies one operand operator on a value
+ *
+ * Operators on lists are applied on each of their elements.
+ *
+ * @param op name of the operator
+ * @param v operand
+ * @return Value result of the operator
+ * @return error if the operator fails on any of the elements
+ */
+func applyUnary(op string, v Value) (Value, error) {
+	if err := checkOperand(v); err != nil {
+		return Value{}, err
+	}
+	if v.Uncertainty != 0 {
+		return applyUncertainUnary(op, v)
+	}
+	if !v.IsList {
+		res, err := applyOperator(op, v.Number, 0)
+		if err != nil {
+			// factorials too big for a float are calculated in the logarithmic scale
+			large, largeErr := largeFactorial(op, v.Number)
+			if largeErr == nil {
+				return Value{Large: &large}, nil
+			}
+			if errors.Is(largeErr, mathfunc.ErrExponentTooBig) {
+				return Value{}, largeErr
+			}
+			return Value{}, err
+		}
+		return NumberValue(res), nil
+	}
+	res := make([]float64, len(v.List))
+	for i, x := range v.List {
+		var err error
+		res[i], err = applyOperator(op, x, 0)
+		if err != nil {
+			return Value{}, err
+		}
+	}
+	return ListValue(res), nil
+}
+
+/**
+ * applyBinary: applies two operand operator on values
+ *
+ * Operators on two lists are applied element-wise, so the lists must have the same length.
+ * Operator on a list and a number is applied on each element of the list and the number.
+ *
+ * @param op name of the operator
+ * @param left left operand
+ * @param right right operand
+ * @return Value result of the operator
+ * @return error if the lists have different lengths or the operator fails on any of the elements
+ */
+func applyBinary(op string, left, right Value) (Value, error) {
+	if err := checkOperand(left); err != nil {
+		return Value{}, err
+	}
+	if err := checkOperand(right); err != nil {
+		return Value{}, err
+	}
+	if left.Uncertainty != 0 || right.Uncertainty != 0 || op == "±" {
+		if left.IsList || right.IsList {
+			return Value{}, fmt.Errorf("lists can't have an uncertainty")
+		}
+		return applyUncertainBinary(op, left, right)
+	}
+	if !left.IsList && !right.IsList {
+		res, err := applyOperator(op, left.Number, right.Number)
+		if err != nil {
+			return Value{}, err
+		}
+		return NumberValue(res), nil
+	}
+
+	length := len(left.List)
+	if !left.IsList {
+		length = len(right.List)
+	} else if right.IsList && len(right.List) != length {
+		return Value{}, fmt.Errorf("lists have different lengths: %d and %d", len(left.List), len(right.List))
+	}
+
+	res := make([]float64, length)
+	for i := range res {
+		a, b := left.Number, right.Number
+		if left.IsList {
+			a = left.List[i]
+		}
+		if right.IsList {
+			b = right.List[i]
+		}
+		var err error
+		res[i], err = applyOperator(op, a, b)
+		if err != nil {
+			return Value{}, err
+		}
+	}
+	return ListValue(res), nil
+}
+
+/**
+ * checkOperand: checks that the value can be used in a calculation
+ *
+ * @param v the value
+ * @return error if the value is too big for a float64 value, is a table or an inspection
+ */
+func checkOperand(v Value) error {
+	switch v.payload().(type) {
+	case *Table:
+		return fmt.Errorf("tables can't be used in calculations")
+	case *format.Inspection:
+		return fmt.Errorf("inspections can't be used in calculations")
+	case *mathfunc.LargeNumber:
+		return fmt.Errorf("%v is too big to calculate with", v)
+	}
+	return nil
+}
+
+/**
+ * largeFactorial: calculates factorial or double factorial of a number, whose result is too big for a float64 value
+ *
+ * @param op fac or dfac
+ * @param x the number
+ * @return mathfunc.LargeNumber the factorial
+ * @return error if the operator isn't a factorial, the factorial isn't defined for the number, fits into a float64 value
+ * or its exponent doesn't fit into an int64 value
+ */
+func largeFactorial(op string, x float64) (mathfunc.LargeNumber, error) {
+	var res mathfunc.LargeNumber
+	var err error
+	switch op {
+	case "fac":
+		res, err = mathfunc.LargeFactorial(x)
+	case "dfac":
+		res, err = mathfunc.LargeDoubleFactorial(x)
+	default:
+		return res, fmt.Errorf("'%v' isn't a factorial", op)
+	}
+	if err == nil && res.Exponent <= 308 {
+		return res, fmt.Errorf("%v! isn't too big", x)
+	}
+	return res, err
+}
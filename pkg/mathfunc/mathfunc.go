@@ -2,6 +2,7 @@ package mathfunc
 
 import (
 	"errors"
+	"math"
 )
 
 /**
@@ -72,22 +73,51 @@ func Modulo(a, b float64) (float64, error) {
 }
 
 /**
- * Factorial: divides two 64-bit floats.
- * Works only on natural numbers. Decimals are rounded, negative numbers return an error.
- * @param a float value (internally converted to integer)
+ * Factorial: returns factorial of a 64-bit float.
+ * Natural numbers are multiplied, other numbers use the gamma function: a! = Γ(a+1), e.g. 0.5! = √π/2.
+ * Negative integers and results too big for a float64 value return an error.
+ * @param a float value
  */
 func Factorial(a float64) (float64, error) {
-	if a < 0 {
-		return 0, errors.New("cannot calculate factorial of negative numbers")
+	if a < 0 && a == math.Trunc(a) {
+		return 0, errors.New("cannot calculate factorial of negative integers")
+	}
+	if a != math.Trunc(a) {
+		res := math.Gamma(a + 1)
+		if math.IsInf(res, 0) {
+			return 0, errors.New("factorial too big")
+		}
+		return res, nil
 	}
-	var output uint64 = 1
-	var i uint64 = 1
-	for i = 1; i <= uint64(a); i++ {
-		previous := output
+	output := 1.0
+	for i := 2.0; i <= a; i++ {
 		output *= i
-		if previous > output {
+		if math.IsInf(output, 0) {
 			return 0, errors.New("factorial too big")
 		}
 	}
-	return float64(output), nil
+	return output, nil
+}
+
+/**
+ * DoubleFactorial: returns double factorial of a 64-bit float, the product of the numbers from a down to 1 with step 2,
+ * e.g. 7!! = 7*5*3*1. 0!! and (-1)!! are 1.
+ * Works only on integers from -1 up. Returns error if the result is too big for a float64 value.
+ * @param a float value
+ */
+func DoubleFactorial(a float64) (float64, error) {
+	if a != math.Trunc(a) {
+		return 0, errors.New("double factorial works only with integers")
+	}
+	if a < -1 {
+		return 0, errors.New("cannot calculate double factorial of integers less than -1")
+	}
+	output := 1.0
+	for i := a; i > 1; i -= 2 {
+		output *= i
+		if math.IsInf(output, 0) {
+			return 0, errors.New("double factorial too big")
+		}
+	}
+	return output, nil
 }
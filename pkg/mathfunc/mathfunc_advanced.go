@@ -8,71 +8,157 @@ import (
 /**
  * Power: returns base raised to the power of exp as a float64 value
  *
- * Only uses natural numbers (including 0) as the exponent. If epx is not an integer, it is floored, negative values of exp return an error.
- * Base can be any float64 value.
+ * Integer exponents, including negative ones, are calculated using exponentiation by squaring and work with any base.
+ * Fractional exponents need a non-negative base, since the result of a negative base wouldn't be a real number.
+ * Returns error if the result is too big or too small to be represented by a float64 value.
  *
  * @param base float value used as the base of the exponentiation
- * @param exp float value used as the exponent (internally converted to integer)
+ * @param exp float value used as the exponent
  */
 func Power(base float64, exponent float64) (float64, error) {
-	exp := int(exponent) // exponent has to be a natural number - including 0
-	if exp < 0 {
-		return 0, fmt.Errorf("invalid exponent: '%d', has to be >= 0", exp)
+	if math.IsNaN(base) || math.IsNaN(exponent) {
+		return 0, fmt.Errorf("invalid power: %.3f^%g", base, exponent)
 	}
-	if exp == 0 && base == 0 {
-		return 0, fmt.Errorf("0^0 is undefined")
+	if base == 0 {
+		if exponent == 0 {
+			return 0, fmt.Errorf("0^0 is undefined")
+		} else if exponent < 0 {
+			return 0, fmt.Errorf("cannot raise 0 to a negative power")
+		}
+		return 0, nil
 	}
 
-	var res float64 = 1
-	for i := 0; i < exp; i++ {
-		res *= base
-		if math.IsInf(res, 0) {
-			return 0, fmt.Errorf("result of %.3f^%d is too big", base, exp)
+	var res float64
+	if exponent == math.Trunc(exponent) {
+		res = integerPower(base, exponent)
+	} else {
+		if base < 0 {
+			return 0, fmt.Errorf("cannot raise a negative number to a fractional power: %.3f^%g", base, exponent)
 		}
+		res = math.Pow(base, exponent)
+	}
+
+	if math.IsInf(res, 0) {
+		return 0, fmt.Errorf("result of %.3f^%g is too big", base, exponent)
+	}
+	if res == 0 {
+		return 0, fmt.Errorf("result of %.3f^%g is too small", base, exponent)
 	}
 	return res, nil
 }
 
+/**
+ * integerPower: returns base raised to an integer power using exponentiation by squaring
+ *
+ * Negative exponents are calculated as the reciprocal of the positive power. If the positive power overflows
+ * or the exponent is too large for squaring, the result is calculated from logarithms instead.
+ *
+ * @param base non-zero float value
+ * @param exponent integer float value
+ */
+func integerPower(base float64, exponent float64) float64 {
+	if math.Abs(exponent) >= 1<<62 {
+		return math.Pow(base, exponent)
+	}
+	n := int64(math.Abs(exponent))
+	res, x := 1.0, base
+	for ; n > 0; n >>= 1 {
+		if n&1 == 1 {
+			res *= x
+		}
+		if n > 1 {
+			x *= x
+		}
+	}
+	if exponent < 0 {
+		if math.IsInf(res, 0) || res == 0 {
+			// the reciprocal may still be representable
+			return math.Pow(base, exponent)
+		}
+		return 1 / res
+	}
+	return res
+}
+
+// Newton's method for roots stops when two approximations differ by less than this relative amount
+const rootTolerance = 4 * 0x1p-52
+
+// maximal number of iterations of Newton's method for roots
+const maxRootIterations = 100
+
 /**
  * Root: returns the nth root of x as a float64 value
  *
- * Only works with natural values of n. Decimals are floored, negative numbers and 0 return an error.
- * x can be any float64 value.
+ * Works with any positive real degree n, 0 and negative degrees return an error.
+ * Negative x only has a real root for odd integer degrees, the root is then negative.
  *
- * Uses Newton's method to calculate the principal root. Stops the calculation when two subsequent approximations are closer than 10^-10.
- * Meaning that the absolute value of their difference is less than 10^-10.
+ * Uses Newton's method started from an estimate calculated by logarithms. Stops the calculation when two subsequent
+ * approximations differ by less than a few units in the last place of the result, or after 100 iterations.
  *
  * @param x float value used as the radicand
- * @param n float value used as the degree of the root (internally converted to integer)
+ * @param n float value used as the degree of the root
  */
 func Root(x float64, n float64) (float64, error) {
-	degree := float64(int(n))
-
-	if degree == 0 {
+	if n == 0 {
 		return 0, fmt.Errorf("can't calculate 0th root")
-	} else if degree < 0 {
-		return 0, fmt.Errorf("can't calculate root of a negative degree: %d", int(degree))
+	} else if n < 0 {
+		return 0, fmt.Errorf("can't calculate root of a negative degree: %g", n)
 	}
 
-	// if degree is even and x < 0
-	if x < 0 && int(degree)%2 == 0 {
-		return 0, fmt.Errorf("can't calculate root %d of a negative number: %.3f", int(degree), x)
+	// only odd integer degrees have real roots of negative numbers
+	if x < 0 && (n != math.Trunc(n) || math.Mod(n, 2) == 0) {
+		return 0, fmt.Errorf("can't calculate root %g of a negative number: %.3f", n, x)
 	}
 
 	// handle special cases
-	if x == 0 || x == 1 || degree == 1 {
+	if x == 0 || x == 1 || n == 1 {
 		return x, nil
 	}
+	if x < 0 {
+		res, err := Root(-x, n)
+		return -res, err
+	}
 
-	res, old, tmpPow := 1.0, 0.0, 0.0
-	oneOverDeg, degMinOne := 1/degree, degree-1
-	eps := math.Pow10(-10)
-
-	for math.Abs(old-res) > eps {
-		old = res
-		tmpPow = math.Pow(res, degMinOne)
-		res = oneOverDeg * ((degMinOne * res) + (x / tmpPow))
+	res := math.Pow(x, 1/n)
+	if math.IsInf(res, 0) {
+		return 0, fmt.Errorf("result of root %g of %g is too big", n, x)
+	} else if res == 0 {
+		return 0, fmt.Errorf("result of root %g of %g is too small", n, x)
+	}
+	for i := 0; i < maxRootIterations; i++ {
+		next := ((n-1)*res + x/math.Pow(res, n-1)) / n
+		converged := math.Abs(next-res) <= rootTolerance*math.Abs(next)
+		res = next
+		if converged {
+			break
+		}
 	}
+	return res, nil
+}
 
+/**
+ * Digamma: returns the logarithmic derivative of the gamma function at x
+ *
+ * Uses the recurrence digamma(x) = digamma(x+1) - 1/x to shift x above 6 and then the asymptotic expansion.
+ * Non-positive integers are poles of the function and return an error.
+ *
+ * @param x float value
+ */
+func Digamma(x float64) (float64, error) {
+	if x <= 0 && x == math.Floor(x) {
+		return 0, fmt.Errorf("digamma is undefined for non-positive integers")
+	}
+	res := 0.0
+	// reflection formula for negative numbers
+	if x < 0 {
+		res -= math.Pi / math.Tan(math.Pi*x)
+		x = 1 - x
+	}
+	for x < 6 {
+		res -= 1 / x
+		x++
+	}
+	inv := 1 / (x * x)
+	res += math.Log(x) - 0.5/x - inv*(1.0/12-inv*(1.0/120-inv*(1.0/252-inv*(1.0/240-inv/132))))
 	return res, nil
 }
@@ -0,0 +1,481 @@
+package mathfunc
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+/**
+ * Decimal: exact decimal number Unscaled × 10^-Scale, e.g. 0.30 is 30 with the scale 2
+ *
+ * The scale is never negative. Results of addition, subtraction and multiplication keep all their digits,
+ * so 0.1 + 0.2 is exactly 0.3.
+ */
+type Decimal struct {
+	Unscaled *big.Int
+	Scale    int
+}
+
+/**
+ * DecimalRounding: how inexact decimal results are rounded to the scale of the context
+ */
+type DecimalRounding int
+
+const (
+	RoundHalfEven DecimalRounding = iota // ties to the even digit, e.g. 0.125 to 0.12
+	RoundHalfUp                          // ties away from zero, e.g. 0.125 to 0.13
+)
+
+/**
+ * DecimalContext: settings of decimal operations, whose results can't be written with finitely many digits
+ *
+ * Division, roots and negative or fractional powers are rounded to Scale fractional digits,
+ * trailing zeros of their results are removed.
+ */
+type DecimalContext struct {
+	Scale    int
+	Rounding DecimalRounding
+}
+
+// fractional digits of inexact results unless a frontend sets another scale, and the biggest allowed scale
+const (
+	DefaultDecimalScale = 10
+	MaxDecimalScale     = 1000
+)
+
+// limits keeping exact results of powers, factorials and roots reasonably small
+const (
+	maxDecimalPowerBits  = 1 << 16
+	maxDecimalExponent   = 10000
+	maxDecimalFactorial  = 5000
+	maxDecimalRootDegree = 100
+)
+
+var bigTen = big.NewInt(10)
+
+/**
+ * pow10: returns 10^n
+ * @param n non-negative exponent
+ */
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(bigTen, big.NewInt(int64(n)), nil)
+}
+
+/**
+ * ParseDecimal: converts a number literal to an exact decimal, e.g. 0.1, -2.50 or 1.5e3
+ * @param s the literal
+ */
+func ParseDecimal(s string) (Decimal, error) {
+	mantissa, exponent := s, 0
+	if i := strings.IndexAny(s, "eE"); i != -1 {
+		var err error
+		mantissa = s[:i]
+		exponent, err = strconv.Atoi(s[i+1:])
+		if err != nil || exponent > maxDecimalExponent || exponent < -maxDecimalExponent {
+			return Decimal{}, fmt.Errorf("%q isn't a decimal number", s)
+		}
+	}
+	sign := ""
+	if strings.HasPrefix(mantissa, "-") || strings.HasPrefix(mantissa, "+") {
+		sign, mantissa = mantissa[:1], mantissa[1:]
+	}
+	integer, fraction := mantissa, ""
+	if i := strings.IndexByte(mantissa, '.'); i != -1 {
+		integer, fraction = mantissa[:i], mantissa[i+1:]
+	}
+	if integer+fraction == "" || strings.ContainsAny(integer+fraction, "+-") {
+		return Decimal{}, fmt.Errorf("%q isn't a decimal number", s)
+	}
+	unscaled, ok := new(big.Int).SetString(sign+integer+fraction, 10)
+	if !ok {
+		return Decimal{}, fmt.Errorf("%q isn't a decimal number", s)
+	}
+	scale := len(fraction) - exponent
+	if scale < 0 {
+		return Decimal{unscaled.Mul(unscaled, pow10(-scale)), 0}, nil
+	}
+	return Decimal{unscaled, scale}, nil
+}
+
+/**
+ * String: writes the decimal with all digits of its scale, e.g. 0.30
+ */
+func (d Decimal) String() string {
+	digits := new(big.Int).Abs(d.Unscaled).String()
+	sign := ""
+	if d.Unscaled.Sign() < 0 {
+		sign = "-"
+	}
+	if d.Scale == 0 {
+		return sign + digits
+	}
+	if len(digits) <= d.Scale {
+		digits = strings.Repeat("0", d.Scale-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-d.Scale] + "." + digits[len(digits)-d.Scale:]
+}
+
+/**
+ * Rat: returns the decimal as an exact fraction
+ */
+func (d Decimal) Rat() *big.Rat {
+	return new(big.Rat).SetFrac(d.Unscaled, pow10(d.Scale))
+}
+
+/**
+ * Float64: returns the nearest 64-bit float to the decimal
+ */
+func (d Decimal) Float64() float64 {
+	res, _ := d.Rat().Float64()
+	return res
+}
+
+/**
+ * Cmp: compares two decimals, returns -1 if d < e, 0 if they're equal and 1 if d > e
+ * @param e compared decimal
+ */
+func (d Decimal) Cmp(e Decimal) int {
+	a, b := align(d, e)
+	return a.Cmp(b)
+}
+
+/**
+ * align: returns unscaled values of two decimals brought to the bigger of their scales
+ * @param a first decimal
+ * @param b second decimal
+ */
+func align(a, b Decimal) (*big.Int, *big.Int) {
+	x, y := new(big.Int).Set(a.Unscaled), new(big.Int).Set(b.Unscaled)
+	if a.Scale < b.Scale {
+		x.Mul(x, pow10(b.Scale-a.Scale))
+	} else if b.Scale < a.Scale {
+		y.Mul(y, pow10(a.Scale-b.Scale))
+	}
+	return x, y
+}
+
+/**
+ * maxScale: returns the bigger of the scales of two decimals
+ * @param a first decimal
+ * @param b second decimal
+ */
+func maxScale(a, b Decimal) int {
+	if a.Scale > b.Scale {
+		return a.Scale
+	}
+	return b.Scale
+}
+
+/**
+ * trimZeros: removes trailing zeros of the fractional digits, e.g. 2.500 to 2.5
+ * @param d decimal, its unscaled value may be overwritten
+ */
+func trimZeros(d Decimal) Decimal {
+	r := new(big.Int)
+	for d.Scale > 0 {
+		q, _ := new(big.Int).QuoRem(d.Unscaled, bigTen, r)
+		if r.Sign() != 0 {
+			break
+		}
+		d = Decimal{q, d.Scale - 1}
+	}
+	return d
+}
+
+/**
+ * roundQuotient: returns num/den rounded to an integer
+ * @param num numerator
+ * @param den positive denominator
+ * @param rounding how ties are rounded
+ */
+func roundQuotient(num, den *big.Int, rounding DecimalRounding) *big.Int {
+	q, r := new(big.Int).QuoRem(num, den, new(big.Int))
+	half := new(big.Int).Abs(r)
+	half.Lsh(half, 1)
+	cmp := half.Cmp(den)
+	if cmp > 0 || cmp == 0 && (rounding == RoundHalfUp || q.Bit(0) == 1) {
+		q.Add(q, big.NewInt(int64(num.Sign())))
+	}
+	return q
+}
+
+/**
+ * Round: rounds a fraction to the scale of the context and removes trailing zeros
+ * @param x the fraction
+ */
+func (ctx DecimalContext) Round(x *big.Rat) Decimal {
+	num := new(big.Int).Mul(x.Num(), pow10(ctx.Scale))
+	return trimZeros(Decimal{roundQuotient(num, x.Denom(), ctx.Rounding), ctx.Scale})
+}
+
+/**
+ * DecimalAdd: adds two decimals exactly
+ * @param a first decimal
+ * @param b second decimal
+ */
+func DecimalAdd(a, b Decimal) Decimal {
+	x, y := align(a, b)
+	return Decimal{x.Add(x, y), maxScale(a, b)}
+}
+
+/**
+ * DecimalSubtract: subtracts two decimals exactly
+ * @param a first decimal
+ * @param b second decimal
+ */
+func DecimalSubtract(a, b Decimal) Decimal {
+	x, y := align(a, b)
+	return Decimal{x.Sub(x, y), maxScale(a, b)}
+}
+
+/**
+ * DecimalMultiply: multiplies two decimals exactly, the scale of the product is the sum of their scales
+ * @param a first decimal
+ * @param b second decimal
+ */
+func DecimalMultiply(a, b Decimal) Decimal {
+	return Decimal{new(big.Int).Mul(a.Unscaled, b.Unscaled), a.Scale + b.Scale}
+}
+
+/**
+ * DecimalDivide: divides two decimals, the quotient is rounded to the scale of the context.
+ * Returns error if b is zero.
+ * @param a first decimal
+ * @param b second decimal
+ * @param ctx scale and rounding of the quotient
+ */
+func DecimalDivide(a, b Decimal, ctx DecimalContext) (Decimal, error) {
+	if b.Unscaled.Sign() == 0 {
+		return Decimal{}, errors.New("cannot divide by zero")
+	}
+	return ctx.Round(new(big.Rat).Quo(a.Rat(), b.Rat())), nil
+}
+
+/**
+ * DecimalAbsoluteValue: returns absolute value of a decimal
+ * @param a decimal
+ */
+func DecimalAbsoluteValue(a Decimal) Decimal {
+	return Decimal{new(big.Int).Abs(a.Unscaled), a.Scale}
+}
+
+/**
+ * DecimalModulo: returns the exact remainder of division of two decimals. Returns error if b is zero.
+ * The remainder has the sign of the divisor as in Modulo.
+ * @param a first decimal
+ * @param b second decimal
+ */
+func DecimalModulo(a, b Decimal) (Decimal, error) {
+	if b.Unscaled.Sign() == 0 {
+		return Decimal{}, errors.New("cannot divide by zero")
+	}
+	x, y := align(a, b)
+	_, r := new(big.Int).QuoRem(x, y, new(big.Int))
+	if r.Sign() != 0 && r.Sign() != y.Sign() {
+		r.Add(r, y)
+	}
+	return Decimal{r, maxScale(a, b)}, nil
+}
+
+/**
+ * decimalNatural: converts a decimal holding a natural number to int64
+ * Returns error if the decimal isn't a natural number or is bigger than limit.
+ * @param a decimal
+ * @param limit biggest allowed number
+ * @param name name of the function used in the error message
+ */
+func decimalNatural(a Decimal, limit int64, name string) (int64, error) {
+	r := a.Rat()
+	if !r.IsInt() || r.Sign() < 0 {
+		return 0, fmt.Errorf("%s in decimal mode works only with natural numbers", name)
+	}
+	if !r.Num().IsInt64() || r.Num().Int64() > limit {
+		return 0, fmt.Errorf("%v is too big for %s", a, name)
+	}
+	return r.Num().Int64(), nil
+}
+
+/**
+ * DecimalFactorial: returns exact factorial of a natural number
+ * Negative numbers, decimals with fractional digits and numbers above 5000 return an error.
+ * @param a decimal holding a natural number
+ */
+func DecimalFactorial(a Decimal) (Decimal, error) {
+	n, err := decimalNatural(a, maxDecimalFactorial, "factorial")
+	if err != nil {
+		return Decimal{}, err
+	}
+	if n < 2 {
+		return Decimal{big.NewInt(1), 0}, nil
+	}
+	return Decimal{new(big.Int).MulRange(1, n), 0}, nil
+}
+
+/**
+ * DecimalDoubleFactorial: returns exact double factorial of a natural number, e.g. 7!! = 7*5*3*1
+ * Negative numbers, decimals with fractional digits and numbers above 5000 return an error.
+ * @param a decimal holding a natural number
+ */
+func DecimalDoubleFactorial(a Decimal) (Decimal, error) {
+	n, err := decimalNatural(a, maxDecimalFactorial, "double factorial")
+	if err != nil {
+		return Decimal{}, err
+	}
+	res := big.NewInt(1)
+	for i := n; i > 1; i -= 2 {
+		res.Mul(res, big.NewInt(i))
+	}
+	return Decimal{res, 0}, nil
+}
+
+/**
+ * DecimalPower: returns base raised to the power of exponent
+ *
+ * Natural exponents give exact results. Negative exponents and fractional exponents p/q are calculated
+ * as the q-th root of base^p, which is rounded to the scale of the context, e.g. 2^0.5 is the square root of 2.
+ * Fractional exponents need a non-negative base and a denominator up to 100.
+ *
+ * @param base decimal used as the base of the exponentiation
+ * @param exponent decimal used as the exponent
+ * @param ctx scale and rounding of inexact results
+ */
+func DecimalPower(base, exponent Decimal, ctx DecimalContext) (Decimal, error) {
+	if base.Unscaled.Sign() == 0 {
+		switch exponent.Unscaled.Sign() {
+		case 0:
+			return Decimal{}, fmt.Errorf("0^0 is undefined")
+		case -1:
+			return Decimal{}, fmt.Errorf("cannot raise 0 to a negative power")
+		}
+		return Decimal{new(big.Int), 0}, nil
+	}
+	e := exponent.Rat()
+	if !e.IsInt() && base.Unscaled.Sign() < 0 {
+		return Decimal{}, fmt.Errorf("cannot raise a negative number to a fractional power: %v^%v", base, exponent)
+	}
+	return rationalPower(base, e, ctx)
+}
+
+/**
+ * DecimalRoot: returns the nth root of x rounded to the scale of the context
+ *
+ * The degree has to be positive, fractional degrees p/q are calculated as the p-th root of x^q.
+ * Negative x only has a real root for odd integer degrees, the root is then negative.
+ *
+ * @param x decimal used as the radicand
+ * @param n decimal used as the degree of the root
+ * @param ctx scale and rounding of the root
+ */
+func DecimalRoot(x, n Decimal, ctx DecimalContext) (Decimal, error) {
+	switch n.Unscaled.Sign() {
+	case 0:
+		return Decimal{}, fmt.Errorf("can't calculate 0th root")
+	case -1:
+		return Decimal{}, fmt.Errorf("can't calculate root of a negative degree: %v", n)
+	}
+	degree := n.Rat()
+	if x.Unscaled.Sign() < 0 {
+		if !degree.IsInt() || degree.Num().Bit(0) == 0 {
+			return Decimal{}, fmt.Errorf("can't calculate root %v of a negative number: %v", n, x)
+		}
+		res, err := rationalPower(DecimalAbsoluteValue(x), new(big.Rat).Inv(degree), ctx)
+		if err != nil {
+			return Decimal{}, err
+		}
+		return Decimal{res.Unscaled.Neg(res.Unscaled), res.Scale}, nil
+	}
+	if x.Unscaled.Sign() == 0 {
+		return Decimal{new(big.Int), 0}, nil
+	}
+	return rationalPower(x, new(big.Rat).Inv(degree), ctx)
+}
+
+/**
+ * rationalPower: raises a non-zero decimal to a rational power p/q as the q-th root of base^p
+ * @param base non-zero decimal, positive unless the exponent is an integer
+ * @param exponent the exponent
+ * @param ctx scale and rounding of inexact results
+ */
+func rationalPower(base Decimal, exponent *big.Rat, ctx DecimalContext) (Decimal, error) {
+	p, q := exponent.Num(), exponent.Denom()
+	if !q.IsInt64() || q.Int64() > maxDecimalRootDegree {
+		return Decimal{}, fmt.Errorf("%v is too fine an exponent for decimal mode", exponent.RatString())
+	}
+	bits := int64(base.Unscaled.BitLen() + 4*base.Scale)
+	if !p.IsInt64() || new(big.Int).Abs(p).Int64() > maxDecimalPowerBits/bits {
+		return Decimal{}, fmt.Errorf("result of %v^%v is too big", base, exponent.RatString())
+	}
+	n := p.Int64()
+	if n < 0 {
+		n = -n
+	}
+	power := Decimal{new(big.Int).Exp(base.Unscaled, big.NewInt(n), nil), base.Scale * int(n)}
+	if p.Sign() >= 0 && q.Int64() == 1 {
+		return power, nil
+	}
+	x := power.Rat()
+	if p.Sign() < 0 {
+		x.Inv(x)
+	}
+	return ctx.root(x, int(q.Int64())), nil
+}
+
+/**
+ * root: returns the nth root of a positive fraction rounded to the scale of the context
+ * @param x the fraction
+ * @param n positive degree of the root
+ */
+func (ctx DecimalContext) root(x *big.Rat, n int) Decimal {
+	if n == 1 {
+		return ctx.Round(x)
+	}
+	// the root of x × 10^(n × scale) is the root of x with the scale, flooring the radicand doesn't change its floor
+	num := new(big.Int).Mul(x.Num(), pow10(n*ctx.Scale))
+	radicand := new(big.Int).Quo(num, x.Denom())
+	res := IntegerRoot(radicand, n)
+
+	// compare (res + 1/2)^n with the radicand, both multiplied by 2^n × denominator
+	bigN := big.NewInt(int64(n))
+	mid := new(big.Int).Lsh(res, 1)
+	mid.Add(mid, big.NewInt(1))
+	mid.Exp(mid, bigN, nil)
+	mid.Mul(mid, x.Denom())
+	cmp := mid.Cmp(num.Lsh(num, uint(n)))
+	if cmp < 0 || cmp == 0 && (ctx.Rounding == RoundHalfUp || res.Bit(0) == 1) {
+		res.Add(res, big.NewInt(1))
+	}
+	return trimZeros(Decimal{res, ctx.Scale})
+}
+
+/**
+ * IntegerRoot: returns the floor of the nth root of a natural number using Newton's method
+ * @param a the natural number
+ * @param n degree of the root, at least 1
+ */
+func IntegerRoot(a *big.Int, n int) *big.Int {
+	if a.Sign() == 0 {
+		return new(big.Int)
+	}
+	if n == 1 {
+		return new(big.Int).Set(a)
+	}
+	if n == 2 {
+		return new(big.Int).Sqrt(a)
+	}
+	bigN, bigN1 := big.NewInt(int64(n)), big.NewInt(int64(n-1))
+	// start above the root, then the approximations decrease until they reach its floor
+	x := new(big.Int).Lsh(big.NewInt(1), uint((a.BitLen()+n-1)/n))
+	for {
+		y := new(big.Int).Exp(x, bigN1, nil)
+		y.Quo(a, y)
+		y.Add(y, new(big.Int).Mul(bigN1, x))
+		y.Quo(y, bigN)
+		if y.Cmp(x) >= 0 {
+			return x
+		}
+		x = y
+	}
+}
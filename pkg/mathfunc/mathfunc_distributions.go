@@ -0,0 +1,506 @@
+package mathfunc
+
+import (
+	"errors"
+	"math"
+)
+
+// relative accuracy at which continued fractions and series of incomplete functions are stopped
+const incompleteTolerance = 1e-15
+
+// maximum number of terms of the continued fractions and series of incomplete functions
+const maxIncompleteTerms = 1000
+
+/**
+ * checkNormal: checks parameters of a normal distribution
+ * @param sigma standard deviation
+ */
+func checkNormal(sigma float64) error {
+	if !(sigma > 0) {
+		return errors.New("standard deviation has to be positive")
+	}
+	return nil
+}
+
+/**
+ * checkProbability: checks that p is a probability
+ * @param p float value
+ */
+func checkProbability(p float64) error {
+	if !(p >= 0 && p <= 1) {
+		return errors.New("probability has to be between 0 and 1")
+	}
+	return nil
+}
+
+/**
+ * checkDegrees: checks degrees of freedom of a distribution
+ * @param nu degrees of freedom
+ */
+func checkDegrees(nu float64) error {
+	if !(nu > 0) || math.IsInf(nu, 0) {
+		return errors.New("degrees of freedom have to be positive")
+	}
+	return nil
+}
+
+/**
+ * NormPdf: returns the probability density of the normal distribution with mean mu and standard deviation sigma at x
+ * Accurate to a few units in the last place. Returns error if sigma isn't positive.
+ * @param x float value
+ * @param mu mean
+ * @param sigma standard deviation
+ */
+func NormPdf(x, mu, sigma float64) (float64, error) {
+	if err := checkNormal(sigma); err != nil {
+		return 0, err
+	}
+	z := (x - mu) / sigma
+	return math.Exp(-z*z/2) / (sigma * math.Sqrt(2*math.Pi)), nil
+}
+
+/**
+ * NormCdf: returns the probability that a normally distributed value is less than or equal to x
+ * Calculated using the complementary error function, so even the far lower tail has full relative accuracy.
+ * Returns error if sigma isn't positive.
+ * @param x float value
+ * @param mu mean
+ * @param sigma standard deviation
+ */
+func NormCdf(x, mu, sigma float64) (float64, error) {
+	if err := checkNormal(sigma); err != nil {
+		return 0, err
+	}
+	return math.Erfc(-(x-mu)/(sigma*math.Sqrt2)) / 2, nil
+}
+
+// coefficients of the rational approximations of the normal quantile by Wichura, algorithm AS 241 (PPND16),
+// for the central region, the near tails and the far tails, numerators start with the constant term
+var (
+	normInvCentralNum = [8]float64{3.3871328727963666080e0, 1.3314166789178437745e+2, 1.9715909503065514427e+3,
+		1.3731693765509461125e+4, 4.5921953931549871457e+4, 6.7265770927008700853e+4, 3.3430575583588128105e+4,
+		2.5090809287301226727e+3}
+	normInvCentralDen = [8]float64{1, 4.2313330701600911252e+1, 6.8718700749205790830e+2, 5.3941960214247511077e+3,
+		2.1213794301586595867e+4, 3.9307895800092710610e+4, 2.8729085735721942674e+4, 5.2264952788528545610e+3}
+	normInvNearNum = [8]float64{1.42343711074968357734e0, 4.63033784615654529590e0, 5.76949722146069140550e0,
+		3.64784832476320460504e0, 1.27045825245236838258e0, 2.41780725177450611770e-1, 2.27238449892691845833e-2,
+		7.74545014278341407640e-4}
+	normInvNearDen = [8]float64{1, 2.05319162663775882187e0, 1.67638483018380384940e0, 6.89767334985100004550e-1,
+		1.48103976427480074590e-1, 1.51986665636164571966e-2, 5.47593808499534494600e-4, 1.05075007164441684324e-9}
+	normInvFarNum = [8]float64{6.65790464350110377720e0, 5.46378491116411436990e0, 1.78482653991729133580e0,
+		2.96560571828504891230e-1, 2.65321895265761230930e-2, 1.24266094738807843860e-3, 2.71155556874348757815e-5,
+		2.01033439929228813265e-7}
+	normInvFarDen = [8]float64{1, 5.99832206555887937690e-1, 1.36929880922735805310e-1, 1.48753612908506148525e-2,
+		7.86869131145613259100e-4, 1.84631831751005468180e-5, 1.42151175831644588870e-7, 2.04426310338993978564e-15}
+)
+
+/**
+ * polynomial: evaluates the polynomial with the coefficients at x using Horner's method
+ * @param coefficients coefficients starting with the constant term
+ * @param x float value
+ */
+func polynomial(coefficients [8]float64, x float64) float64 {
+	var res float64
+	for i := len(coefficients) - 1; i >= 0; i-- {
+		res = res*x + coefficients[i]
+	}
+	return res
+}
+
+/**
+ * NormInv: returns x for which NormCdf(x, mu, sigma) is p, the quantile of the normal distribution
+ * Calculated by Wichura's algorithm AS 241, accurate to about 1e-15 relative even in the far tails, e.g. for p 1e-300.
+ * The upper tail is calculated from 1-p, so p close to 1 is limited by its own rounding.
+ * Returns ±Inf for p 0 and 1, error if p isn't a probability or sigma isn't positive.
+ * @param p probability
+ * @param mu mean
+ * @param sigma standard deviation
+ */
+func NormInv(p, mu, sigma float64) (float64, error) {
+	if err := checkNormal(sigma); err != nil {
+		return 0, err
+	}
+	if err := checkProbability(p); err != nil {
+		return 0, err
+	}
+	if p == 0 {
+		return math.Inf(-1), nil
+	}
+	if p == 1 {
+		return math.Inf(1), nil
+	}
+	q := p - 0.5
+	if math.Abs(q) <= 0.425 {
+		r := 0.180625 - q*q
+		return mu + sigma*q*polynomial(normInvCentralNum, r)/polynomial(normInvCentralDen, r), nil
+	}
+	// distance from the nearer tail
+	r := math.Sqrt(-math.Log(math.Min(p, 1-p)))
+	var z float64
+	if r <= 5 {
+		r -= 1.6
+		z = polynomial(normInvNearNum, r) / polynomial(normInvNearDen, r)
+	} else {
+		r -= 5
+		z = polynomial(normInvFarNum, r) / polynomial(normInvFarDen, r)
+	}
+	if q < 0 {
+		z = -z
+	}
+	return mu + sigma*z, nil
+}
+
+/**
+ * checkBinomial: checks parameters of a binomial distribution and converts the number of trials to an integer
+ * @param n number of trials
+ * @param p probability of a success
+ */
+func checkBinomial(n, p float64) (float64, error) {
+	if n < 0 || n != math.Trunc(n) || math.IsInf(n, 0) {
+		return 0, errors.New("number of trials has to be a non-negative integer")
+	}
+	return n, checkProbability(p)
+}
+
+/**
+ * BinomPdf: returns the probability of exactly k successes in n independent trials with probability of success p
+ * Calculated in logarithms, the relative error is below 1e-11 up to thousands of trials. Returns 0 for k outside of 0 to n.
+ * Returns error if k isn't an integer, n isn't a non-negative integer or p isn't a probability.
+ * @param k number of successes
+ * @param n number of trials
+ * @param p probability of a success
+ */
+func BinomPdf(k, n, p float64) (float64, error) {
+	n, err := checkBinomial(n, p)
+	if err != nil {
+		return 0, err
+	}
+	if k != math.Trunc(k) {
+		return 0, errors.New("number of successes has to be an integer")
+	}
+	if k < 0 || k > n {
+		return 0, nil
+	}
+	// 0^0 is 1, the logarithm would be undefined
+	if p == 0 || p == 1 {
+		if k == n*p {
+			return 1, nil
+		}
+		return 0, nil
+	}
+	lnChoose, _ := math.Lgamma(n + 1)
+	lnK, _ := math.Lgamma(k + 1)
+	lnNK, _ := math.Lgamma(n - k + 1)
+	return math.Exp(lnChoose - lnK - lnNK + k*math.Log(p) + (n-k)*math.Log1p(-p)), nil
+}
+
+/**
+ * BinomCdf: returns the probability of at most k successes in n independent trials with probability of success p
+ * Calculated using the regularized incomplete beta function, the relative error is below 1e-11 up to thousands of trials.
+ * Returns error if n isn't a non-negative integer or p isn't a probability.
+ * @param k number of successes, it's rounded down
+ * @param n number of trials
+ * @param p probability of a success
+ */
+func BinomCdf(k, n, p float64) (float64, error) {
+	n, err := checkBinomial(n, p)
+	if err != nil {
+		return 0, err
+	}
+	k = math.Floor(k)
+	if k < 0 {
+		return 0, nil
+	}
+	if k >= n || p == 0 {
+		return 1, nil
+	}
+	if p == 1 {
+		return 0, nil
+	}
+	return regularizedBeta(n-k, k+1, 1-p, p), nil
+}
+
+/**
+ * PoissonPdf: returns the probability of exactly k events of a Poisson process with mean lambda
+ * Calculated in logarithms, the relative error is below 1e-11 for means up to thousands. Returns 0 for negative k.
+ * Returns error if k isn't an integer or lambda is negative.
+ * @param k number of events
+ * @param lambda mean number of events
+ */
+func PoissonPdf(k, lambda float64) (float64, error) {
+	if !(lambda >= 0) || math.IsInf(lambda, 0) {
+		return 0, errors.New("mean has to be a non-negative number")
+	}
+	if k != math.Trunc(k) {
+		return 0, errors.New("number of events has to be an integer")
+	}
+	if k < 0 {
+		return 0, nil
+	}
+	if lambda == 0 {
+		if k == 0 {
+			return 1, nil
+		}
+		return 0, nil
+	}
+	lnK, _ := math.Lgamma(k + 1)
+	return math.Exp(k*math.Log(lambda) - lambda - lnK), nil
+}
+
+/**
+ * StudentTCdf: returns the probability that a value of Student's t-distribution with nu degrees of freedom
+ * is less than or equal to t
+ * Calculated using the regularized incomplete beta function, the absolute error is below about 1e-12.
+ * Returns error if nu isn't positive.
+ * @param t float value
+ * @param nu degrees of freedom, it doesn't have to be an integer
+ */
+func StudentTCdf(t, nu float64) (float64, error) {
+	if err := checkDegrees(nu); err != nil {
+		return 0, err
+	}
+	if math.IsInf(t, 0) {
+		if t > 0 {
+			return 1, nil
+		}
+		return 0, nil
+	}
+	tail := studentTTail(t, nu)
+	if t > 0 {
+		return 1 - tail, nil
+	}
+	return tail, nil
+}
+
+/**
+ * studentTTail: returns the probability of the tail of Student's t-distribution beyond |t|,
+ * it keeps its relative precision far in the tail
+ * @param t float value
+ * @param nu degrees of freedom
+ */
+func studentTTail(t, nu float64) float64 {
+	t = math.Abs(t)
+	x, y := nu/(nu+t*t), t*t/(nu+t*t)
+	if t*t > nu {
+		// the fractions are reduced by t, t*t overflows far in the tail
+		r := nu / t
+		x, y = r/(r+t), t/(r+t)
+	}
+	return regularizedBeta(nu/2, 0.5, x, y) / 2
+}
+
+/**
+ * StudentTInv: returns t for which StudentTCdf(t, nu) is p, the quantile of Student's t-distribution
+ * Found by bisection of the tail probability to the nearest float, so small p keep their relative precision.
+ * Returns ±Inf for p 0 and 1, error if p isn't a probability or nu isn't positive.
+ * @param p probability
+ * @param nu degrees of freedom, it doesn't have to be an integer
+ */
+func StudentTInv(p, nu float64) (float64, error) {
+	if err := checkDegrees(nu); err != nil {
+		return 0, err
+	}
+	if err := checkProbability(p); err != nil {
+		return 0, err
+	}
+	switch p {
+	case 0:
+		return math.Inf(-1), nil
+	case 0.5:
+		return 0, nil
+	case 1:
+		return math.Inf(1), nil
+	}
+	// the distribution is symmetric, |t| is searched by the probability of its tail, which is exact for p < 0.5
+	tail, sign := p, -1.0
+	if p > 0.5 {
+		tail, sign = 1-p, 1
+	}
+	lo, hi := 0.0, 1.0
+	for studentTTail(hi, nu) > tail {
+		lo, hi = hi, hi*2
+		if math.IsInf(hi, 0) {
+			return sign * hi, nil
+		}
+	}
+	for {
+		mid := lo + (hi-lo)/2
+		if mid <= lo || mid >= hi {
+			return sign * mid, nil
+		}
+		if studentTTail(mid, nu) > tail {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+}
+
+/**
+ * ChiSquareCdf: returns the probability that a value of the chi-squared distribution with k degrees of freedom
+ * is less than or equal to x
+ * Calculated using the regularized incomplete gamma function, the absolute error is below about 1e-12.
+ * Returns error if k isn't positive.
+ * @param x float value
+ * @param k degrees of freedom, it doesn't have to be an integer
+ */
+func ChiSquareCdf(x, k float64) (float64, error) {
+	if err := checkDegrees(k); err != nil {
+		return 0, err
+	}
+	if x <= 0 {
+		return 0, nil
+	}
+	return regularizedGamma(k/2, x/2), nil
+}
+
+/**
+ * stirlingCorrection: returns the difference between ln Γ(x) and its Stirling's approximation
+ * @param x float value of at least 10
+ */
+func stirlingCorrection(x float64) float64 {
+	x2 := x * x
+	return (1.0/12 - (1.0/360-1/(1260*x2))/x2) / x
+}
+
+/**
+ * lnBeta: returns the natural logarithm of the beta function of positive a and b
+ * When a parameter is big, ln Γ(a+b) - ln Γ(a) is calculated without subtracting two big logarithms.
+ * @param a positive float value
+ * @param b positive float value
+ */
+func lnBeta(a, b float64) float64 {
+	if a < b {
+		a, b = b, a
+	}
+	lnB, _ := math.Lgamma(b)
+	if a < 10 {
+		lnA, _ := math.Lgamma(a)
+		lnAB, _ := math.Lgamma(a + b)
+		return lnA + lnB - lnAB
+	}
+	// ln Γ(a+b) - ln Γ(a) from Stirling's formula
+	ratio := (a-0.5)*math.Log1p(b/a) + b*math.Log(a+b) - b + stirlingCorrection(a+b) - stirlingCorrection(a)
+	return lnB - ratio
+}
+
+/**
+ * logPair: returns natural logarithms of x and y = 1-x, the one closer to 1 is calculated from the other one
+ * @param x float value from 0 to 1
+ * @param y 1-x, it's passed separately to keep its precision
+ */
+func logPair(x, y float64) (float64, float64) {
+	if x > 0.5 {
+		return math.Log1p(-y), math.Log(y)
+	}
+	return math.Log(x), math.Log1p(-x)
+}
+
+/**
+ * regularizedBeta: returns the regularized incomplete beta function I_x(a, b)
+ * Uses the continued fraction evaluated by the modified Lentz's method on the side where it converges quickly.
+ * @param a positive float value
+ * @param b positive float value
+ * @param x float value from 0 to 1
+ * @param y 1-x, it's passed separately to keep its precision
+ */
+func regularizedBeta(a, b, x, y float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if y <= 0 {
+		return 1
+	}
+	lnX, lnY := logPair(x, y)
+	front := math.Exp(a*lnX + b*lnY - lnBeta(a, b))
+	if x < (a+1)/(a+b+2) {
+		return front * betaFraction(a, b, x) / a
+	}
+	return 1 - front*betaFraction(b, a, y)/b
+}
+
+/**
+ * betaFraction: evaluates the continued fraction of the incomplete beta function
+ * @param a positive float value
+ * @param b positive float value
+ * @param x float value from 0 to (a+1)/(a+b+2)
+ */
+func betaFraction(a, b, x float64) float64 {
+	const tiny = 1e-300
+	c := 1.0
+	d := 1 - (a+b)*x/(a+1)
+	if math.Abs(d) < tiny {
+		d = tiny
+	}
+	d = 1 / d
+	res := d
+	for m := 1.0; m <= maxIncompleteTerms; m++ {
+		// even and odd step of the fraction
+		for _, coef := range []float64{
+			m * (b - m) * x / ((a + 2*m - 1) * (a + 2*m)),
+			-(a + m) * (a + b + m) * x / ((a + 2*m) * (a + 2*m + 1)),
+		} {
+			d = 1 + coef*d
+			if math.Abs(d) < tiny {
+				d = tiny
+			}
+			c = 1 + coef/c
+			if math.Abs(c) < tiny {
+				c = tiny
+			}
+			d = 1 / d
+			res *= d * c
+		}
+		if math.Abs(d*c-1) < incompleteTolerance {
+			break
+		}
+	}
+	return res
+}
+
+/**
+ * regularizedGamma: returns the regularized lower incomplete gamma function P(a, x)
+ * Uses the series for x < a+1 and the continued fraction of the upper function otherwise.
+ * @param a positive float value
+ * @param x positive float value
+ */
+func regularizedGamma(a, x float64) float64 {
+	lnA, _ := math.Lgamma(a)
+	front := math.Exp(a*math.Log(x) - x - lnA)
+	if x < a+1 {
+		term := 1 / a
+		sum := term
+		for n := 1.0; n <= maxIncompleteTerms; n++ {
+			term *= x / (a + n)
+			sum += term
+			if math.Abs(term) < math.Abs(sum)*incompleteTolerance {
+				break
+			}
+		}
+		return front * sum
+	}
+
+	const tiny = 1e-300
+	b := x + 1 - a
+	c := 1 / tiny
+	d := 1 / b
+	res := d
+	for n := 1.0; n <= maxIncompleteTerms; n++ {
+		coef := -n * (n - a)
+		b += 2
+		d = coef*d + b
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = b + coef/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		res *= d * c
+		if math.Abs(d*c-1) < incompleteTolerance {
+			break
+		}
+	}
+	return 1 - front*res
+}
@@ -0,0 +1,348 @@
+package mathfunc
+
+import (
+	"errors"
+	"math"
+)
+
+// Cash flows follow the sign convention of spreadsheets: money received is positive and money paid is negative,
+// e.g. a loan of 1000 has present value 1000 and its payments are negative.
+
+// relative accuracy at which iterative searches for a rate are stopped
+const rateTolerance = 1e-12
+
+// maximum number of iterations of the searches for a rate
+const maxRateIterations = 200
+
+/**
+ * checkRate: checks that an interest rate per period is greater than -100 %
+ * @param rate interest rate per period
+ */
+func checkRate(rate float64) error {
+	if !(rate > -1) || math.IsInf(rate, 0) {
+		return errors.New("rate has to be greater than -1")
+	}
+	return nil
+}
+
+/**
+ * growth: returns (1+rate)^n and (1+rate)^n - 1, the second one is precise even for tiny rates
+ * @param rate interest rate per period, greater than -1
+ * @param n number of periods
+ */
+func growth(rate, n float64) (float64, float64) {
+	exponent := n * math.Log1p(rate)
+	return math.Exp(exponent), math.Expm1(exponent)
+}
+
+/**
+ * annuityFactor: returns the value of payments of 1 per period at the end of the periods, ((1+rate)^n - 1)/rate
+ * @param rate interest rate per period, greater than -1
+ * @param n number of periods
+ * @param due true if payments are made at the beginning of the periods
+ */
+func annuityFactor(rate, n float64, due bool) float64 {
+	if rate == 0 {
+		return n
+	}
+	_, grown := growth(rate, n)
+	factor := grown / rate
+	if due {
+		factor *= 1 + rate
+	}
+	return factor
+}
+
+/**
+ * Pmt: returns the payment per period of an annuity, e.g. a loan, like PMT of spreadsheets
+ * Returns error if the rate isn't greater than -1 or the number of periods is 0.
+ * @param rate interest rate per period
+ * @param nper number of periods
+ * @param pv present value
+ * @param fv future value left after the last payment
+ * @param due true if payments are made at the beginning of the periods
+ */
+func Pmt(rate, nper, pv, fv float64, due bool) (float64, error) {
+	if err := checkRate(rate); err != nil {
+		return 0, err
+	}
+	if nper == 0 {
+		return 0, errors.New("number of periods can't be 0")
+	}
+	grown, _ := growth(rate, nper)
+	return -(pv*grown + fv) / annuityFactor(rate, nper, due), nil
+}
+
+/**
+ * Fv: returns the future value of an investment with periodic payments, like FV of spreadsheets
+ * Returns error if the rate isn't greater than -1.
+ * @param rate interest rate per period
+ * @param nper number of periods
+ * @param pmt payment per period
+ * @param pv present value
+ * @param due true if payments are made at the beginning of the periods
+ */
+func Fv(rate, nper, pmt, pv float64, due bool) (float64, error) {
+	if err := checkRate(rate); err != nil {
+		return 0, err
+	}
+	grown, _ := growth(rate, nper)
+	return -(pv*grown + pmt*annuityFactor(rate, nper, due)), nil
+}
+
+/**
+ * Pv: returns the present value of periodic payments, like PV of spreadsheets
+ * Returns error if the rate isn't greater than -1.
+ * @param rate interest rate per period
+ * @param nper number of periods
+ * @param pmt payment per period
+ * @param fv future value left after the last payment
+ * @param due true if payments are made at the beginning of the periods
+ */
+func Pv(rate, nper, pmt, fv float64, due bool) (float64, error) {
+	if err := checkRate(rate); err != nil {
+		return 0, err
+	}
+	grown, _ := growth(rate, nper)
+	return -(fv + pmt*annuityFactor(rate, nper, due)) / grown, nil
+}
+
+/**
+ * Nper: returns the number of periods needed to get from the present value to the future value, like NPER of spreadsheets
+ * Returns error if the rate isn't greater than -1 or the payments never reach the future value.
+ * @param rate interest rate per period
+ * @param pmt payment per period
+ * @param pv present value
+ * @param fv future value left after the last payment
+ * @param due true if payments are made at the beginning of the periods
+ */
+func Nper(rate, pmt, pv, fv float64, due bool) (float64, error) {
+	if err := checkRate(rate); err != nil {
+		return 0, err
+	}
+	if rate == 0 {
+		if pmt == 0 {
+			return 0, errors.New("payments never reach the future value")
+		}
+		return -(pv + fv) / pmt, nil
+	}
+	if due {
+		pmt *= 1 + rate
+	}
+	res := math.Log((pmt-fv*rate)/(pmt+pv*rate)) / math.Log1p(rate)
+	if math.IsNaN(res) || math.IsInf(res, 0) {
+		return 0, errors.New("payments never reach the future value")
+	}
+	return res, nil
+}
+
+/**
+ * findRate: finds a root of a function of the rate by the secant method starting from the guess
+ * The rate is kept greater than -1.
+ * @param f the function
+ * @param guess starting rate
+ */
+func findRate(f func(float64) float64, guess float64) (float64, error) {
+	x0, x1 := guess, guess+0.01
+	if x1 <= -1 {
+		x1 = (x0 - 1) / 2
+	}
+	y0, y1 := f(x0), f(x1)
+	for i := 0; i < maxRateIterations; i++ {
+		if y1 == 0 {
+			return x1, nil
+		}
+		if y1 == y0 {
+			break
+		}
+		x2 := x1 - y1*(x1-x0)/(y1-y0)
+		if x2 <= -1 {
+			// don't jump over the lowest possible rate
+			x2 = (x1 - 1) / 2
+		}
+		if math.IsNaN(x2) || math.IsInf(x2, 0) {
+			break
+		}
+		if math.Abs(x2-x1) <= rateTolerance*math.Max(1, math.Abs(x2)) {
+			return x2, nil
+		}
+		x0, y0 = x1, y1
+		x1, y1 = x2, f(x2)
+	}
+	return 0, errors.New("rate couldn't be found, try another guess")
+}
+
+/**
+ * Rate: returns the interest rate per period of an annuity, like RATE of spreadsheets
+ * The rate is found iteratively starting from the guess. Returns error if the number of periods isn't positive
+ * or the search doesn't converge.
+ * @param nper number of periods
+ * @param pmt payment per period
+ * @param pv present value
+ * @param fv future value left after the last payment
+ * @param due true if payments are made at the beginning of the periods
+ * @param guess starting rate, spreadsheets use 0.1
+ */
+func Rate(nper, pmt, pv, fv float64, due bool, guess float64) (float64, error) {
+	if !(nper > 0) {
+		return 0, errors.New("number of periods has to be positive")
+	}
+	if err := checkRate(guess); err != nil {
+		return 0, err
+	}
+	return findRate(func(rate float64) float64 {
+		grown, _ := growth(rate, nper)
+		return pv*grown + pmt*annuityFactor(rate, nper, due) + fv
+	}, guess)
+}
+
+/**
+ * Npv: returns the net present value of cash flows at the end of the following periods, like NPV of spreadsheets
+ * The first cash flow is discounted by one period. Returns error if the rate isn't greater than -1.
+ * @param rate discount rate per period
+ * @param values cash flows of the periods
+ */
+func Npv(rate float64, values []float64) (float64, error) {
+	if err := checkRate(rate); err != nil {
+		return 0, err
+	}
+	var res float64
+	for i := len(values) - 1; i >= 0; i-- {
+		res = (res + values[i]) / (1 + rate)
+	}
+	return res, nil
+}
+
+/**
+ * Irr: returns the internal rate of return of cash flows, for which their net present value is 0, like IRR of spreadsheets
+ * The first cash flow is not discounted. Returns error if the cash flows don't change their sign or the search doesn't converge.
+ * @param values cash flows of the periods
+ * @param guess starting rate, spreadsheets use 0.1
+ */
+func Irr(values []float64, guess float64) (float64, error) {
+	positive, negative := false, false
+	for _, x := range values {
+		positive = positive || x > 0
+		negative = negative || x < 0
+	}
+	if !positive || !negative {
+		return 0, errors.New("irr needs at least one positive and one negative cash flow")
+	}
+	if err := checkRate(guess); err != nil {
+		return 0, err
+	}
+	return findRate(func(rate float64) float64 {
+		var res float64
+		for i := len(values) - 1; i >= 0; i-- {
+			res = res/(1+rate) + values[i]
+		}
+		return res
+	}, guess)
+}
+
+/**
+ * CompoundInterest: returns the value of the principal after n periods of compound interest, principal*(1+rate)^n
+ * Returns error if the rate isn't greater than -1.
+ * @param principal the initial value
+ * @param rate interest rate per period
+ * @param n number of periods
+ */
+func CompoundInterest(principal, rate, n float64) (float64, error) {
+	if err := checkRate(rate); err != nil {
+		return 0, err
+	}
+	grown, _ := growth(rate, n)
+	return principal * grown, nil
+}
+
+/**
+ * checkPeriodsPerYear: checks the number of compounding periods per year
+ * @param npery number of periods per year
+ */
+func checkPeriodsPerYear(npery float64) error {
+	if npery < 1 || npery != math.Trunc(npery) || math.IsInf(npery, 0) {
+		return errors.New("number of periods per year has to be a positive integer")
+	}
+	return nil
+}
+
+/**
+ * EffectiveRate: returns the effective annual rate of a nominal annual rate compounded npery times a year,
+ * like EFFECT of spreadsheets
+ * Returns error if npery isn't a positive integer or the rate per period isn't greater than -1.
+ * @param nominal nominal annual rate
+ * @param npery number of compounding periods per year
+ */
+func EffectiveRate(nominal, npery float64) (float64, error) {
+	if err := checkPeriodsPerYear(npery); err != nil {
+		return 0, err
+	}
+	if err := checkRate(nominal / npery); err != nil {
+		return 0, err
+	}
+	_, grown := growth(nominal/npery, npery)
+	return grown, nil
+}
+
+/**
+ * NominalRate: returns the nominal annual rate compounded npery times a year of an effective annual rate,
+ * like NOMINAL of spreadsheets
+ * Returns error if npery isn't a positive integer or the rate isn't greater than -1.
+ * @param effective effective annual rate
+ * @param npery number of compounding periods per year
+ */
+func NominalRate(effective, npery float64) (float64, error) {
+	if err := checkPeriodsPerYear(npery); err != nil {
+		return 0, err
+	}
+	if err := checkRate(effective); err != nil {
+		return 0, err
+	}
+	_, grown := growth(effective, 1/npery)
+	return grown * npery, nil
+}
+
+/**
+ * AmortizationRow: one period of an amortization schedule
+ */
+type AmortizationRow struct {
+	Period    float64
+	Payment   float64 // whole payment of the period
+	Interest  float64 // part of the payment paying the interest
+	Principal float64 // part of the payment paying off the principal
+	Balance   float64 // principal left after the payment
+}
+
+// maximum number of periods of an amortization schedule
+const maxAmortizationPeriods = 10000
+
+/**
+ * Amortize: returns the schedule of a loan paid off by equal payments at the end of each period
+ * Amounts in the schedule are positive for a positive principal.
+ * Returns error if n isn't a positive integer up to 10000 or the rate isn't greater than -1.
+ * @param principal the loan
+ * @param rate interest rate per period
+ * @param n number of periods
+ */
+func Amortize(principal, rate, n float64) ([]AmortizationRow, error) {
+	if n < 1 || n != math.Trunc(n) || n > maxAmortizationPeriods {
+		return nil, errors.New("number of periods has to be a positive integer up to 10000")
+	}
+	pmt, err := Pmt(rate, n, principal, 0, false)
+	if err != nil {
+		return nil, err
+	}
+	payment := -pmt
+	balance := principal
+	schedule := make([]AmortizationRow, int(n))
+	for i := range schedule {
+		interest := balance * rate
+		balance -= payment - interest
+		if i == len(schedule)-1 {
+			// rounding errors of the previous periods would leave a tiny balance
+			balance = 0
+		}
+		schedule[i] = AmortizationRow{float64(i + 1), payment, interest, payment - interest, balance}
+	}
+	return schedule, nil
+}
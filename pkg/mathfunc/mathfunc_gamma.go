@@ -0,0 +1,184 @@
+package mathfunc
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+/**
+ * LargeNumber: positive number too big for a float64 value, equal to Mantissa * 10^Exponent
+ *
+ * The mantissa is at least 1 and less than 10.
+ */
+type LargeNumber struct {
+	Mantissa float64
+	Exponent int64
+}
+
+/**
+ * Digits: returns the number of significant digits of the mantissa, which are accurate
+ * The natural logarithm the number was calculated from has an error of a few ulp, which becomes the relative error
+ * of the mantissa, so the accuracy falls as the exponent grows. Returns 0 if even the exponent may be wrong.
+ */
+func (n LargeNumber) Digits() int {
+	// error of the logarithm, 4 ulp cover both the logarithm of the gamma function and converting it
+	lnError := (float64(n.Exponent) + math.Log10(n.Mantissa)) * math.Ln10 * 0x1p-51
+	if lnError < 1e-10 {
+		return 10
+	}
+	if lnError >= 1 {
+		return 0
+	}
+	return int(math.Floor(-math.Log10(lnError)))
+}
+
+/**
+ * String: formats the number with the accurate digits of its mantissa, e.g. 2.846259681e+35659,
+ * a number whose mantissa has no accurate digit is written only with its order, e.g. ~1e+14565705518096754
+ */
+func (n LargeNumber) String() string {
+	digits := n.Digits()
+	if digits == 0 {
+		return fmt.Sprintf("~1e%+d", n.Exponent)
+	}
+	return fmt.Sprintf("%.*ge%+d", digits, n.Mantissa, n.Exponent)
+}
+
+// ErrExponentTooBig: error of numbers too big even for a LargeNumber, whose exponent doesn't fit into an int64 value
+var ErrExponentTooBig = errors.New("exponent of the result doesn't fit into 64 bits")
+
+/**
+ * isPole: checks whether the gamma function has a pole at x, which happens for non-positive integers
+ * @param x float value
+ */
+func isPole(x float64) bool {
+	return x <= 0 && x == math.Trunc(x)
+}
+
+/**
+ * Gamma: returns the gamma function of x, which extends factorial to real numbers, Γ(n) = (n-1)!
+ * Returns error for non-positive integers, where the function isn't defined, and when the result doesn't fit into a float64 value.
+ * @param x float value
+ */
+func Gamma(x float64) (float64, error) {
+	if isPole(x) {
+		return 0, errors.New("gamma is undefined for non-positive integers")
+	}
+	res := math.Gamma(x)
+	if math.IsInf(res, 0) {
+		return 0, fmt.Errorf("result of gamma(%g) is too big", x)
+	} else if res == 0 {
+		return 0, fmt.Errorf("result of gamma(%g) is too small", x)
+	}
+	return res, nil
+}
+
+/**
+ * Lgamma: returns the natural logarithm of the absolute value of the gamma function of x
+ * Unlike Gamma it can be used for very large x. Returns error for non-positive integers.
+ * @param x float value
+ */
+func Lgamma(x float64) (float64, error) {
+	if isPole(x) {
+		return 0, errors.New("lgamma is undefined for non-positive integers")
+	}
+	res, _ := math.Lgamma(x)
+	return res, nil
+}
+
+/**
+ * Beta: returns the beta function of a and b, B(a, b) = Γ(a)Γ(b)/Γ(a+b)
+ * Returns error if a or b is a non-positive integer or the result is too big.
+ * @param a first float value
+ * @param b second float value
+ */
+func Beta(a, b float64) (float64, error) {
+	if isPole(a) || isPole(b) {
+		return 0, errors.New("beta is undefined for non-positive integers")
+	}
+	if isPole(a + b) {
+		return 0, nil
+	}
+	// gamma values are more precise, but overflow sooner than their logarithms
+	if ga, gb, gab := math.Gamma(a), math.Gamma(b), math.Gamma(a+b); !math.IsInf(ga, 0) && !math.IsInf(gb, 0) && !math.IsInf(gab, 0) && gab != 0 {
+		res := ga * gb / gab
+		if !math.IsInf(res, 0) && res != 0 {
+			return res, nil
+		}
+	}
+	la, sa := math.Lgamma(a)
+	lb, sb := math.Lgamma(b)
+	lab, sab := math.Lgamma(a + b)
+	res := float64(sa*sb*sab) * math.Exp(la+lb-lab)
+	if math.IsInf(res, 0) {
+		return 0, fmt.Errorf("result of beta(%g, %g) is too big", a, b)
+	}
+	return res, nil
+}
+
+/**
+ * largeFromLog: converts natural logarithm of a number to the number in the form of mantissa and exponent
+ * Returns ErrExponentTooBig if the exponent doesn't fit into an int64 value.
+ * @param ln natural logarithm of the number
+ */
+func largeFromLog(ln float64) (LargeNumber, error) {
+	log10 := ln / math.Ln10
+	exponent := math.Floor(log10)
+	mantissa := math.Pow(10, log10-exponent)
+	if mantissa >= 10 {
+		mantissa /= 10
+		exponent++
+	}
+	// float64(math.MaxInt64) is 2^63, the first exponent which doesn't fit
+	if math.IsNaN(exponent) || exponent >= math.MaxInt64 {
+		return LargeNumber{}, ErrExponentTooBig
+	}
+	return LargeNumber{mantissa, int64(exponent)}, nil
+}
+
+/**
+ * LargeFactorial: returns factorial of a number too big for Factorial, calculated from the logarithm of the gamma function
+ * The precision of the mantissa decreases as the exponent grows, the exponent has to fit into an int64 value,
+ * which holds up to about 5e17!. Returns error for negative numbers and wraps ErrExponentTooBig for bigger ones.
+ * @param a non-negative float value
+ */
+func LargeFactorial(a float64) (LargeNumber, error) {
+	if a < 0 || math.IsInf(a, 0) || math.IsNaN(a) {
+		return LargeNumber{}, fmt.Errorf("cannot calculate large factorial of %g", a)
+	}
+	ln, _ := math.Lgamma(a + 1)
+	res, err := largeFromLog(ln)
+	if err != nil {
+		return LargeNumber{}, fmt.Errorf("%g! is too big: %w", a, err)
+	}
+	return res, nil
+}
+
+/**
+ * LargeDoubleFactorial: returns double factorial of a natural number too big for DoubleFactorial
+ * Uses (2k)!! = 2^k k! and (2k-1)!! = (2k)!/(2^k k!). Returns error for negative numbers and decimals
+ * and wraps ErrExponentTooBig if the exponent doesn't fit into an int64 value.
+ * @param a natural float value
+ */
+func LargeDoubleFactorial(a float64) (LargeNumber, error) {
+	if a < 0 || a != math.Trunc(a) || math.IsInf(a, 0) {
+		return LargeNumber{}, fmt.Errorf("cannot calculate large double factorial of %g", a)
+	}
+	var ln float64
+	if math.Mod(a, 2) == 0 {
+		k := a / 2
+		lk, _ := math.Lgamma(k + 1)
+		ln = k*math.Ln2 + lk
+	} else {
+		k := (a + 1) / 2
+		l2k, _ := math.Lgamma(a + 2)
+		lk, _ := math.Lgamma(k + 1)
+		ln = l2k - k*math.Ln2 - lk
+	}
+	res, err := largeFromLog(ln)
+	if err != nil {
+		return LargeNumber{}, fmt.Errorf("%g!! is too big: %w", a, err)
+	}
+	return res, nil
+}
@@ -0,0 +1,533 @@
+package mathfunc
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+/**
+ * Interval: closed interval of real numbers [Lo, Hi]
+ *
+ * Operations on intervals round their bounds outwards, so the resulting interval always contains
+ * every exact result of the operation on numbers from the operand intervals.
+ */
+type Interval struct {
+	Lo float64
+	Hi float64
+}
+
+// results smaller than this may lose their rounding error to underflow, these are widened unconditionally
+const tiny = 0x1p-969
+
+/**
+ * PointInterval: returns the interval containing only x
+ * @param x float value
+ */
+func PointInterval(x float64) Interval {
+	return Interval{x, x}
+}
+
+/**
+ * roundDown: returns the largest float which is not greater than the exact result
+ * @param res rounded result of an operation
+ * @param err error of the rounding, the exact result is res + err
+ */
+func roundDown(res, err float64) float64 {
+	if err < 0 || res != 0 && math.Abs(res) < tiny {
+		return math.Nextafter(res, math.Inf(-1))
+	}
+	return res
+}
+
+/**
+ * roundUp: returns the smallest float which is not less than the exact result
+ * @param res rounded result of an operation
+ * @param err error of the rounding, the exact result is res + err
+ */
+func roundUp(res, err float64) float64 {
+	if err > 0 || res != 0 && math.Abs(res) < tiny {
+		return math.Nextafter(res, math.Inf(1))
+	}
+	return res
+}
+
+/**
+ * sumError: returns the rounding error of a sum (TwoSum algorithm)
+ * @param a first float
+ * @param b second float
+ * @param s rounded sum a + b
+ */
+func sumError(a, b, s float64) float64 {
+	bb := s - a
+	return (a - (s - bb)) + (b - bb)
+}
+
+/**
+ * addDown: returns lower bound of a + b
+ * @param a first float
+ * @param b second float
+ */
+func addDown(a, b float64) float64 {
+	s := a + b
+	return roundDown(s, sumError(a, b, s))
+}
+
+/**
+ * addUp: returns upper bound of a + b
+ * @param a first float
+ * @param b second float
+ */
+func addUp(a, b float64) float64 {
+	s := a + b
+	return roundUp(s, sumError(a, b, s))
+}
+
+/**
+ * productError: returns a number with the sign of the rounding error of a product
+ * @param a first float
+ * @param b second float
+ * @param p rounded product a * b
+ */
+func productError(a, b, p float64) float64 {
+	if p == 0 && a != 0 && b != 0 { // underflow, the exact result has the sign of the operands
+		return math.Copysign(1, a) * math.Copysign(1, b)
+	}
+	return math.FMA(a, b, -p)
+}
+
+/**
+ * mulDown: returns lower bound of a * b
+ * @param a first float
+ * @param b second float
+ */
+func mulDown(a, b float64) float64 {
+	p := a * b
+	return roundDown(p, productError(a, b, p))
+}
+
+/**
+ * mulUp: returns upper bound of a * b
+ * @param a first float
+ * @param b second float
+ */
+func mulUp(a, b float64) float64 {
+	p := a * b
+	return roundUp(p, productError(a, b, p))
+}
+
+/**
+ * divisionError: returns a number with the sign of the rounding error of a division
+ * @param a dividend
+ * @param b divisor
+ * @param q rounded quotient a / b
+ */
+func divisionError(a, b, q float64) float64 {
+	if q == 0 && a != 0 { // underflow, the exact result has the sign of the operands
+		return math.Copysign(1, a) * math.Copysign(1, b)
+	}
+	r := math.FMA(-q, b, a) // exact remainder a - q*b
+	if b < 0 {
+		return -r
+	}
+	return r
+}
+
+/**
+ * divDown: returns lower bound of a / b
+ * @param a dividend
+ * @param b non-zero divisor
+ */
+func divDown(a, b float64) float64 {
+	q := a / b
+	return roundDown(q, divisionError(a, b, q))
+}
+
+/**
+ * divUp: returns upper bound of a / b
+ * @param a dividend
+ * @param b non-zero divisor
+ */
+func divUp(a, b float64) float64 {
+	q := a / b
+	return roundUp(q, divisionError(a, b, q))
+}
+
+/**
+ * powDown: returns lower bound of x^n for non-negative x
+ * @param x non-negative float value
+ * @param n natural exponent
+ */
+func powDown(x float64, n int) float64 {
+	res := 1.0
+	for ; n > 0; n >>= 1 {
+		if n&1 == 1 {
+			res = mulDown(res, x)
+		}
+		x = mulDown(x, x)
+	}
+	return res
+}
+
+/**
+ * powUp: returns upper bound of x^n for non-negative x
+ * @param x non-negative float value
+ * @param n natural exponent
+ */
+func powUp(x float64, n int) float64 {
+	res := 1.0
+	for ; n > 0; n >>= 1 {
+		if n&1 == 1 {
+			res = mulUp(res, x)
+		}
+		x = mulUp(x, x)
+	}
+	return res
+}
+
+/**
+ * rootDown: returns lower bound of the nth root of non-negative x
+ * The approximation is moved to the largest float whose power is certainly not greater than x.
+ * @param x non-negative float value
+ * @param n natural degree
+ */
+func rootDown(x float64, n int) float64 {
+	if x == 0 || math.IsInf(x, 1) {
+		return x
+	}
+	r := math.Pow(x, 1/float64(n))
+	for r > 0 && powUp(r, n) > x {
+		r = math.Nextafter(r, 0)
+	}
+	for next := math.Nextafter(r, math.Inf(1)); powUp(next, n) <= x; next = math.Nextafter(r, math.Inf(1)) {
+		r = next
+	}
+	return r
+}
+
+/**
+ * rootUp: returns upper bound of the nth root of non-negative x
+ * The approximation is moved to the smallest float whose power is certainly not less than x.
+ * @param x non-negative float value
+ * @param n natural degree
+ */
+func rootUp(x float64, n int) float64 {
+	if x == 0 || math.IsInf(x, 1) {
+		return x
+	}
+	r := math.Pow(x, 1/float64(n))
+	for powDown(r, n) < x {
+		r = math.Nextafter(r, math.Inf(1))
+	}
+	for prev := math.Nextafter(r, 0); prev > 0 && powDown(prev, n) >= x; prev = math.Nextafter(r, 0) {
+		r = prev
+	}
+	return r
+}
+
+/**
+ * expDown: returns lower bound of e^x, math.Exp is accurate to 1 ulp
+ * @param x float value
+ */
+func expDown(x float64) float64 {
+	return math.Max(math.Nextafter(math.Exp(x), math.Inf(-1)), 0)
+}
+
+/**
+ * expUp: returns upper bound of e^x, math.Exp is accurate to 1 ulp
+ * @param x float value
+ */
+func expUp(x float64) float64 {
+	if x == math.Inf(-1) {
+		return 0
+	}
+	return math.Nextafter(math.Exp(x), math.Inf(1))
+}
+
+/**
+ * logDown: returns lower bound of the natural logarithm of non-negative x, math.Log is accurate to 1 ulp
+ * @param x non-negative float value
+ */
+func logDown(x float64) float64 {
+	return math.Nextafter(math.Log(x), math.Inf(-1))
+}
+
+/**
+ * logUp: returns upper bound of the natural logarithm of non-negative x, math.Log is accurate to 1 ulp
+ * @param x non-negative float value
+ */
+func logUp(x float64) float64 {
+	return math.Nextafter(math.Log(x), math.Inf(1))
+}
+
+/**
+ * isPointInteger: checks whether the interval is a single point holding an integer
+ * @param a checked interval
+ */
+func isPointInteger(a Interval) bool {
+	return a.Lo == a.Hi && a.Lo == math.Trunc(a.Lo)
+}
+
+/**
+ * exactInteger: returns the integer contained in a single point interval
+ * @param a interval which has to be a single point holding an integer
+ * @param name name of the operand used in the error message
+ */
+func exactInteger(a Interval, name string) (int, error) {
+	if a.Lo != a.Hi || a.Lo != math.Trunc(a.Lo) {
+		return 0, fmt.Errorf("%s has to be an exact integer", name)
+	}
+	if math.Abs(a.Lo) >= 1<<62 {
+		return 0, fmt.Errorf("%s %g is too big", name, a.Lo)
+	}
+	return int(a.Lo), nil
+}
+
+/**
+ * IntervalAdd: adds two intervals
+ * @param a first interval
+ * @param b second interval
+ */
+func IntervalAdd(a, b Interval) Interval {
+	return Interval{addDown(a.Lo, b.Lo), addUp(a.Hi, b.Hi)}
+}
+
+/**
+ * IntervalSubtract: subtracts two intervals
+ * @param a first interval
+ * @param b second interval
+ */
+func IntervalSubtract(a, b Interval) Interval {
+	return Interval{addDown(a.Lo, -b.Hi), addUp(a.Hi, -b.Lo)}
+}
+
+/**
+ * IntervalMultiply: multiplies two intervals
+ * @param a first interval
+ * @param b second interval
+ */
+func IntervalMultiply(a, b Interval) Interval {
+	lo := math.Min(math.Min(mulDown(a.Lo, b.Lo), mulDown(a.Lo, b.Hi)), math.Min(mulDown(a.Hi, b.Lo), mulDown(a.Hi, b.Hi)))
+	hi := math.Max(math.Max(mulUp(a.Lo, b.Lo), mulUp(a.Lo, b.Hi)), math.Max(mulUp(a.Hi, b.Lo), mulUp(a.Hi, b.Hi)))
+	return Interval{lo, hi}
+}
+
+/**
+ * IntervalDivide: divides two intervals. Returns error if b contains zero.
+ * @param a first interval
+ * @param b second interval
+ */
+func IntervalDivide(a, b Interval) (Interval, error) {
+	if b.Lo <= 0 && b.Hi >= 0 {
+		return Interval{}, errors.New("cannot divide by an interval containing zero")
+	}
+	lo := math.Min(math.Min(divDown(a.Lo, b.Lo), divDown(a.Lo, b.Hi)), math.Min(divDown(a.Hi, b.Lo), divDown(a.Hi, b.Hi)))
+	hi := math.Max(math.Max(divUp(a.Lo, b.Lo), divUp(a.Lo, b.Hi)), math.Max(divUp(a.Hi, b.Lo), divUp(a.Hi, b.Hi)))
+	return Interval{lo, hi}, nil
+}
+
+/**
+ * IntervalAbsoluteValue: returns absolute values of an interval
+ * @param a interval
+ */
+func IntervalAbsoluteValue(a Interval) Interval {
+	if a.Lo >= 0 {
+		return a
+	}
+	if a.Hi <= 0 {
+		return Interval{-a.Hi, -a.Lo}
+	}
+	return Interval{0, math.Max(-a.Lo, a.Hi)}
+}
+
+/**
+ * IntervalModulo: returns remainders of division of two intervals. Returns error if b contains zero.
+ * The remainder has the sign of the divisor as in Modulo. When the quotients of a and b aren't in between
+ * the same two integers, the result covers all possible remainders.
+ * @param a first interval
+ * @param b second interval
+ */
+func IntervalModulo(a, b Interval) (Interval, error) {
+	quotient, err := IntervalDivide(a, b)
+	if err != nil {
+		return Interval{}, err
+	}
+	if k := math.Floor(quotient.Lo); k == math.Floor(quotient.Hi) {
+		return IntervalSubtract(a, IntervalMultiply(PointInterval(k), b)), nil
+	}
+	if b.Lo > 0 {
+		return Interval{0, b.Hi}, nil
+	}
+	return Interval{b.Lo, 0}, nil
+}
+
+// relative error, by which the gamma function of the factorial of real numbers is widened
+const gammaError = 1e-13
+
+/**
+ * IntervalFactorial: returns factorial of a natural number in a single point interval, or of real numbers using
+ * the gamma function widened by its relative error
+ * Intervals of real numbers wider than a point have to start at 0.5 or above, where the factorial is increasing.
+ * Negative integers and intervals containing an integer, which aren't a single point, return an error.
+ * @param a interval holding the argument
+ */
+func IntervalFactorial(a Interval) (Interval, error) {
+	if !isPointInteger(a) && (a.Lo == a.Hi || a.Lo >= 0.5) {
+		lo, err := Factorial(a.Lo)
+		if err != nil {
+			return Interval{}, err
+		}
+		hi, err := Factorial(a.Hi)
+		if err != nil {
+			return Interval{}, err
+		}
+		if lo > hi {
+			// the factorial of a single point below -1 can be negative
+			lo, hi = hi, lo
+		}
+		return Interval{lo - math.Abs(lo)*gammaError, hi + math.Abs(hi)*gammaError}, nil
+	}
+	n, err := exactInteger(a, "factorial argument")
+	if err != nil {
+		return Interval{}, err
+	}
+	if n < 0 {
+		return Interval{}, errors.New("cannot calculate factorial of negative numbers")
+	}
+	res := PointInterval(1)
+	for i := 2; i <= n && !math.IsInf(res.Hi, 1); i++ {
+		res = Interval{mulDown(res.Lo, float64(i)), mulUp(res.Hi, float64(i))}
+	}
+	return res, nil
+}
+
+/**
+ * IntervalPower: raises an interval to the power of an interval
+ * Powers of a single point integer exponent are multiplied out, other exponents are calculated as e^(exponent*ln base),
+ * which needs a base without negative numbers. Negative powers of intervals containing zero return an error.
+ * @param base interval used as the base of the exponentiation
+ * @param exponent interval holding the exponent
+ */
+func IntervalPower(base, exponent Interval) (Interval, error) {
+	if !isPointInteger(exponent) {
+		return realPower(base, exponent)
+	}
+	n, err := exactInteger(exponent, "exponent")
+	if err != nil {
+		return Interval{}, err
+	}
+	if n < 0 {
+		if base.Lo <= 0 && base.Hi >= 0 {
+			return Interval{}, errors.New("cannot raise an interval containing zero to a negative power")
+		}
+		positive, err := IntervalPower(base, PointInterval(float64(-n)))
+		if err != nil {
+			return Interval{}, err
+		}
+		return IntervalDivide(PointInterval(1), positive)
+	}
+	if n == 0 {
+		if base.Lo <= 0 && base.Hi >= 0 {
+			return Interval{}, fmt.Errorf("0^0 is undefined")
+		}
+		return PointInterval(1), nil
+	}
+
+	switch {
+	case base.Lo >= 0:
+		return Interval{powDown(base.Lo, n), powUp(base.Hi, n)}, nil
+	case n%2 == 1:
+		// odd powers are increasing
+		lo, hi := -powUp(-base.Lo, n), 0.0
+		if base.Hi >= 0 {
+			hi = powUp(base.Hi, n)
+		} else {
+			hi = -powDown(-base.Hi, n)
+		}
+		return Interval{lo, hi}, nil
+	case base.Hi <= 0:
+		return Interval{powDown(-base.Hi, n), powUp(-base.Lo, n)}, nil
+	default:
+		// even power of an interval containing zero
+		return Interval{0, powUp(math.Max(-base.Lo, base.Hi), n)}, nil
+	}
+}
+
+/**
+ * realPower: raises an interval without negative numbers to the power of a real interval as e^(exponent*ln base)
+ * Powers are monotonic in both operands, so the bounds are powers of the bounds of the operands.
+ * @param base interval used as the base of the exponentiation
+ * @param exponent interval holding the exponent
+ */
+func realPower(base, exponent Interval) (Interval, error) {
+	if base.Lo < 0 {
+		return Interval{}, errors.New("cannot raise an interval containing negative numbers to a power, which isn't an exact integer")
+	}
+	if base.Lo == 0 && exponent.Lo <= 0 {
+		return Interval{}, errors.New("cannot raise an interval containing zero to a power, which isn't positive")
+	}
+	if base.Hi == 0 {
+		return PointInterval(0), nil
+	}
+	logarithm := Interval{logDown(base.Lo), logUp(base.Hi)}
+	if base.Lo == 0 {
+		// ln 0 is -inf, exponent*ln 0 is -inf for positive exponents
+		high := exponent.Hi
+		if logarithm.Hi < 0 {
+			high = exponent.Lo
+		}
+		return Interval{0, expUp(mulUp(high, logarithm.Hi))}, nil
+	}
+	product := IntervalMultiply(logarithm, exponent)
+	return Interval{expDown(product.Lo), expUp(product.Hi)}, nil
+}
+
+/**
+ * IntervalRoot: returns the nth root of an interval
+ * Roots of a single point integer degree are found by powers of their bounds, other positive degrees are calculated
+ * as the power of 1/degree. Even roots and roots of other degrees can't be calculated for intervals containing
+ * negative numbers.
+ * @param x interval used as the radicand
+ * @param n interval holding the degree of the root
+ */
+func IntervalRoot(x, n Interval) (Interval, error) {
+	if !isPointInteger(n) {
+		if n.Lo <= 0 {
+			return Interval{}, fmt.Errorf("can't calculate root of a degree, which isn't positive")
+		}
+		if x.Lo < 0 {
+			return Interval{}, fmt.Errorf("can't calculate root of a degree, which isn't an exact integer, of an interval containing negative numbers")
+		}
+		exponent, err := IntervalDivide(PointInterval(1), n)
+		if err != nil {
+			return Interval{}, err
+		}
+		return realPower(x, exponent)
+	}
+	degree, err := exactInteger(n, "degree of a root")
+	if err != nil {
+		return Interval{}, err
+	}
+	if degree == 0 {
+		return Interval{}, fmt.Errorf("can't calculate 0th root")
+	} else if degree < 0 {
+		return Interval{}, fmt.Errorf("can't calculate root of a negative degree: %d", degree)
+	}
+	if x.Lo < 0 && degree%2 == 0 {
+		return Interval{}, fmt.Errorf("can't calculate root %d of an interval containing negative numbers", degree)
+	}
+
+	// odd roots are odd functions, so negative bounds are calculated from their absolute values
+	lo, hi := 0.0, 0.0
+	if x.Lo >= 0 {
+		lo = rootDown(x.Lo, degree)
+	} else {
+		lo = -rootUp(-x.Lo, degree)
+	}
+	if x.Hi >= 0 {
+		hi = rootUp(x.Hi, degree)
+	} else {
+		hi = -rootDown(-x.Hi, degree)
+	}
+	return Interval{lo, hi}, nil
+}
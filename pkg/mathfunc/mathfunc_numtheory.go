@@ -0,0 +1,423 @@
+package mathfunc
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"math/bits"
+	"sort"
+)
+
+// integers below it are stored in a float64 value exactly, 2^53 itself may be a rounded 2^53+1
+const maxExactInteger = 1 << 53
+
+/**
+ * toInteger: converts a 64-bit float holding an integer to int64
+ * Returns error if the float isn't an integer or is too big to hold an exact integer.
+ * @param x float value
+ * @param name name of the function used in the error message
+ */
+func toInteger(x float64, name string) (int64, error) {
+	if x != math.Trunc(x) {
+		return 0, fmt.Errorf("%s works only with integers", name)
+	}
+	if math.Abs(x) >= maxExactInteger {
+		return 0, fmt.Errorf("%g is too big for %s", x, name)
+	}
+	return int64(x), nil
+}
+
+/**
+ * toNatural: converts a 64-bit float holding a non-negative integer to uint64
+ * Returns error if the float isn't a non-negative integer or is too big to hold an exact integer.
+ * @param x float value
+ * @param name name of the function used in the error message
+ */
+func toNatural(x float64, name string) (uint64, error) {
+	n, err := toInteger(x, name)
+	if err != nil {
+		return 0, err
+	}
+	if n < 0 {
+		return 0, fmt.Errorf("%s works only with non-negative integers", name)
+	}
+	return uint64(n), nil
+}
+
+/**
+ * bigToFloat: converts an exact integer to the nearest 64-bit float
+ * Returns error if the integer is too big for a float64 value.
+ * @param x integer value
+ * @param name name of the function used in the error message
+ */
+func bigToFloat(x *big.Int, name string) (float64, error) {
+	res, _ := new(big.Float).SetInt(x).Float64()
+	if math.IsInf(res, 0) {
+		return 0, fmt.Errorf("result of %s is too big", name)
+	}
+	return res, nil
+}
+
+/**
+ * Combinations: returns the number of ways to choose k items out of n, nCr = n!/(k!(n-k)!)
+ * Returns 0 if k is negative or bigger than n. Works only with integers, n can't be negative.
+ * @param n number of items
+ * @param k number of chosen items
+ */
+func Combinations(n, k float64) (float64, error) {
+	a, err := toNatural(n, "nCr")
+	if err != nil {
+		return 0, err
+	}
+	b, err := toInteger(k, "nCr")
+	if err != nil {
+		return 0, err
+	}
+	if b < 0 || uint64(b) > a {
+		return 0, nil
+	}
+	r := uint64(b)
+	if a-r < r {
+		r = a - r
+	}
+	// C(2r, r) is bigger than 2^r, so the result can't fit into a float64 value
+	if r > 1100 {
+		return 0, errors.New("result of nCr is too big")
+	}
+	res := big.NewInt(1)
+	for i := uint64(0); i < r; i++ {
+		res.Mul(res, new(big.Int).SetUint64(a-i))
+		res.Quo(res, new(big.Int).SetUint64(i+1))
+	}
+	return bigToFloat(res, "nCr")
+}
+
+/**
+ * Permutations: returns the number of ordered selections of k items out of n, nPr = n!/(n-k)!
+ * Returns 0 if k is negative or bigger than n. Works only with integers, n can't be negative.
+ * @param n number of items
+ * @param k number of chosen items
+ */
+func Permutations(n, k float64) (float64, error) {
+	a, err := toNatural(n, "nPr")
+	if err != nil {
+		return 0, err
+	}
+	b, err := toInteger(k, "nPr")
+	if err != nil {
+		return 0, err
+	}
+	if b < 0 || uint64(b) > a {
+		return 0, nil
+	}
+	// the result is at least k!, which doesn't fit into a float64 value from 171 up
+	if b > 170 {
+		return 0, errors.New("result of nPr is too big")
+	}
+	res := big.NewInt(1)
+	for i := uint64(0); i < uint64(b); i++ {
+		res.Mul(res, new(big.Int).SetUint64(a-i))
+	}
+	return bigToFloat(res, "nPr")
+}
+
+/**
+ * gcd: returns the greatest common divisor of two non-negative integers
+ * @param a first integer
+ * @param b second integer
+ */
+func gcd(a, b uint64) uint64 {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+/**
+ * absInteger: returns absolute value of an integer as uint64
+ * @param a integer value
+ */
+func absInteger(a int64) uint64 {
+	if a < 0 {
+		return uint64(-a)
+	}
+	return uint64(a)
+}
+
+/**
+ * GCD: returns the greatest common divisor of the integers, which is always non-negative
+ * Returns error if there are no numbers or any of them isn't an integer.
+ * @param numbers slice of float values
+ */
+func GCD(numbers []float64) (float64, error) {
+	if len(numbers) == 0 {
+		return 0, errors.New("cannot calculate gcd of no numbers")
+	}
+	var res uint64
+	for _, x := range numbers {
+		n, err := toInteger(x, "gcd")
+		if err != nil {
+			return 0, err
+		}
+		res = gcd(res, absInteger(n))
+	}
+	return float64(res), nil
+}
+
+/**
+ * LCM: returns the least common multiple of the integers, which is always non-negative, 0 if any of them is 0
+ * Returns error if there are no numbers, any of them isn't an integer or the result is too big.
+ * @param numbers slice of float values
+ */
+func LCM(numbers []float64) (float64, error) {
+	if len(numbers) == 0 {
+		return 0, errors.New("cannot calculate lcm of no numbers")
+	}
+	res := big.NewInt(1)
+	for _, x := range numbers {
+		n, err := toInteger(x, "lcm")
+		if err != nil {
+			return 0, err
+		}
+		if n == 0 {
+			res.SetInt64(0)
+			continue
+		}
+		if res.Sign() == 0 {
+			continue
+		}
+		b := new(big.Int).SetUint64(absInteger(n))
+		divisor := new(big.Int).GCD(nil, nil, res, b)
+		res.Mul(res, b.Quo(b, divisor))
+	}
+	return bigToFloat(res, "lcm")
+}
+
+/**
+ * isPrime: checks whether a natural number is a prime, the test is exact for all 64-bit numbers
+ * @param n natural number
+ */
+func isPrime(n uint64) bool {
+	return new(big.Int).SetUint64(n).ProbablyPrime(0)
+}
+
+/**
+ * IsPrime: checks whether an integer is a prime, numbers less than 2 are not primes
+ * Returns error if the number isn't an integer or is too big.
+ * @param n float value
+ */
+func IsPrime(n float64) (bool, error) {
+	a, err := toInteger(n, "isprime")
+	if err != nil {
+		return false, err
+	}
+	return a >= 2 && isPrime(uint64(a)), nil
+}
+
+/**
+ * NextPrime: returns the smallest prime bigger than n
+ * Returns error if n isn't an integer or the prime is too big to be stored exactly.
+ * @param n float value
+ */
+func NextPrime(n float64) (float64, error) {
+	a, err := toInteger(n, "nextprime")
+	if err != nil {
+		return 0, err
+	}
+	if a < 2 {
+		return 2, nil
+	}
+	for p := uint64(a) + 1; p < maxExactInteger; p++ {
+		if isPrime(p) {
+			return float64(p), nil
+		}
+	}
+	return 0, errors.New("result of nextprime is too big")
+}
+
+/**
+ * mulMod: returns a*b mod m without overflow
+ * @param a first factor less than m
+ * @param b second factor less than m
+ * @param m modulus
+ */
+func mulMod(a, b, m uint64) uint64 {
+	hi, lo := bits.Mul64(a, b)
+	_, rem := bits.Div64(hi, lo, m)
+	return rem
+}
+
+/**
+ * pollardRho: finds a non-trivial divisor of an odd composite number using Pollard's rho algorithm
+ * @param n odd composite number
+ */
+func pollardRho(n uint64) uint64 {
+	for c := uint64(1); ; c++ {
+		next := func(x uint64) uint64 {
+			return (mulMod(x, x, n) + c) % n
+		}
+		x, y, d := uint64(2), uint64(2), uint64(1)
+		for d == 1 {
+			x = next(x)
+			y = next(next(y))
+			if x > y {
+				d = gcd(x-y, n)
+			} else {
+				d = gcd(y-x, n)
+			}
+		}
+		// the cycle closed without finding a divisor, try another polynomial
+		if d != n {
+			return d
+		}
+	}
+}
+
+/**
+ * primeFactors: returns prime factors of a natural number in ascending order, repeated according to their multiplicity
+ * @param n natural number, 1 has no prime factors
+ */
+func primeFactors(n uint64) []uint64 {
+	factors := make([]uint64, 0)
+	for p := uint64(2); p < 1000 && p*p <= n; p++ {
+		for n%p == 0 {
+			factors = append(factors, p)
+			n /= p
+		}
+	}
+	// the rest has only factors of at least 1000
+	composites := []uint64{n}
+	for len(composites) > 0 {
+		m := composites[len(composites)-1]
+		composites = composites[:len(composites)-1]
+		if m == 1 {
+			continue
+		}
+		if isPrime(m) {
+			factors = append(factors, m)
+			continue
+		}
+		d := pollardRho(m)
+		composites = append(composites, d, m/d)
+	}
+	sort.Slice(factors, func(i, j int) bool { return factors[i] < factors[j] })
+	return factors
+}
+
+/**
+ * Factor: returns prime factors of a positive integer in ascending order, e.g. 12 has factors 2, 2, 3
+ * Returns error if n isn't a positive integer or is too big.
+ * @param n float value
+ */
+func Factor(n float64) ([]float64, error) {
+	a, err := toNatural(n, "factor")
+	if err != nil {
+		return nil, err
+	}
+	if a == 0 {
+		return nil, errors.New("cannot factor 0")
+	}
+	factors := primeFactors(a)
+	res := make([]float64, len(factors))
+	for i, p := range factors {
+		res[i] = float64(p)
+	}
+	return res, nil
+}
+
+/**
+ * Totient: returns Euler's totient of a positive integer, the count of numbers up to n which are coprime with n
+ * Returns error if n isn't a positive integer or is too big.
+ * @param n float value
+ */
+func Totient(n float64) (float64, error) {
+	a, err := toNatural(n, "totient")
+	if err != nil {
+		return 0, err
+	}
+	if a == 0 {
+		return 0, errors.New("totient works only with positive integers")
+	}
+	res := a
+	var last uint64
+	for _, p := range primeFactors(a) {
+		if p != last {
+			res = res / p * (p - 1)
+			last = p
+		}
+	}
+	return float64(res), nil
+}
+
+/**
+ * toModulus: converts a 64-bit float holding a positive integer to a modulus
+ * @param m float value
+ * @param name name of the function used in the error message
+ */
+func toModulus(m float64, name string) (*big.Int, error) {
+	n, err := toInteger(m, name)
+	if err != nil {
+		return nil, err
+	}
+	if n <= 0 {
+		return nil, fmt.Errorf("modulus of %s has to be a positive integer", name)
+	}
+	return big.NewInt(n), nil
+}
+
+/**
+ * PowMod: returns a^b mod m calculated exactly, negative exponents use the modular inverse of a
+ * Returns error if any argument isn't an integer, m isn't positive or a has no inverse for a negative b.
+ * @param a base
+ * @param b exponent
+ * @param m modulus
+ */
+func PowMod(a, b, m float64) (float64, error) {
+	base, err := toInteger(a, "powmod")
+	if err != nil {
+		return 0, err
+	}
+	exponent, err := toInteger(b, "powmod")
+	if err != nil {
+		return 0, err
+	}
+	modulus, err := toModulus(m, "powmod")
+	if err != nil {
+		return 0, err
+	}
+	x := new(big.Int).Mod(big.NewInt(base), modulus)
+	if exponent < 0 {
+		if x.ModInverse(x, modulus) == nil {
+			return 0, fmt.Errorf("%d has no inverse modulo %d", base, modulus)
+		}
+		exponent = -exponent
+	}
+	res := new(big.Int).Exp(x, big.NewInt(exponent), modulus)
+	return float64(res.Int64()), nil
+}
+
+/**
+ * ModInverse: returns x from 0 to m-1 such that a*x mod m is 1
+ * Returns error if the arguments aren't integers, m isn't positive or a and m aren't coprime.
+ * @param a the inverted number
+ * @param m modulus
+ */
+func ModInverse(a, m float64) (float64, error) {
+	n, err := toInteger(a, "modinv")
+	if err != nil {
+		return 0, err
+	}
+	modulus, err := toModulus(m, "modinv")
+	if err != nil {
+		return 0, err
+	}
+	if modulus.IsInt64() && modulus.Int64() == 1 {
+		return 0, nil
+	}
+	x := new(big.Int).Mod(big.NewInt(n), modulus)
+	if x.ModInverse(x, modulus) == nil {
+		return 0, fmt.Errorf("%d has no inverse modulo %d", n, modulus)
+	}
+	return float64(x.Int64()), nil
+}
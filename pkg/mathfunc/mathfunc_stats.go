@@ -0,0 +1,143 @@
+package mathfunc
+
+import (
+	"errors"
+	"sort"
+)
+
+/**
+ * Mean: returns the arithmetic mean of the numbers
+ * Returns error if there are no numbers.
+ * @param numbers slice of float values
+ */
+func Mean(numbers []float64) (float64, error) {
+	if len(numbers) == 0 {
+		return 0, errors.New("cannot calculate mean of no numbers")
+	}
+	var sum float64
+	for _, x := range numbers {
+		sum = Add(sum, x)
+	}
+	return Divide(sum, float64(len(numbers)))
+}
+
+/**
+ * Median: returns the middle value of the sorted numbers, or the mean of the two middle values
+ * Returns error if there are no numbers.
+ * @param numbers slice of float values, it is not modified
+ */
+func Median(numbers []float64) (float64, error) {
+	if len(numbers) == 0 {
+		return 0, errors.New("cannot calculate median of no numbers")
+	}
+	sorted := Sort(numbers)
+	half := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		return sorted[half], nil
+	}
+	return Divide(Add(sorted[half-1], sorted[half]), 2)
+}
+
+/**
+ * Mode: returns the most frequent of the numbers
+ * If more numbers are the most frequent, the smallest one is returned. Returns error if there are no numbers.
+ * @param numbers slice of float values
+ */
+func Mode(numbers []float64) (float64, error) {
+	if len(numbers) == 0 {
+		return 0, errors.New("cannot calculate mode of no numbers")
+	}
+	counts := make(map[float64]int)
+	for _, x := range numbers {
+		counts[x]++
+	}
+	mode, best := 0.0, 0
+	for x, count := range counts {
+		if count > best || count == best && x < mode {
+			mode, best = x, count
+		}
+	}
+	return mode, nil
+}
+
+/**
+ * Variance: returns the sample variance of the numbers
+ * Deviations are summed from the mean calculated first, so that large numbers close to each other don't cancel out.
+ * Returns error if there are less than two numbers.
+ * @param numbers slice of float values
+ */
+func Variance(numbers []float64) (float64, error) {
+	if len(numbers) < 2 {
+		return 0, errors.New("cannot calculate variance of less than two numbers")
+	}
+	mean, err := Mean(numbers)
+	if err != nil {
+		return 0, err
+	}
+	var sum float64
+	for _, x := range numbers {
+		deviation := Subtract(x, mean)
+		sum = Add(sum, Multiply(deviation, deviation))
+	}
+	return Divide(sum, float64(len(numbers)-1))
+}
+
+/**
+ * StandardDeviation: returns the sample standard deviation of the numbers
+ * Returns error if there are less than two numbers.
+ * @param numbers slice of float values
+ */
+func StandardDeviation(numbers []float64) (float64, error) {
+	res, err := Variance(numbers)
+	if err != nil {
+		return 0, err
+	}
+	return Root(res, 2)
+}
+
+/**
+ * Min: returns the smallest of the numbers
+ * Returns error if there are no numbers.
+ * @param numbers slice of float values
+ */
+func Min(numbers []float64) (float64, error) {
+	if len(numbers) == 0 {
+		return 0, errors.New("cannot calculate minimum of no numbers")
+	}
+	min := numbers[0]
+	for _, x := range numbers[1:] {
+		if x < min {
+			min = x
+		}
+	}
+	return min, nil
+}
+
+/**
+ * Max: returns the largest of the numbers
+ * Returns error if there are no numbers.
+ * @param numbers slice of float values
+ */
+func Max(numbers []float64) (float64, error) {
+	if len(numbers) == 0 {
+		return 0, errors.New("cannot calculate maximum of no numbers")
+	}
+	max := numbers[0]
+	for _, x := range numbers[1:] {
+		if x > max {
+			max = x
+		}
+	}
+	return max, nil
+}
+
+/**
+ * Sort: returns the numbers sorted in ascending order
+ * @param numbers slice of float values, it is not modified
+ */
+func Sort(numbers []float64) []float64 {
+	sorted := make([]float64, len(numbers))
+	copy(sorted, numbers)
+	sort.Float64s(sorted)
+	return sorted
+}
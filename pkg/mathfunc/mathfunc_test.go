@@ -3,6 +3,8 @@ package mathfunc
 import (
 	"errors"
 	"math"
+	"math/big"
+	"reflect"
 	"testing"
 )
 
@@ -175,8 +177,118 @@ func TestFactorial(t *testing.T) {
 	FactorialTestCase(t, 5, 120, nil)
 	FactorialTestCase(t, 10, 3628800, nil)
 
-	FactorialTestCase(t, -1, 0, errors.New("cannot calculate factorial of negative numbers"))
+	FactorialTestCase(t, 20, 2432902008176640000, nil)
+
+	// real numbers use the gamma function
+	FactorialTestCase(t, 0.5, math.Sqrt(math.Pi)/2, nil)
+	FactorialTestCase(t, -0.5, math.Sqrt(math.Pi), nil)
+
+	FactorialTestCase(t, -1, 0, errors.New("cannot calculate factorial of negative integers"))
+	FactorialTestCase(t, 171, 0, errors.New("factorial too big"))
 	FactorialTestCase(t, 100000, 0, errors.New("factorial too big"))
+	FactorialTestCase(t, 200.5, 0, errors.New("factorial too big"))
+}
+
+func TestDoubleFactorial(t *testing.T) {
+	DoubleFactorialTestCase(t, -1, 1, nil)
+	DoubleFactorialTestCase(t, 0, 1, nil)
+	DoubleFactorialTestCase(t, 1, 1, nil)
+	DoubleFactorialTestCase(t, 7, 105, nil)
+	DoubleFactorialTestCase(t, 8, 384, nil)
+
+	DoubleFactorialTestCase(t, -2, 0, errors.New("cannot calculate double factorial of integers less than -1"))
+	DoubleFactorialTestCase(t, 2.5, 0, errors.New("double factorial works only with integers"))
+	DoubleFactorialTestCase(t, 1000, 0, errors.New("double factorial too big"))
+}
+
+func DoubleFactorialTestCase(t *testing.T, input float64, expectedOutput float64, expectedError error) {
+	output, err := DoubleFactorial(input)
+	if output != expectedOutput {
+		t.Errorf("DoubleFactorial(%f) = %f; should be %f", input, output, expectedOutput)
+	}
+	if (err == nil && expectedError != nil) || (err != nil && expectedError == nil) || (err != nil && expectedError != nil && err.Error() != expectedError.Error()) {
+		t.Errorf("DoubleFactorial(%f) err = %s; should be %s", input, err, expectedError)
+	}
+}
+
+func TestGamma(t *testing.T) {
+	GammaTestCase(t, "Gamma", Gamma, 5, 24, nil)
+	GammaTestCase(t, "Gamma", Gamma, 0.5, 1.7724538509, nil)
+	GammaTestCase(t, "Gamma", Gamma, -1.5, 2.3632718012, nil)
+	GammaTestCase(t, "Gamma", Gamma, 0, 0, errors.New("gamma is undefined for non-positive integers"))
+	GammaTestCase(t, "Gamma", Gamma, -2, 0, errors.New("gamma is undefined for non-positive integers"))
+	GammaTestCase(t, "Gamma", Gamma, 200, 0, errors.New("result of gamma(200) is too big"))
+
+	GammaTestCase(t, "Lgamma", Lgamma, 5, math.Log(24), nil)
+	GammaTestCase(t, "Lgamma", Lgamma, 200, 857.9336698258, nil)
+	GammaTestCase(t, "Lgamma", Lgamma, -1, 0, errors.New("lgamma is undefined for non-positive integers"))
+
+	beta := func(b float64) func(float64) (float64, error) {
+		return func(a float64) (float64, error) { return Beta(a, b) }
+	}
+	GammaTestCase(t, "Beta(x, 3)", beta(3), 2, 1.0/12, nil)
+	GammaTestCase(t, "Beta(x, 0.5)", beta(0.5), 0.5, math.Pi, nil)
+	GammaTestCase(t, "Beta(x, 300)", beta(300), 300, 4.934326264e-182, nil)
+	GammaTestCase(t, "Beta(x, -1.5)", beta(-1.5), -0.5, 0, nil)
+	GammaTestCase(t, "Beta(x, 2)", beta(2), 0, 0, errors.New("beta is undefined for non-positive integers"))
+}
+
+func GammaTestCase(t *testing.T, name string, f func(float64) (float64, error), input float64, expectedOutput float64, expectedError error) {
+	output, err := f(input)
+	// Check 10 significant digits
+	tolerance := math.Pow(10, -10) * math.Abs(expectedOutput)
+	if expectedOutput == 0 {
+		tolerance = math.Pow(10, -10)
+	}
+	if math.Abs(output-expectedOutput) > tolerance {
+		t.Errorf("%s(%f) = %.10g; should be %.10g", name, input, output, expectedOutput)
+	}
+	if (err == nil && expectedError != nil) || (err != nil && expectedError == nil) || (err != nil && expectedError != nil && err.Error() != expectedError.Error()) {
+		t.Errorf("%s(%f) err = %s; should be %s", name, input, err, expectedError)
+	}
+}
+
+func TestLargeFactorial(t *testing.T) {
+	LargeTestCase(t, "LargeFactorial(171)", LargeFactorial, 171, LargeNumber{1.2410180702, 309})
+	LargeTestCase(t, "LargeFactorial(100000)", LargeFactorial, 100000, LargeNumber{2.8242294080, 456573})
+	LargeTestCase(t, "LargeFactorial(10)", LargeFactorial, 10, LargeNumber{3.6288, 6})
+	LargeTestCase(t, "LargeDoubleFactorial(1000)", LargeDoubleFactorial, 1000, LargeNumber{3.9939844265, 1284})
+	LargeTestCase(t, "LargeDoubleFactorial(999)", LargeDoubleFactorial, 999, LargeNumber{1.0074832976, 1283})
+	LargeTestCase(t, "LargeDoubleFactorial(7)", LargeDoubleFactorial, 7, LargeNumber{1.05, 2})
+
+	// the mantissa keeps fewer accurate digits as the logarithm grows
+	for _, c := range []struct {
+		input    float64
+		expected string
+	}{{10, "3.6288e+6"}, {100000, "2.82422941e+456573"}, {1e8, "1.6172e+756570556"}, {1e15, "~1e+14565705518096754"}} {
+		if res, err := LargeFactorial(c.input); err != nil || res.String() != c.expected {
+			t.Errorf("LargeFactorial(%g) = %v, %v; should be %s", c.input, res, err, c.expected)
+		}
+	}
+
+	if _, err := LargeFactorial(-1); err == nil {
+		t.Errorf("LargeFactorial(-1) err = nil; should be an error")
+	}
+
+	// exponents up to the int64 range, log10(1e17!) is about 1.66e18
+	if res, err := LargeFactorial(1e17); err != nil || res.Exponent < 1.6e18 || res.Exponent > 1.7e18 {
+		t.Errorf("LargeFactorial(1e17) = %v, %v; should be about 1e+1.66e18", res, err)
+	}
+	for _, input := range []float64{1e20, 1e300, math.MaxFloat64} {
+		if res, err := LargeFactorial(input); !errors.Is(err, ErrExponentTooBig) {
+			t.Errorf("LargeFactorial(%g) = %v, %v; should be %v", input, res, err, ErrExponentTooBig)
+		}
+	}
+	if res, err := LargeDoubleFactorial(1e20); !errors.Is(err, ErrExponentTooBig) {
+		t.Errorf("LargeDoubleFactorial(1e20) = %v, %v; should be %v", res, err, ErrExponentTooBig)
+	}
+}
+
+func LargeTestCase(t *testing.T, name string, f func(float64) (LargeNumber, error), input float64, expectedOutput LargeNumber) {
+	output, err := f(input)
+	if err != nil || output.Exponent != expectedOutput.Exponent || math.Abs(output.Mantissa-expectedOutput.Mantissa) > math.Pow(10, -9) {
+		t.Errorf("%s = %v, %v; should be %v", name, output, err, expectedOutput)
+	}
 }
 
 func FactorialTestCase(t *testing.T, input float64, expectedOutput float64, expectedError error) {
@@ -191,20 +303,40 @@ func FactorialTestCase(t *testing.T, input float64, expectedOutput float64, expe
 
 func TestPower(t *testing.T) {
 	PowerTestCase(t, 0, 0, 0, errors.New("0^0 is undefined"))
-	PowerTestCase(t, 123, -1, 0, errors.New("invalid exponent: '-1', has to be >= 0"))
+	PowerTestCase(t, 0, -1, 0, errors.New("cannot raise 0 to a negative power"))
+	PowerTestCase(t, 0, 2.5, 0, nil)
 	PowerTestCase(t, 34.2, 0, 1, nil)
 	PowerTestCase(t, 34.2, 1, 34.2, nil)
 	PowerTestCase(t, 5, 2, 25, nil)
 	PowerTestCase(t, -5, 2, 25, nil)
 	PowerTestCase(t, -5, 3, -125, nil)
 	PowerTestCase(t, 10, 4, 10000, nil)
-	PowerTestCase(t, 10, 4.4, 10000, nil)
-	PowerTestCase(t, 10.2, 4.4, 10824.321600, nil)
 	PowerTestCase(t, 10, 5, 100000, nil)
 	PowerTestCase(t, 25, 8, 152587890625, nil)
-	PowerTestCase(t, 525789, 8, 5841064044963377783181066373525779412512931840.000000, nil)
+	// correctly rounded value of 525789^8
+	PowerTestCase(t, 525789, 8, 5841064044963379050831666601755180909216137216.000000, nil)
 	PowerTestCase(t, 525789, 20157, 0, errors.New("result of 525789.000^20157 is too big"))
 
+	// negative exponents
+	PowerTestCase(t, 2, -1, 0.5, nil)
+	PowerTestCase(t, 123, -1, 0.0081300813, nil)
+	PowerTestCase(t, -2, -3, -0.125, nil)
+	PowerTestCase(t, 2, -1074, math.SmallestNonzeroFloat64, nil)
+	PowerTestCase(t, 2, -1075, 0, errors.New("result of 2.000^-1075 is too small"))
+	PowerTestCase(t, 0.5, -1023, math.Pow(2, 1023), nil)
+
+	// fractional exponents
+	PowerTestCase(t, 10, 4.4, 25118.8643150958, nil)
+	PowerTestCase(t, 10.2, 4.4, 27405.6909380005, nil)
+	PowerTestCase(t, 4, 0.5, 2, nil)
+	PowerTestCase(t, 8, -1.0/3, 0.5, nil)
+	PowerTestCase(t, -8, 0.5, 0, errors.New("cannot raise a negative number to a fractional power: -8.000^0.5"))
+
+	// huge exponents don't take long
+	PowerTestCase(t, -1, 1e300, 1, nil)
+	PowerTestCase(t, 1, -9007199254740993, 1, nil)
+	PowerTestCase(t, 0.5, 1e300, 0, errors.New("result of 0.500^1e+300 is too small"))
+	PowerTestCase(t, 1.0000001, 1e18, 0, errors.New("result of 1.000^1e+18 is too big"))
 }
 
 func PowerTestCase(t *testing.T, base float64, exp float64, expectedOutput float64, expectedError error) {
@@ -234,21 +366,42 @@ func TestRoot(t *testing.T) {
 	RootTestCase(t, 400100000, 2, 20002.4998437695, nil)
 
 	RootTestCase(t, 64, 3, 4, nil)
-	RootTestCase(t, 64, 3.2, 4, nil)
-	RootTestCase(t, -64, 3.2, -4, nil)
+	RootTestCase(t, -64, 3, -4, nil)
 	RootTestCase(t, -4, 3, -1.587401052, nil)
 
+	// real degrees
+	RootTestCase(t, 64, 3.2, 3.6680161728, nil)
+	RootTestCase(t, 2, 0.5, 4, nil)
+	RootTestCase(t, -64, 3.2, 0, errors.New("can't calculate root 3.2 of a negative number: -64.000"))
+	RootTestCase(t, 4, -0.5, 0, errors.New("can't calculate root of a negative degree: -0.5"))
+	RootTestCase(t, 1e300, 0.1, 0, errors.New("result of root 0.1 of 1e+300 is too big"))
+
 	RootTestCase(t, -12587458, 7, -10.33419999481, nil)
 	RootTestCase(t, 12587458, 8, 7.717777507194, nil)
 	RootTestCase(t, 5670, 56, 1.166885569, nil)
 	RootTestCase(t, 5670, 560, 1.015553546, nil)
 	RootTestCase(t, 5670, 560, 1.015553546, nil)
 	RootTestCase(t, 56705, 560871, 1.0000195156, nil)
+
+	// relative precision of tiny and huge radicands
+	RootRelativeTestCase(t, 1e-300, 2, 1e-150)
+	RootRelativeTestCase(t, 2e-20, 2, math.Sqrt(2e-20))
+	RootRelativeTestCase(t, 1e300, 3, 1e100)
+	RootRelativeTestCase(t, math.MaxFloat64, 2, math.Sqrt(math.MaxFloat64))
+	RootRelativeTestCase(t, -1e-30, 5, -1e-6)
+}
+
+func RootRelativeTestCase(t *testing.T, x float64, n float64, expectedOutput float64) {
+	output, err := Root(x, n)
+	if err != nil || math.Abs(output-expectedOutput) > 4e-16*math.Abs(expectedOutput) {
+		t.Errorf("Root(%g, %g) = %g, %v; should be %g", x, n, output, err, expectedOutput)
+	}
 }
 
 // test by using the result of root as the base in exponentiation and checking if it equals to the original input to root
 func TestRootWihtPower(t *testing.T) {
-	RootWithPowerTestCase(t, -64, 3.1)
+	RootWithPowerTestCase(t, 64, 3.1)
+	RootWithPowerTestCase(t, -64, 3)
 	RootWithPowerTestCase(t, 120, 3)
 	RootWithPowerTestCase(t, -4, 3)
 	RootWithPowerTestCase(t, 5670, 56)
@@ -257,7 +410,7 @@ func TestRootWihtPower(t *testing.T) {
 
 func RootWithPowerTestCase(t *testing.T, x float64, n float64) {
 	output, err := Root(x, n)
-	expectedOutput := math.Pow(output, float64(int(n)))
+	expectedOutput := math.Pow(output, n)
 
 	// Check 10 decimals
 	if math.IsNaN(output) || math.Abs(x-expectedOutput) > math.Pow(10, -10) {
@@ -279,3 +432,549 @@ func RootTestCase(t *testing.T, x float64, n float64, expectedOutput float64, ex
 		t.Errorf("Root(%f, %f) err = %s; should be %s", x, n, err, expectedError)
 	}
 }
+
+func TestStatistics(t *testing.T) {
+	numbers := []float64{3, 1, 4, 1, 5}
+	StatisticTestCase(t, "Mean", Mean, numbers, 2.8, nil)
+	StatisticTestCase(t, "Median", Median, numbers, 3, nil)
+	StatisticTestCase(t, "Median", Median, []float64{4, 1, 3, 2}, 2.5, nil)
+	StatisticTestCase(t, "Mode", Mode, numbers, 1, nil)
+	StatisticTestCase(t, "Mode", Mode, []float64{2, 2, 1, 1}, 1, nil)
+	StatisticTestCase(t, "Variance", Variance, numbers, 3.2, nil)
+	StatisticTestCase(t, "Variance", Variance, []float64{2, 2, 2}, 0, nil)
+	StatisticTestCase(t, "StandardDeviation", StandardDeviation, []float64{10, 20}, 7.0710678119, nil)
+	StatisticTestCase(t, "StandardDeviation", StandardDeviation, []float64{987, 382, 928, 278, 273, 421, 515, 832, 286, 495}, 275.2453652854, nil)
+	// large numbers close to each other
+	StatisticTestCase(t, "Variance", Variance, []float64{1e9, 1e9 + 1, 1e9 + 2}, 1, nil)
+	StatisticTestCase(t, "StandardDeviation", StandardDeviation, []float64{1e9, 1e9 + 1, 1e9 + 2}, 1, nil)
+	StatisticTestCase(t, "Variance", Variance, []float64{1e15 + 4, 1e15 + 7, 1e15 + 13, 1e15 + 16}, 30, nil)
+	StatisticTestCase(t, "Min", Min, numbers, 1, nil)
+	StatisticTestCase(t, "Max", Max, numbers, 5, nil)
+	StatisticTestCase(t, "Max", Max, []float64{-3, -1, -2}, -1, nil)
+
+	StatisticTestCase(t, "Mean", Mean, []float64{}, 0, errors.New("cannot calculate mean of no numbers"))
+	StatisticTestCase(t, "Median", Median, []float64{}, 0, errors.New("cannot calculate median of no numbers"))
+	StatisticTestCase(t, "Mode", Mode, []float64{}, 0, errors.New("cannot calculate mode of no numbers"))
+	StatisticTestCase(t, "Variance", Variance, []float64{1}, 0, errors.New("cannot calculate variance of less than two numbers"))
+	StatisticTestCase(t, "StandardDeviation", StandardDeviation, []float64{1}, 0, errors.New("cannot calculate variance of less than two numbers"))
+	StatisticTestCase(t, "Min", Min, []float64{}, 0, errors.New("cannot calculate minimum of no numbers"))
+	StatisticTestCase(t, "Max", Max, []float64{}, 0, errors.New("cannot calculate maximum of no numbers"))
+}
+
+func StatisticTestCase(t *testing.T, name string, f func([]float64) (float64, error), input []float64, expectedOutput float64, expectedError error) {
+	output, err := f(input)
+	// Check 10 decimals
+	if math.Abs(output-expectedOutput) > math.Pow(10, -10) {
+		t.Errorf("%s(%v) = %f; should be %f", name, input, output, expectedOutput)
+	}
+	if (err == nil && expectedError != nil) || (err != nil && expectedError == nil) || (err != nil && expectedError != nil && err.Error() != expectedError.Error()) {
+		t.Errorf("%s(%v) err = %s; should be %s", name, input, err, expectedError)
+	}
+}
+
+func TestSort(t *testing.T) {
+	input := []float64{3, 1, 4, 1, 5}
+	output := Sort(input)
+	if !reflect.DeepEqual(output, []float64{1, 1, 3, 4, 5}) {
+		t.Errorf("Sort(%v) = %v; should be sorted", input, output)
+	}
+	if !reflect.DeepEqual(input, []float64{3, 1, 4, 1, 5}) {
+		t.Errorf("Sort modified its input to %v", input)
+	}
+}
+
+func TestDigamma(t *testing.T) {
+	DigammaTestCase(t, 1, -0.5772156649, nil)
+	DigammaTestCase(t, 0.5, -1.9635100260, nil)
+	DigammaTestCase(t, 10, 2.2517525891, nil)
+	DigammaTestCase(t, 100.5, 4.6051743526, nil)
+	DigammaTestCase(t, -0.5, 0.0364899740, nil)
+
+	DigammaTestCase(t, 0, 0, errors.New("digamma is undefined for non-positive integers"))
+	DigammaTestCase(t, -3, 0, errors.New("digamma is undefined for non-positive integers"))
+}
+
+func DigammaTestCase(t *testing.T, input float64, expectedOutput float64, expectedError error) {
+	output, err := Digamma(input)
+	// Check 10 decimals
+	if math.Abs(output-expectedOutput) > math.Pow(10, -10) {
+		t.Errorf("Digamma(%f) = %.10f; should be %.10f", input, output, expectedOutput)
+	}
+	if (err == nil && expectedError != nil) || (err != nil && expectedError == nil) || (err != nil && expectedError != nil && err.Error() != expectedError.Error()) {
+		t.Errorf("Digamma(%f) err = %s; should be %s", input, err, expectedError)
+	}
+}
+
+func TestIntervalArithmetic(t *testing.T) {
+	a, b := Interval{1, 2}, Interval{-3, 4}
+	IntervalTestCase(t, "IntervalAdd", IntervalAdd(a, b), nil, Interval{-2, 6}, nil)
+	IntervalTestCase(t, "IntervalSubtract", IntervalSubtract(a, b), nil, Interval{-3, 5}, nil)
+	IntervalTestCase(t, "IntervalMultiply", IntervalMultiply(a, b), nil, Interval{-6, 8}, nil)
+	IntervalTestCase(t, "IntervalMultiply", IntervalMultiply(b, b), nil, Interval{-12, 16}, nil)
+	IntervalTestCase(t, "IntervalAbsoluteValue", IntervalAbsoluteValue(b), nil, Interval{0, 4}, nil)
+	IntervalTestCase(t, "IntervalAbsoluteValue", IntervalAbsoluteValue(Interval{-2, -1}), nil, Interval{1, 2}, nil)
+
+	out, err := IntervalDivide(Interval{1, 2}, Interval{4, 8})
+	IntervalTestCase(t, "IntervalDivide", out, err, Interval{0.125, 0.5}, nil)
+	out, err = IntervalDivide(a, b)
+	IntervalTestCase(t, "IntervalDivide", out, err, Interval{}, errors.New("cannot divide by an interval containing zero"))
+	out, err = IntervalDivide(a, PointInterval(0))
+	IntervalTestCase(t, "IntervalDivide", out, err, Interval{}, errors.New("cannot divide by an interval containing zero"))
+
+	out, err = IntervalModulo(Interval{7, 8}, PointInterval(3))
+	IntervalTestCase(t, "IntervalModulo", out, err, Interval{1, 2}, nil)
+	out, err = IntervalModulo(Interval{5, 7}, PointInterval(3))
+	IntervalTestCase(t, "IntervalModulo", out, err, Interval{0, 3}, nil)
+	out, err = IntervalModulo(Interval{5, 7}, PointInterval(-3))
+	IntervalTestCase(t, "IntervalModulo", out, err, Interval{-3, 0}, nil)
+
+	out, err = IntervalPower(b, PointInterval(2))
+	IntervalTestCase(t, "IntervalPower", out, err, Interval{0, 16}, nil)
+	out, err = IntervalPower(b, PointInterval(3))
+	IntervalTestCase(t, "IntervalPower", out, err, Interval{-27, 64}, nil)
+	out, err = IntervalPower(Interval{-3, -2}, PointInterval(2))
+	IntervalTestCase(t, "IntervalPower", out, err, Interval{4, 9}, nil)
+	out, err = IntervalPower(b, PointInterval(0))
+	IntervalTestCase(t, "IntervalPower", out, err, Interval{}, errors.New("0^0 is undefined"))
+	out, err = IntervalPower(Interval{-1, 2}, Interval{1, 2})
+	IntervalTestCase(t, "IntervalPower", out, err, Interval{}, errors.New("cannot raise an interval containing negative numbers to a power, which isn't an exact integer"))
+	out, err = IntervalPower(Interval{0, 2}, PointInterval(-0.5))
+	IntervalTestCase(t, "IntervalPower", out, err, Interval{}, errors.New("cannot raise an interval containing zero to a power, which isn't positive"))
+	out, err = IntervalPower(a, PointInterval(-2))
+	IntervalTestCase(t, "IntervalPower", out, err, Interval{0.25, 1}, nil)
+	out, err = IntervalPower(b, PointInterval(-1))
+	IntervalTestCase(t, "IntervalPower", out, err, Interval{}, errors.New("cannot raise an interval containing zero to a negative power"))
+
+	out, err = IntervalRoot(Interval{4, 9}, PointInterval(2))
+	IntervalTestCase(t, "IntervalRoot", out, err, Interval{2, 3}, nil)
+	out, err = IntervalRoot(Interval{-27, 8}, PointInterval(3))
+	IntervalTestCase(t, "IntervalRoot", out, err, Interval{-3, 2}, nil)
+	out, err = IntervalRoot(b, PointInterval(2.5))
+	IntervalTestCase(t, "IntervalRoot", out, err, Interval{}, errors.New("can't calculate root of a degree, which isn't an exact integer, of an interval containing negative numbers"))
+	out, err = IntervalRoot(a, Interval{-1, 2})
+	IntervalTestCase(t, "IntervalRoot", out, err, Interval{}, errors.New("can't calculate root of a degree, which isn't positive"))
+	out, err = IntervalRoot(b, PointInterval(2))
+	IntervalTestCase(t, "IntervalRoot", out, err, Interval{}, errors.New("can't calculate root 2 of an interval containing negative numbers"))
+
+	out, err = IntervalFactorial(PointInterval(5))
+	IntervalTestCase(t, "IntervalFactorial", out, err, Interval{120, 120}, nil)
+	out, err = IntervalFactorial(Interval{-1.5, 1})
+	IntervalTestCase(t, "IntervalFactorial", out, err, Interval{}, errors.New("factorial argument has to be an exact integer"))
+
+	// real exponents, degrees and factorials are calculated with a small outward widening
+	for _, c := range []struct {
+		name   string
+		f      func(Interval, Interval) (Interval, error)
+		a, b   Interval
+		lo, hi float64
+	}{
+		{"IntervalPower", IntervalPower, PointInterval(2), PointInterval(0.5), math.Sqrt2, math.Sqrt2},
+		{"IntervalPower", IntervalPower, a, a, 1, 4},
+		{"IntervalPower", IntervalPower, Interval{0, 0.25}, Interval{0.5, 1}, 0, 0.5},
+		{"IntervalPower", IntervalPower, PointInterval(4), PointInterval(-0.5), 0.5, 0.5},
+		{"IntervalRoot", IntervalRoot, PointInterval(8), PointInterval(1.5), 4, 4},
+		{"IntervalRoot", IntervalRoot, Interval{0, 16}, Interval{2, 4}, 0, 4},
+		{"IntervalFactorial", func(x, _ Interval) (Interval, error) { return IntervalFactorial(x) }, Interval{0.5, 2}, a,
+			math.Sqrt(math.Pi) / 2, 2},
+		{"IntervalFactorial", func(x, _ Interval) (Interval, error) { return IntervalFactorial(x) }, PointInterval(-0.5), a,
+			math.Sqrt(math.Pi), math.Sqrt(math.Pi)},
+	} {
+		out, err := c.f(c.a, c.b)
+		if err != nil || out.Lo > c.lo || out.Hi < c.hi || c.lo-out.Lo > 1e-12*math.Max(1, c.lo) || out.Hi-c.hi > 1e-12*c.hi {
+			t.Errorf("%s(%v, %v) = %v, %v should closely contain [%v, %v]", c.name, c.a, c.b, out, err, c.lo, c.hi)
+		}
+	}
+}
+
+func IntervalTestCase(t *testing.T, name string, output Interval, err error, expectedOutput Interval, expectedError error) {
+	if expectedError == nil && output != expectedOutput {
+		t.Errorf("%s = %v; should be %v", name, output, expectedOutput)
+	}
+	if (err == nil && expectedError != nil) || (err != nil && expectedError == nil) || (err != nil && expectedError != nil && err.Error() != expectedError.Error()) {
+		t.Errorf("%s err = %s; should be %s", name, err, expectedError)
+	}
+}
+
+func TestIntervalRounding(t *testing.T) {
+	tenth, third := new(big.Rat).SetFloat64(0.1), new(big.Rat).SetFloat64(1.0/3)
+	exact := new(big.Rat).Add(tenth, new(big.Rat).SetFloat64(0.2))
+	IntervalRoundingTestCase(t, "IntervalAdd", IntervalAdd(PointInterval(0.1), PointInterval(0.2)), exact)
+	exact = new(big.Rat).Sub(tenth, third)
+	IntervalRoundingTestCase(t, "IntervalSubtract", IntervalSubtract(PointInterval(0.1), PointInterval(1.0/3)), exact)
+	exact = new(big.Rat).Mul(tenth, third)
+	IntervalRoundingTestCase(t, "IntervalMultiply", IntervalMultiply(PointInterval(0.1), PointInterval(1.0/3)), exact)
+	exact = new(big.Rat).Quo(tenth, third)
+	out, _ := IntervalDivide(PointInterval(0.1), PointInterval(1.0/3))
+	IntervalRoundingTestCase(t, "IntervalDivide", out, exact)
+	exact = new(big.Rat).Mul(tenth, new(big.Rat).Mul(tenth, tenth))
+	out, _ = IntervalPower(PointInterval(0.1), PointInterval(3))
+	IntervalRoundingTestCase(t, "IntervalPower", out, exact)
+
+	// bounds of the square root of 2 squared have to enclose 2
+	out, _ = IntervalRoot(PointInterval(2), PointInterval(2))
+	lo, hi := new(big.Rat).SetFloat64(out.Lo), new(big.Rat).SetFloat64(out.Hi)
+	two := big.NewRat(2, 1)
+	if new(big.Rat).Mul(lo, lo).Cmp(two) > 0 || new(big.Rat).Mul(hi, hi).Cmp(two) < 0 || math.Nextafter(out.Lo, 3) != out.Hi {
+		t.Errorf("IntervalRoot(2, 2) = %v; should tightly enclose the square root of 2", out)
+	}
+}
+
+func IntervalRoundingTestCase(t *testing.T, name string, output Interval, exact *big.Rat) {
+	lo, hi := new(big.Rat).SetFloat64(output.Lo), new(big.Rat).SetFloat64(output.Hi)
+	if lo.Cmp(exact) > 0 || hi.Cmp(exact) < 0 {
+		t.Errorf("%s = %v; should contain %s", name, output, exact.FloatString(20))
+	}
+	if output.Hi-output.Lo > 4*(math.Nextafter(output.Hi, math.Inf(1))-output.Hi) {
+		t.Errorf("%s = %v; bounds are too far apart", name, output)
+	}
+}
+
+func TestCombinatorics(t *testing.T) {
+	BinaryTestCase(t, "Combinations", Combinations, 5, 2, 10, nil)
+	BinaryTestCase(t, "Combinations", Combinations, 5, 0, 1, nil)
+	BinaryTestCase(t, "Combinations", Combinations, 5, 6, 0, nil)
+	BinaryTestCase(t, "Combinations", Combinations, 5, -1, 0, nil)
+	BinaryTestCase(t, "Combinations", Combinations, 100, 50, 1.008913445455642e+29, nil)
+	BinaryTestCase(t, "Combinations", Combinations, 1000, 500, 2.7028824094543655e+299, nil)
+	BinaryTestCase(t, "Combinations", Combinations, 1e15, 1e15-1, 1e15, nil)
+	BinaryTestCase(t, "Permutations", Permutations, 5, 2, 20, nil)
+	BinaryTestCase(t, "Permutations", Permutations, 20, 10, 670442572800, nil)
+	BinaryTestCase(t, "Permutations", Permutations, 3, 4, 0, nil)
+
+	BinaryTestCase(t, "Combinations", Combinations, 5.5, 2, 0, errors.New("nCr works only with integers"))
+	BinaryTestCase(t, "Combinations", Combinations, -5, 2, 0, errors.New("nCr works only with non-negative integers"))
+	BinaryTestCase(t, "Combinations", Combinations, 1e9, 5e8, 0, errors.New("result of nCr is too big"))
+	BinaryTestCase(t, "Combinations", Combinations, 1e17, 2, 0, errors.New("1e+17 is too big for nCr"))
+	BinaryTestCase(t, "Permutations", Permutations, 200, 171, 0, errors.New("result of nPr is too big"))
+}
+
+func TestNumberTheory(t *testing.T) {
+	StatisticTestCase(t, "GCD", GCD, []float64{12, 18}, 6, nil)
+	StatisticTestCase(t, "GCD", GCD, []float64{-12, 18, 8}, 2, nil)
+	StatisticTestCase(t, "GCD", GCD, []float64{0, 5}, 5, nil)
+	StatisticTestCase(t, "LCM", LCM, []float64{4, 6}, 12, nil)
+	StatisticTestCase(t, "LCM", LCM, []float64{-4, 6, 10}, 60, nil)
+	StatisticTestCase(t, "LCM", LCM, []float64{4, 0}, 0, nil)
+	StatisticTestCase(t, "LCM", LCM, []float64{94906249, 94906247}, 9007195909437503, nil)
+	StatisticTestCase(t, "GCD", GCD, []float64{}, 0, errors.New("cannot calculate gcd of no numbers"))
+	StatisticTestCase(t, "LCM", LCM, []float64{1.5, 2}, 0, errors.New("lcm works only with integers"))
+
+	GammaTestCase(t, "NextPrime", NextPrime, 1, 2, nil)
+	GammaTestCase(t, "NextPrime", NextPrime, 13, 17, nil)
+	GammaTestCase(t, "NextPrime", NextPrime, 9007199254740800, 9007199254740881, nil)
+	GammaTestCase(t, "NextPrime", NextPrime, 9007199254740881, 0, errors.New("result of nextprime is too big"))
+	// 2^53+1 is rounded to 2^53 already
+	GammaTestCase(t, "NextPrime", NextPrime, 9007199254740993, 0, errors.New("9.007199254740992e+15 is too big for nextprime"))
+	GammaTestCase(t, "Totient", Totient, 1, 1, nil)
+	GammaTestCase(t, "Totient", Totient, 36, 12, nil)
+	GammaTestCase(t, "Totient", Totient, 97, 96, nil)
+	GammaTestCase(t, "Totient", Totient, 0, 0, errors.New("totient works only with positive integers"))
+
+	BinaryTestCase(t, "ModInverse", ModInverse, 3, 7, 5, nil)
+	BinaryTestCase(t, "ModInverse", ModInverse, -3, 7, 2, nil)
+	BinaryTestCase(t, "ModInverse", ModInverse, 4, 1, 0, nil)
+	BinaryTestCase(t, "ModInverse", ModInverse, 4, 6, 0, errors.New("4 has no inverse modulo 6"))
+	BinaryTestCase(t, "ModInverse", ModInverse, 4, 0, 0, errors.New("modulus of modinv has to be a positive integer"))
+
+	for _, c := range []struct{ a, b, m, out float64 }{{2, 10, 1000, 24}, {2, 1e15, 1e9 + 7, 264444359}, {3, -2, 7, 4}, {-2, 3, 5, 2}, {0, 0, 5, 1}} {
+		output, err := PowMod(c.a, c.b, c.m)
+		if output != c.out || err != nil {
+			t.Errorf("PowMod(%g, %g, %g) = %g, %v; should be %g", c.a, c.b, c.m, output, err, c.out)
+		}
+	}
+	if _, err := PowMod(2, -1, 4); err == nil || err.Error() != "2 has no inverse modulo 4" {
+		t.Errorf("PowMod(2, -1, 4) err = %v; should be 2 has no inverse modulo 4", err)
+	}
+}
+
+func TestPrimes(t *testing.T) {
+	for n, expected := range map[float64]bool{-7: false, 0: false, 1: false, 2: true, 9: false, 97: true, 9007199254740881: true, 9007195909437503: false} {
+		output, err := IsPrime(n)
+		if output != expected || err != nil {
+			t.Errorf("IsPrime(%g) = %t, %v; should be %t", n, output, err, expected)
+		}
+	}
+
+	FactorTestCase(t, 1, []float64{}, nil)
+	FactorTestCase(t, 12, []float64{2, 2, 3}, nil)
+	FactorTestCase(t, 97, []float64{97}, nil)
+	FactorTestCase(t, 9007199254740991, []float64{6361, 69431, 20394401}, nil)
+	FactorTestCase(t, 9007195909437503, []float64{94906247, 94906249}, nil)
+	FactorTestCase(t, 0, nil, errors.New("cannot factor 0"))
+	FactorTestCase(t, -12, nil, errors.New("factor works only with non-negative integers"))
+	FactorTestCase(t, 1e16, nil, errors.New("1e+16 is too big for factor"))
+}
+
+func FactorTestCase(t *testing.T, input float64, expectedOutput []float64, expectedError error) {
+	output, err := Factor(input)
+	if !reflect.DeepEqual(output, expectedOutput) {
+		t.Errorf("Factor(%g) = %v; should be %v", input, output, expectedOutput)
+	}
+	if (err == nil && expectedError != nil) || (err != nil && expectedError == nil) || (err != nil && expectedError != nil && err.Error() != expectedError.Error()) {
+		t.Errorf("Factor(%g) err = %s; should be %s", input, err, expectedError)
+	}
+}
+
+func BinaryTestCase(t *testing.T, name string, f func(float64, float64) (float64, error), inputA float64, inputB float64, expectedOutput float64, expectedError error) {
+	output, err := f(inputA, inputB)
+	if output != expectedOutput {
+		t.Errorf("%s(%g, %g) = %g; should be %g", name, inputA, inputB, output, expectedOutput)
+	}
+	if (err == nil && expectedError != nil) || (err != nil && expectedError == nil) || (err != nil && expectedError != nil && err.Error() != expectedError.Error()) {
+		t.Errorf("%s(%g, %g) err = %s; should be %s", name, inputA, inputB, err, expectedError)
+	}
+}
+
+func TestNormalDistribution(t *testing.T) {
+	DistributionTestCase(t, "NormPdf", NormPdf, []float64{0, 0, 1}, 0.3989422804014327, nil)
+	DistributionTestCase(t, "NormPdf", NormPdf, []float64{2, 1, 2}, 0.17603266338214976, nil)
+	DistributionTestCase(t, "NormCdf", NormCdf, []float64{1.96, 0, 1}, 0.9750021048517796, nil)
+	DistributionTestCase(t, "NormCdf", NormCdf, []float64{-3, 0, 1}, 0.0013498980316301035, nil)
+	DistributionTestCase(t, "NormCdf", NormCdf, []float64{130, 100, 15}, 0.9772498680518208, nil)
+	DistributionTestCase(t, "NormInv", NormInv, []float64{0.975, 0, 1}, 1.9599639845400536, nil)
+	DistributionTestCase(t, "NormInv", NormInv, []float64{0.001, 0, 1}, -3.090232306167813, nil)
+	DistributionTestCase(t, "NormInv", NormInv, []float64{0.9, 100, 15}, 119.22327348316901, nil)
+	DistributionTestCase(t, "NormInv", NormInv, []float64{0.98, 0, 1}, 2.053748910631823, nil)
+	DistributionTestCase(t, "NormInv", NormInv, []float64{0, 0, 1}, math.Inf(-1), nil)
+	DistributionTestCase(t, "NormInv", NormInv, []float64{1, 0, 1}, math.Inf(1), nil)
+
+	DistributionTestCase(t, "NormPdf", NormPdf, []float64{0, 0, 0}, 0, errors.New("standard deviation has to be positive"))
+	DistributionTestCase(t, "NormCdf", NormCdf, []float64{0, 0, -1}, 0, errors.New("standard deviation has to be positive"))
+	DistributionTestCase(t, "NormInv", NormInv, []float64{1.5, 0, 1}, 0, errors.New("probability has to be between 0 and 1"))
+
+	// far tails keep their relative accuracy
+	output, _ := NormCdf(-30, 0, 1)
+	if math.Abs(output/4.906713927148187e-198-1) > 1e-12 {
+		t.Errorf("NormCdf(-30, 0, 1) = %g; should be 4.906713927148187e-198", output)
+	}
+	for p, expected := range map[float64]float64{
+		0.4:    -0.2533471031357998,
+		0.02:   -2.053748910631823,
+		1e-10:  -6.361340902404057,
+		1e-14:  -7.650628092935269,
+		1e-17:  -8.493793224109599,
+		1e-100: -21.273453560965326,
+		1e-300: -37.0470962993612,
+	} {
+		output, err := NormInv(p, 0, 1)
+		if err != nil || math.Abs(output/expected-1) > 1e-15 {
+			t.Errorf("NormInv(%g, 0, 1) = %.17g, %v; should be %.17g", p, output, err, expected)
+		}
+	}
+}
+
+func TestDiscreteDistributions(t *testing.T) {
+	DistributionTestCase(t, "BinomPdf", BinomPdf, []float64{3, 10, 0.5}, 0.1171875, nil)
+	DistributionTestCase(t, "BinomPdf", BinomPdf, []float64{7, 20, 0.3}, 0.1642619852172365, nil)
+	DistributionTestCase(t, "BinomPdf", BinomPdf, []float64{11, 10, 0.5}, 0, nil)
+	DistributionTestCase(t, "BinomPdf", BinomPdf, []float64{10, 10, 1}, 1, nil)
+	DistributionTestCase(t, "BinomCdf", BinomCdf, []float64{3, 10, 0.5}, 0.171875, nil)
+	DistributionTestCase(t, "BinomCdf", BinomCdf, []float64{15, 100, 0.1}, 0.9601094728889167, nil)
+	DistributionTestCase(t, "BinomCdf", BinomCdf, []float64{500, 1000, 0.5}, 0.5126125090891804, nil)
+	DistributionTestCase(t, "BinomCdf", BinomCdf, []float64{-1, 10, 0.5}, 0, nil)
+	DistributionTestCase(t, "BinomCdf", BinomCdf, []float64{10, 10, 0.5}, 1, nil)
+	DistributionTestCase(t, "PoissonPdf", PoissonPdf, []float64{2, 4}, 0.14652511110987343, nil)
+	DistributionTestCase(t, "PoissonPdf", PoissonPdf, []float64{15, 10}, 0.03471806963068413, nil)
+	DistributionTestCase(t, "PoissonPdf", PoissonPdf, []float64{0, 0}, 1, nil)
+
+	DistributionTestCase(t, "BinomPdf", BinomPdf, []float64{2.5, 10, 0.5}, 0, errors.New("number of successes has to be an integer"))
+	DistributionTestCase(t, "BinomCdf", BinomCdf, []float64{2, 10.5, 0.5}, 0, errors.New("number of trials has to be a non-negative integer"))
+	DistributionTestCase(t, "BinomCdf", BinomCdf, []float64{2, 10, -0.1}, 0, errors.New("probability has to be between 0 and 1"))
+	DistributionTestCase(t, "PoissonPdf", PoissonPdf, []float64{2, -1}, 0, errors.New("mean has to be a non-negative number"))
+}
+
+func TestContinuousDistributions(t *testing.T) {
+	DistributionTestCase(t, "StudentTCdf", StudentTCdf, []float64{1.5, 1}, 0.8128329581890013, nil)
+	DistributionTestCase(t, "StudentTCdf", StudentTCdf, []float64{-1, 2}, 0.21132486540518708, nil)
+	DistributionTestCase(t, "StudentTCdf", StudentTCdf, []float64{0, 5}, 0.5, nil)
+	DistributionTestCase(t, "StudentTInv", StudentTInv, []float64{0.975, 1}, 12.706204736174696, nil)
+	DistributionTestCase(t, "StudentTInv", StudentTInv, []float64{0.975, 2}, 4.302652729749461, nil)
+	DistributionTestCase(t, "StudentTInv", StudentTInv, []float64{0.05, 2}, -2.919985580353726, nil)
+	DistributionTestCase(t, "StudentTInv", StudentTInv, []float64{0.975, 1e6}, 1.9599663568141064, nil)
+
+	// the lower tail keeps its relative precision, the quantile of the Cauchy distribution is tan(π(p-1/2))
+	for _, p := range []float64{1e-20, 1e-100, 0.01} {
+		expected := -1 / math.Tan(math.Pi*p)
+		if output, err := StudentTInv(p, 1); err != nil || math.Abs(output-expected) > 1e-12*math.Abs(expected) {
+			t.Errorf("StudentTInv(%g, 1) = %v, %v should be %v", p, output, err, expected)
+		}
+	}
+	if output, err := StudentTInv(1e-20, 5); err != nil || math.IsInf(output, 0) || output > -1e3 {
+		t.Errorf("StudentTInv(1e-20, 5) = %v, %v should be finite", output, err)
+	} else if cdf, _ := StudentTCdf(output, 5); math.Abs(cdf-1e-20) > 1e-12*1e-20 {
+		t.Errorf("StudentTCdf(StudentTInv(1e-20, 5), 5) = %v should be 1e-20", cdf)
+	}
+	DistributionTestCase(t, "ChiSquareCdf", ChiSquareCdf, []float64{3, 2}, 0.7768698398515702, nil)
+	DistributionTestCase(t, "ChiSquareCdf", ChiSquareCdf, []float64{1, 1}, 0.682689492137086, nil)
+	DistributionTestCase(t, "ChiSquareCdf", ChiSquareCdf, []float64{5, 4}, 0.7127025048163542, nil)
+	DistributionTestCase(t, "ChiSquareCdf", ChiSquareCdf, []float64{-1, 4}, 0, nil)
+
+	DistributionTestCase(t, "StudentTCdf", StudentTCdf, []float64{1, 0}, 0, errors.New("degrees of freedom have to be positive"))
+	DistributionTestCase(t, "StudentTInv", StudentTInv, []float64{-0.5, 3}, 0, errors.New("probability has to be between 0 and 1"))
+	DistributionTestCase(t, "ChiSquareCdf", ChiSquareCdf, []float64{1, -2}, 0, errors.New("degrees of freedom have to be positive"))
+}
+
+func DistributionTestCase(t *testing.T, name string, f interface{}, input []float64, expectedOutput float64, expectedError error) {
+	var output float64
+	var err error
+	switch f := f.(type) {
+	case func(float64, float64) (float64, error):
+		output, err = f(input[0], input[1])
+	case func(float64, float64, float64) (float64, error):
+		output, err = f(input[0], input[1], input[2])
+	}
+	// Check 10 decimals, infinities have to match exactly
+	if output != expectedOutput && !(math.Abs(output-expectedOutput) <= math.Pow(10, -10)) {
+		t.Errorf("%s(%v) = %.12f; should be %.12f", name, input, output, expectedOutput)
+	}
+	if (err == nil && expectedError != nil) || (err != nil && expectedError == nil) || (err != nil && expectedError != nil && err.Error() != expectedError.Error()) {
+		t.Errorf("%s(%v) err = %s; should be %s", name, input, err, expectedError)
+	}
+}
+
+func TestAnnuities(t *testing.T) {
+	output, err := Pmt(0.08/12, 10, 10000, 0, false)
+	FinanceTestCase(t, "Pmt(0.08/12, 10, 10000)", output, err, -1037.0320893591636, nil)
+	output, err = Pmt(0.06/12, 216, 0, 50000, false)
+	FinanceTestCase(t, "Pmt(0.06/12, 216, 0, 50000)", output, err, -129.0811608679954, nil)
+	output, err = Pmt(0, 10, 1000, 0, false)
+	FinanceTestCase(t, "Pmt(0, 10, 1000)", output, err, -100, nil)
+	output, err = Pmt(0.01, 0, 1000, 0, false)
+	FinanceTestCase(t, "Pmt(0.01, 0, 1000)", output, err, 0, errors.New("number of periods can't be 0"))
+	output, err = Fv(0.06/12, 10, -200, -500, true)
+	FinanceTestCase(t, "Fv(0.06/12, 10, -200, -500, 1)", output, err, 2581.4033740601362, nil)
+	output, err = Fv(0, 10, -100, -50, false)
+	FinanceTestCase(t, "Fv(0, 10, -100, -50)", output, err, 1050, nil)
+	output, err = Pv(0.08/12, 240, 500, 0, false)
+	FinanceTestCase(t, "Pv(0.08/12, 240, 500)", output, err, -59777.14585118777, nil)
+	output, err = Pv(-1, 240, 500, 0, false)
+	FinanceTestCase(t, "Pv(-1, 240, 500)", output, err, 0, errors.New("rate has to be greater than -1"))
+	output, err = Nper(0.01, -100, -1000, 10000, true)
+	FinanceTestCase(t, "Nper(0.01, -100, -1000, 10000, 1)", output, err, 59.67386567429457, nil)
+	output, err = Nper(0.01, -100, -1000, 10000, false)
+	FinanceTestCase(t, "Nper(0.01, -100, -1000, 10000)", output, err, 60.08212285376166, nil)
+	output, err = Nper(0.01, -100, -1000, 0, false)
+	FinanceTestCase(t, "Nper(0.01, -100, -1000)", output, err, -9.578594039813161, nil)
+	output, err = Nper(0.1, -50, 1000, 0, false)
+	FinanceTestCase(t, "Nper(0.1, -50, 1000)", output, err, 0, errors.New("payments never reach the future value"))
+	output, err = Rate(48, -200, 8000, 0, false, 0.1)
+	FinanceTestCase(t, "Rate(48, -200, 8000)", output, err, 0.007701472488201888, nil)
+	output, err = Rate(0, -200, 8000, 0, false, 0.1)
+	FinanceTestCase(t, "Rate(0, -200, 8000)", output, err, 0, errors.New("number of periods has to be positive"))
+}
+
+func TestCashFlows(t *testing.T) {
+	output, err := Npv(0.1, []float64{-10000, 3000, 4200, 6800})
+	FinanceTestCase(t, "Npv(0.1, {-10000, 3000, 4200, 6800})", output, err, 1188.4434123352216, nil)
+	output, err = Irr([]float64{-70000, 12000, 15000, 18000, 21000, 26000}, 0.1)
+	FinanceTestCase(t, "Irr({-70000, 12000, 15000, 18000, 21000, 26000})", output, err, 0.0866309480365316, nil)
+	output, err = Irr([]float64{100, 200}, 0.1)
+	FinanceTestCase(t, "Irr({100, 200})", output, err, 0, errors.New("irr needs at least one positive and one negative cash flow"))
+	output, err = CompoundInterest(1000, 0.05, 10)
+	FinanceTestCase(t, "CompoundInterest(1000, 0.05, 10)", output, err, 1628.894626777442, nil)
+	output, err = EffectiveRate(0.0525, 4)
+	FinanceTestCase(t, "EffectiveRate(0.0525, 4)", output, err, 0.05354266737075819, nil)
+	output, err = NominalRate(0.053543, 4)
+	FinanceTestCase(t, "NominalRate(0.053543, 4)", output, err, 0.052500319868356016, nil)
+	output, err = NominalRate(0.05, 0.5)
+	FinanceTestCase(t, "NominalRate(0.05, 0.5)", output, err, 0, errors.New("number of periods per year has to be a positive integer"))
+}
+
+func TestAmortize(t *testing.T) {
+	schedule, err := Amortize(1000, 0.1, 2)
+	payment := 576.1904761904761
+	expected := []AmortizationRow{{1, payment, 100, payment - 100, 1100 - payment}, {2, payment, 110 - payment/10, payment - 110 + payment/10, 0}}
+	if err != nil || len(schedule) != len(expected) {
+		t.Fatalf("Amortize(1000, 0.1, 2) = %v, %v; should be %v", schedule, err, expected)
+	}
+	for i, row := range schedule {
+		for j, x := range []float64{row.Period, row.Payment, row.Interest, row.Principal, row.Balance} {
+			y := []float64{expected[i].Period, expected[i].Payment, expected[i].Interest, expected[i].Principal, expected[i].Balance}[j]
+			if math.Abs(x-y) > math.Pow(10, -10) {
+				t.Errorf("Amortize(1000, 0.1, 2)[%d] = %v; should be %v", i, row, expected[i])
+			}
+		}
+	}
+	if _, err = Amortize(1000, 0.1, 2.5); err == nil || err.Error() != "number of periods has to be a positive integer up to 10000" {
+		t.Errorf("Amortize(1000, 0.1, 2.5) err = %v; should be number of periods has to be a positive integer up to 10000", err)
+	}
+}
+
+func FinanceTestCase(t *testing.T, name string, output float64, err error, expectedOutput float64, expectedError error) {
+	// Check 10 significant digits
+	if math.Abs(output-expectedOutput) > math.Pow(10, -10)*math.Max(1, math.Abs(expectedOutput)) {
+		t.Errorf("%s = %.12g; should be %.12g", name, output, expectedOutput)
+	}
+	if (err == nil && expectedError != nil) || (err != nil && expectedError == nil) || (err != nil && expectedError != nil && err.Error() != expectedError.Error()) {
+		t.Errorf("%s err = %s; should be %s", name, err, expectedError)
+	}
+}
+
+func TestDecimal(t *testing.T) {
+	halfEven := DecimalContext{Scale: 2, Rounding: RoundHalfEven}
+	halfUp := DecimalContext{Scale: 2, Rounding: RoundHalfUp}
+	add := func(a, b Decimal, _ DecimalContext) (Decimal, error) { return DecimalAdd(a, b), nil }
+	sub := func(a, b Decimal, _ DecimalContext) (Decimal, error) { return DecimalSubtract(a, b), nil }
+	mul := func(a, b Decimal, _ DecimalContext) (Decimal, error) { return DecimalMultiply(a, b), nil }
+	mod := func(a, b Decimal, _ DecimalContext) (Decimal, error) { return DecimalModulo(a, b) }
+
+	DecimalTestCase(t, "DecimalAdd", add, "0.1", "0.2", halfEven, "0.3", nil)
+	DecimalTestCase(t, "DecimalAdd", add, "1.50", "-2", halfEven, "-0.50", nil)
+	DecimalTestCase(t, "DecimalSubtract", sub, "0.3", "0.1", halfEven, "0.2", nil)
+	DecimalTestCase(t, "DecimalMultiply", mul, "1.1", "1.1", halfEven, "1.21", nil)
+	DecimalTestCase(t, "DecimalMultiply", mul, "2.5e3", "-1", halfEven, "-2500", nil)
+	DecimalTestCase(t, "DecimalDivide", DecimalDivide, "10", "3", halfEven, "3.33", nil)
+	DecimalTestCase(t, "DecimalDivide", DecimalDivide, "1", "4", DecimalContext{Scale: 10}, "0.25", nil)
+	DecimalTestCase(t, "DecimalDivide", DecimalDivide, "0.125", "1", halfEven, "0.12", nil)
+	DecimalTestCase(t, "DecimalDivide", DecimalDivide, "0.125", "1", halfUp, "0.13", nil)
+	DecimalTestCase(t, "DecimalDivide", DecimalDivide, "-0.125", "1", halfUp, "-0.13", nil)
+	DecimalTestCase(t, "DecimalDivide", DecimalDivide, "0.135", "1", halfEven, "0.14", nil)
+	DecimalTestCase(t, "DecimalDivide", DecimalDivide, "1", "0", halfEven, "", errors.New("cannot divide by zero"))
+	DecimalTestCase(t, "DecimalModulo", mod, "5.5", "2", halfEven, "1.5", nil)
+	DecimalTestCase(t, "DecimalModulo", mod, "-5.5", "2", halfEven, "0.5", nil)
+	DecimalTestCase(t, "DecimalModulo", mod, "5.5", "-2", halfEven, "-0.5", nil)
+	DecimalTestCase(t, "DecimalPower", DecimalPower, "1.1", "3", halfEven, "1.331", nil)
+	DecimalTestCase(t, "DecimalPower", DecimalPower, "2", "-2", halfEven, "0.25", nil)
+	DecimalTestCase(t, "DecimalPower", DecimalPower, "2", "0.5", DecimalContext{Scale: 10}, "1.4142135624", nil)
+	DecimalTestCase(t, "DecimalPower", DecimalPower, "0", "0", halfEven, "", errors.New("0^0 is undefined"))
+	DecimalTestCase(t, "DecimalPower", DecimalPower, "-8", "0.5", halfEven, "", errors.New("cannot raise a negative number to a fractional power: -8^0.5"))
+	DecimalTestCase(t, "DecimalRoot", DecimalRoot, "2", "2", DecimalContext{Scale: 20}, "1.4142135623730950488", nil)
+	DecimalTestCase(t, "DecimalRoot", DecimalRoot, "-27", "3", halfEven, "-3", nil)
+	DecimalTestCase(t, "DecimalRoot", DecimalRoot, "0.0625", "4", halfEven, "0.5", nil)
+	DecimalTestCase(t, "DecimalRoot", DecimalRoot, "-4", "2", halfEven, "", errors.New("can't calculate root 2 of a negative number: -4"))
+
+	for in, expected := range map[string]string{"5": "120", "0": "1", "25": "15511210043330985984000000"} {
+		a, _ := ParseDecimal(in)
+		if out, err := DecimalFactorial(a); err != nil || out.String() != expected {
+			t.Errorf("DecimalFactorial(%s) = %v, %v; should be %s", in, out, err, expected)
+		}
+	}
+	if _, err := DecimalFactorial(Decimal{big.NewInt(15), 1}); err == nil {
+		t.Errorf("DecimalFactorial(1.5) should return an error")
+	}
+	for _, in := range []string{"", ".", "1.2.3", "--1", "1e", "abc"} {
+		if _, err := ParseDecimal(in); err == nil {
+			t.Errorf("ParseDecimal(%q) should return an error", in)
+		}
+	}
+}
+
+func DecimalTestCase(t *testing.T, name string, f func(a, b Decimal, ctx DecimalContext) (Decimal, error), inputA, inputB string, ctx DecimalContext, expectedOutput string, expectedError error) {
+	a, err := ParseDecimal(inputA)
+	if err != nil {
+		t.Errorf("ParseDecimal(%s) err = %s", inputA, err)
+		return
+	}
+	b, err := ParseDecimal(inputB)
+	if err != nil {
+		t.Errorf("ParseDecimal(%s) err = %s", inputB, err)
+		return
+	}
+	output, err := f(a, b, ctx)
+	if expectedError == nil && (err != nil || output.String() != expectedOutput) {
+		t.Errorf("%s(%s, %s) = %v, %v; should be %s", name, inputA, inputB, output, err, expectedOutput)
+	}
+	if expectedError != nil && (err == nil || err.Error() != expectedError.Error()) {
+		t.Errorf("%s(%s, %s) err = %v; should be %s", name, inputA, inputB, err, expectedError)
+	}
+}